@@ -31,6 +31,7 @@ func Build(ctx context.Context) *cobra.Command {
 	var file string
 	var tag string
 	var target string
+	var network string
 	var noCache bool
 	var cacheFrom []string
 	var progress string
@@ -70,7 +71,7 @@ func Build(ctx context.Context) *cobra.Command {
 			log.Information("Running your build in %s...", buildKitHost)
 
 			ctx := context.Background()
-			if err := build.Run(ctx, "", buildKitHost, isOktetoCluster, path, file, tag, target, noCache, cacheFrom, buildArgs, secrets, progress); err != nil {
+			if _, err := build.Run(ctx, "", buildKitHost, isOktetoCluster, path, file, tag, target, network, noCache, cacheFrom, buildArgs, secrets, progress); err != nil {
 				analytics.TrackBuild(buildKitHost, false)
 				return err
 			}
@@ -90,6 +91,7 @@ func Build(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVarP(&file, "file", "f", "", "name of the Dockerfile (Default is 'PATH/Dockerfile')")
 	cmd.Flags().StringVarP(&tag, "tag", "t", "", "name and optionally a tag in the 'name:tag' format (it is automatically pushed)")
 	cmd.Flags().StringVarP(&target, "target", "", "", "set the target build stage to build")
+	cmd.Flags().StringVarP(&network, "network", "", "", "set the networking mode for the RUN instructions during build (default, host or none)")
 	cmd.Flags().BoolVarP(&noCache, "no-cache", "", false, "do not use cache when building the image")
 	cmd.Flags().StringArrayVar(&cacheFrom, "cache-from", nil, "cache source images")
 	cmd.Flags().StringVarP(&progress, "progress", "", "tty", "show plain/tty build output")
@@ -21,6 +21,7 @@ import (
 	"github.com/okteto/okteto/pkg/cmd/login"
 	"github.com/okteto/okteto/pkg/cmd/stack"
 	"github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
 	"github.com/spf13/cobra"
 )
 
@@ -29,15 +30,20 @@ func Deploy(ctx context.Context) *cobra.Command {
 	var stackPath string
 	var name string
 	var namespace string
-	var forceBuild bool
-	var wait bool
-	var noCache bool
+	var overrides []string
+	var checkImages bool
+	var diff bool
+	var commands []string
+	var images []string
+	var imagesFile string
+	options := &stack.DeployOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "deploy <name>",
+		Use:   "deploy [service...]",
 		Short: "Deploys a stack",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			s, err := utils.LoadStack(name, stackPath)
+			options.ServicesToDeploy = args
+			s, err := utils.LoadStack(name, stackPath, overrides)
 			if err != nil {
 				return err
 			}
@@ -46,11 +52,44 @@ func Deploy(ctx context.Context) *cobra.Command {
 				return err
 			}
 
+			for _, command := range commands {
+				if err := s.OverrideCommand(command); err != nil {
+					return err
+				}
+			}
+
+			if imagesFile != "" {
+				fileImages, err := model.ReadImageOverrides(imagesFile)
+				if err != nil {
+					return err
+				}
+				if err := s.OverrideImages(fileImages); err != nil {
+					return err
+				}
+			}
+			for _, image := range images {
+				if err := s.OverrideImage(image); err != nil {
+					return err
+				}
+			}
+
 			if err := login.WithEnvVarIfAvailable(ctx); err != nil {
 				return err
 			}
 
-			err = stack.Deploy(ctx, s, forceBuild, wait, noCache)
+			if diff {
+				return stack.Diff(ctx, s, args)
+			}
+
+			if checkImages {
+				if err := stack.CheckImages(ctx, s); err != nil {
+					return err
+				}
+				log.Success("All images of stack '%s' are available", s.Name)
+				return nil
+			}
+
+			err = stack.Deploy(ctx, s, options)
 			analytics.TrackDeployStack(err == nil)
 			if err == nil {
 				log.Success("Stack '%s' successfully deployed", s.Name)
@@ -58,11 +97,20 @@ func Deploy(ctx context.Context) *cobra.Command {
 			return err
 		},
 	}
-	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path to the stack manifest file")
+	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path or url to the stack manifest file")
 	cmd.Flags().StringVarP(&name, "name", "", "", "overwrites the stack name")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "overwrites the stack namespace where the stack is deployed")
-	cmd.Flags().BoolVarP(&forceBuild, "build", "", false, "build images before starting any Stack service")
-	cmd.Flags().BoolVarP(&wait, "wait", "", false, "wait until a minimum number of containers are in a ready state for every service")
-	cmd.Flags().BoolVarP(&noCache, "no-cache", "", false, "do not use cache when building the image")
+	cmd.Flags().StringArrayVarP(&overrides, "set", "", []string{}, "overrides a stack manifest field (e.g. --set services.web.replicas=3)")
+	cmd.Flags().StringArrayVarP(&commands, "command", "", []string{}, "overrides the command of a service (e.g. --command web=\"sleep infinity\")")
+	cmd.Flags().StringArrayVarP(&images, "images", "", []string{}, "overrides the image of a service, which is deployed without building it (e.g. --images web=okteto/web@sha256:...)")
+	cmd.Flags().StringVarP(&imagesFile, "images-file", "", "", "path to a yaml file mapping service names to the images to deploy, like the ones in --images")
+	cmd.Flags().BoolVarP(&checkImages, "check-images", "", false, "check that the images of every service exist, without building or deploying them")
+	cmd.Flags().BoolVarP(&diff, "diff", "", false, "show the changes to the live objects of the stack as a unified patch, without building or deploying it")
+	cmd.Flags().BoolVarP(&options.ForceBuild, "build", "", false, "build images before starting any Stack service")
+	cmd.Flags().StringVarP(&options.BuildChangedSince, "build-changed-since", "", "", "build only the images of the services whose build context changed since a git ref")
+	cmd.Flags().BoolVarP(&options.Wait, "wait", "", false, "wait until a minimum number of containers are in a ready state for every service")
+	cmd.Flags().BoolVarP(&options.NoCache, "no-cache", "", false, "do not use cache when building the image")
+	cmd.Flags().BoolVarP(&options.RollbackOnFailure, "rollback-on-failure", "", false, "roll back the services whose new version fails to become ready")
+	cmd.Flags().BoolVarP(&options.ForceRecreatePods, "force-recreate-pods", "", false, "recreate the pods of every service even if their configuration didn't change")
 	return cmd
 }
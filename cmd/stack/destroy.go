@@ -34,7 +34,7 @@ func Destroy(ctx context.Context) *cobra.Command {
 		Use:   "destroy <name>",
 		Short: "Destroys a stack",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			s, err := utils.LoadStack(name, stackPath)
+			s, err := utils.LoadStack(name, stackPath, nil)
 			if err != nil {
 				if name == "" {
 					return err
@@ -60,9 +60,9 @@ func Destroy(ctx context.Context) *cobra.Command {
 			return err
 		},
 	}
-	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path to the stack manifest file")
+	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path or url to the stack manifest file")
 	cmd.Flags().StringVarP(&name, "name", "", "", "overwrites the stack name")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "overwrites the stack namespace where the stack is destroyed")
-	cmd.Flags().BoolVarP(&rm, "volumes", "v", false, "remove persistent volumes")
+	cmd.Flags().BoolVarP(&rm, "volumes", "v", false, "remove persistent volumes, including the named volumes with 'persist: true'")
 	return cmd
 }
@@ -0,0 +1,58 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/cmd/stack"
+	"github.com/okteto/okteto/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+//Export writes the kubernetes manifests of a stack and a kustomization listing them
+func Export(ctx context.Context) *cobra.Command {
+	var stackPath string
+	var name string
+	var namespace string
+	var overrides []string
+	var outputDir string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Writes the kubernetes manifests of a stack and a kustomization listing them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := utils.LoadStack(name, stackPath, overrides)
+			if err != nil {
+				return err
+			}
+
+			if err := s.UpdateNamespace(namespace); err != nil {
+				return err
+			}
+
+			if err := stack.Export(s, outputDir); err != nil {
+				return err
+			}
+			log.Success("Stack '%s' exported to '%s'", s.Name, outputDir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path or url to the stack manifest file")
+	cmd.Flags().StringVarP(&name, "name", "", "", "overwrites the stack name")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "sets the namespace of the kustomization")
+	cmd.Flags().StringArrayVarP(&overrides, "set", "", []string{}, "overrides a stack manifest field (e.g. --set services.web.replicas=3)")
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "manifests", "directory where the manifests are written")
+	return cmd
+}
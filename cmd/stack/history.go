@@ -0,0 +1,71 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/cmd/stack"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+//History lists the previous deployments of a stack
+func History(ctx context.Context) *cobra.Command {
+	var stackPath string
+	var name string
+	var namespace string
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Lists the previous deployments of a stack",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := utils.LoadStack(name, stackPath, nil)
+			if err != nil {
+				if name == "" {
+					return err
+				}
+				s = &model.Stack{Name: name}
+			}
+
+			if err := s.UpdateNamespace(namespace); err != nil {
+				return err
+			}
+
+			history, err := stack.GetHistory(ctx, s)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+			fmt.Fprintf(w, "REVISION\tDEPLOYED\tUSER\tMANIFEST\n")
+			for _, r := range history {
+				hash := r.ManifestHash
+				if len(hash) > 12 {
+					hash = hash[:12]
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.Revision, r.Timestamp.Local().Format(time.RFC1123), r.User, hash)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path or url to the stack manifest file")
+	cmd.Flags().StringVarP(&name, "name", "", "", "overwrites the stack name")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "overwrites the stack namespace")
+	return cmd
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+//Lint lists the best-practice issues of a stack
+func Lint(ctx context.Context) *cobra.Command {
+	var stackPath string
+	var name string
+	var overrides []string
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Lists the best-practice issues of a stack",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := utils.LoadStack(name, stackPath, overrides)
+			if err != nil {
+				return err
+			}
+
+			issues := s.Lint()
+			if len(issues) == 0 {
+				log.Success("Stack '%s' has no issues", s.Name)
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', 0)
+			fmt.Fprintf(w, "SEVERITY\tSERVICE\tRULE\tMESSAGE\n")
+			for _, i := range issues {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", i.Severity, i.Service, i.Rule, i.Message)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path or url to the stack manifest file")
+	cmd.Flags().StringVarP(&name, "name", "", "", "overwrites the stack name")
+	cmd.Flags().StringArrayVarP(&overrides, "set", "", []string{}, "overrides a stack manifest field (e.g. --set services.web.replicas=3)")
+	return cmd
+}
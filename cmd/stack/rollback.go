@@ -0,0 +1,75 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/okteto/okteto/cmd/utils"
+	"github.com/okteto/okteto/pkg/cmd/login"
+	"github.com/okteto/okteto/pkg/cmd/stack"
+	"github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+//Rollback re-deploys a previous revision of a stack
+func Rollback(ctx context.Context) *cobra.Command {
+	var stackPath string
+	var name string
+	var namespace string
+	options := &stack.DeployOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rollback <revision>",
+		Short: "Re-deploys a previous revision of a stack",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			revision, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid revision '%s': must be a number", args[0])
+			}
+
+			s, err := utils.LoadStack(name, stackPath, nil)
+			if err != nil {
+				if name == "" {
+					return err
+				}
+				s = &model.Stack{Name: name}
+			}
+
+			if err := s.UpdateNamespace(namespace); err != nil {
+				return err
+			}
+
+			if err := login.WithEnvVarIfAvailable(ctx); err != nil {
+				return err
+			}
+
+			if err := stack.Rollback(ctx, s, revision, options); err != nil {
+				return err
+			}
+			log.Success("Stack '%s' successfully rolled back to revision %d", s.Name, revision)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&stackPath, "file", "f", utils.DefaultStackManifest, "path or url to the stack manifest file")
+	cmd.Flags().StringVarP(&name, "name", "", "", "overwrites the stack name")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "overwrites the stack namespace where the stack is deployed")
+	cmd.Flags().BoolVarP(&options.ForceBuild, "build", "", false, "build images before starting any Stack service")
+	cmd.Flags().BoolVarP(&options.Wait, "wait", "", false, "wait until a minimum number of containers are in a ready state for every service")
+	return cmd
+}
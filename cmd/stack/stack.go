@@ -27,5 +27,9 @@ func Stack(ctx context.Context) *cobra.Command {
 	}
 	cmd.AddCommand(Deploy(ctx))
 	cmd.AddCommand(Destroy(ctx))
+	cmd.AddCommand(History(ctx))
+	cmd.AddCommand(Rollback(ctx))
+	cmd.AddCommand(Export(ctx))
+	cmd.AddCommand(Lint(ctx))
 	return cmd
 }
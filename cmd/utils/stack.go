@@ -26,15 +26,15 @@ var (
 )
 
 //LoadStack loads an okteto stack manifest checking "yml" and "yaml"
-func LoadStack(name, stackPath string) (*model.Stack, error) {
-	if model.FileExists(stackPath) {
-		return model.GetStack(name, stackPath)
+func LoadStack(name, stackPath string, overrides []string) (*model.Stack, error) {
+	if model.IsStackURL(stackPath) || model.FileExists(stackPath) {
+		return model.GetStack(name, stackPath, overrides)
 	}
 
 	if stackPath == DefaultStackManifest {
 		for _, secondaryStackManifest := range secondaryStackManifests {
 			if model.FileExists(secondaryStackManifest) {
-				return model.GetStack(name, secondaryStackManifest)
+				return model.GetStack(name, secondaryStackManifest, overrides)
 			}
 		}
 	}
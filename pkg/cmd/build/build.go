@@ -27,12 +27,12 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Run runs the build sequence
-func Run(ctx context.Context, namespace, buildKitHost string, isOktetoCluster bool, path, dockerFile, tag, target string, noCache bool, cacheFrom, buildArgs, secrets []string, progress string) error {
+// Run runs the build sequence and returns the digest of the pushed image
+func Run(ctx context.Context, namespace, buildKitHost string, isOktetoCluster bool, path, dockerFile, tag, target, network string, noCache bool, cacheFrom, buildArgs, secrets []string, progress string) (string, error) {
 	log.Infof("building your image on %s", buildKitHost)
 	buildkitClient, err := getBuildkitClient(ctx, isOktetoCluster, buildKitHost)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if dockerFile == "" {
@@ -42,36 +42,36 @@ func Run(ctx context.Context, namespace, buildKitHost string, isOktetoCluster bo
 	if buildKitHost == okteto.CloudBuildKitURL {
 		dockerFile, err = registry.GetDockerfile(path, dockerFile)
 		if err != nil {
-			return err
+			return "", err
 		}
 		defer os.Remove(dockerFile)
 	}
 
 	tag, err = registry.ExpandOktetoDevRegistry(ctx, namespace, tag)
 	if err != nil {
-		return err
+		return "", err
 	}
 	for i := range cacheFrom {
 		cacheFrom[i], err = registry.ExpandOktetoDevRegistry(ctx, namespace, cacheFrom[i])
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
-	opt, err := getSolveOpt(path, dockerFile, tag, target, noCache, cacheFrom, buildArgs, secrets)
+	opt, err := getSolveOpt(path, dockerFile, tag, target, network, noCache, cacheFrom, buildArgs, secrets)
 	if err != nil {
-		return errors.Wrap(err, "failed to create build solver")
+		return "", errors.Wrap(err, "failed to create build solver")
 	}
 
-	err = solveBuild(ctx, buildkitClient, opt, progress)
+	digest, err := solveBuild(ctx, buildkitClient, opt, progress)
 	if registry.IsTransientError(err) {
 		log.Yellow("Failed to push '%s' to the registry, retrying ...", tag)
 		success := true
-		err := solveBuild(ctx, buildkitClient, opt, progress)
+		digest, err := solveBuild(ctx, buildkitClient, opt, progress)
 		if err != nil {
 			success = false
 		}
 		analytics.TrackBuildTransientError(buildKitHost, success)
-		return err
+		return digest, err
 	}
 	if err != nil {
 		imageRegistry, imageTag := registry.GetRegistryAndRepo(tag)
@@ -85,5 +85,5 @@ func Run(ctx context.Context, namespace, buildKitHost string, isOktetoCluster bo
 		}
 	}
 
-	return err
+	return digest, err
 }
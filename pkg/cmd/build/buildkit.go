@@ -26,6 +26,7 @@ import (
 	"github.com/moby/buildkit/cmd/buildctl/build"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/util/entitlements"
 	"github.com/moby/buildkit/util/progress/progressui"
 	okErrors "github.com/okteto/okteto/pkg/errors"
 	"github.com/okteto/okteto/pkg/log"
@@ -38,6 +39,8 @@ import (
 
 const (
 	frontend = "dockerfile.v0"
+
+	imageDigestExporterKey = "containerimage.digest"
 )
 
 //GetBuildKitHost returns the buildkit url and if Okteto Build Service is configured, or an error
@@ -54,7 +57,7 @@ func GetBuildKitHost() (string, bool, error) {
 }
 
 //getSolveOpt returns the buildkit solve options
-func getSolveOpt(buildCtx, file, imageTag, target string, noCache bool, cacheFrom, buildArgs, secrets []string) (*client.SolveOpt, error) {
+func getSolveOpt(buildCtx, file, imageTag, target, network string, noCache bool, cacheFrom, buildArgs, secrets []string) (*client.SolveOpt, error) {
 	if file == "" {
 		file = filepath.Join(buildCtx, "Dockerfile")
 	}
@@ -75,6 +78,9 @@ func getSolveOpt(buildCtx, file, imageTag, target string, noCache bool, cacheFro
 	if noCache {
 		frontendAttrs["no-cache"] = ""
 	}
+	if network != "" && network != "default" {
+		frontendAttrs["force-network-mode"] = network
+	}
 	for _, buildArg := range buildArgs {
 		kv := strings.SplitN(buildArg, "=", 2)
 		if len(kv) != 2 {
@@ -109,6 +115,10 @@ func getSolveOpt(buildCtx, file, imageTag, target string, noCache bool, cacheFro
 		CacheImports:  []client.CacheOptionsEntry{},
 	}
 
+	if network == "host" {
+		opt.AllowedEntitlements = []entitlements.Entitlement{entitlements.EntitlementNetworkHost}
+	}
+
 	if imageTag != "" {
 		opt.Exports = []client.ExportEntry{
 			{
@@ -128,6 +138,10 @@ func getSolveOpt(buildCtx, file, imageTag, target string, noCache bool, cacheFro
 				Attrs: map[string]string{"ref": cacheFromImage},
 			},
 		)
+		if imageTag != "" && cacheFromImage == imageTag {
+			// embed the cache metadata in the pushed image, so the next build can import it
+			opt.CacheExports = []client.CacheOptionsEntry{{Type: "inline"}}
+		}
 	}
 
 	return opt, nil
@@ -185,13 +199,17 @@ func getClientForOktetoCluster(ctx context.Context, buildKitHost string) (*clien
 	return c, nil
 }
 
-func solveBuild(ctx context.Context, c *client.Client, opt *client.SolveOpt, progress string) error {
+func solveBuild(ctx context.Context, c *client.Client, opt *client.SolveOpt, progress string) (string, error) {
 	ch := make(chan *client.SolveStatus)
 	eg, ctx := errgroup.WithContext(ctx)
+	var digest string
 	eg.Go(func() error {
-		var err error
-		_, err = c.Solve(ctx, nil, *opt, ch)
-		return errors.Wrap(err, "build failed")
+		resp, err := c.Solve(ctx, nil, *opt, ch)
+		if err != nil {
+			return errors.Wrap(err, "build failed")
+		}
+		digest = resp.ExporterResponse[imageDigestExporterKey]
+		return nil
 	})
 
 	eg.Go(func() error {
@@ -205,5 +223,8 @@ func solveBuild(ctx context.Context, c *client.Client, opt *client.SolveOpt, pro
 		return progressui.DisplaySolveStatus(context.TODO(), "", c, os.Stdout, ch)
 	})
 
-	return eg.Wait()
+	if err := eg.Wait(); err != nil {
+		return "", err
+	}
+	return digest, nil
 }
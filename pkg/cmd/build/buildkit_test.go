@@ -0,0 +1,141 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/util/entitlements"
+)
+
+func Test_getSolveOptNetwork(t *testing.T) {
+	dir, err := ioutil.TempDir("", "okteto-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		os.RemoveAll(dir)
+		os.Unsetenv("OKTETO_FOLDER")
+	}()
+
+	os.Setenv("OKTETO_FOLDER", dir)
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	if err := ioutil.WriteFile(dockerfile, []byte("FROM alpine"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		network      string
+		expected     string
+		entitlements []entitlements.Entitlement
+	}{
+		{
+			name:    "unset",
+			network: "",
+		},
+		{
+			name:    "default",
+			network: "default",
+		},
+		{
+			name:         "host",
+			network:      "host",
+			expected:     "host",
+			entitlements: []entitlements.Entitlement{entitlements.EntitlementNetworkHost},
+		},
+		{
+			name:     "none",
+			network:  "none",
+			expected: "none",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := getSolveOpt(dir, dockerfile, "", "", tt.network, false, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opt.FrontendAttrs["force-network-mode"] != tt.expected {
+				t.Errorf("wrong network mode: '%s'", opt.FrontendAttrs["force-network-mode"])
+			}
+			if !reflect.DeepEqual(opt.AllowedEntitlements, tt.entitlements) {
+				t.Errorf("wrong entitlements: '%v'", opt.AllowedEntitlements)
+			}
+		})
+	}
+}
+
+func Test_getSolveOptCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "okteto-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		os.RemoveAll(dir)
+		os.Unsetenv("OKTETO_FOLDER")
+	}()
+
+	os.Setenv("OKTETO_FOLDER", dir)
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	if err := ioutil.WriteFile(dockerfile, []byte("FROM alpine"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		tag       string
+		cacheFrom []string
+		imports   []client.CacheOptionsEntry
+		exports   []client.CacheOptionsEntry
+	}{
+		{
+			name:    "no-cache",
+			tag:     "registry.okteto.net/ns/api:okteto",
+			imports: []client.CacheOptionsEntry{},
+		},
+		{
+			name:      "other-image",
+			tag:       "registry.okteto.net/ns/api:okteto",
+			cacheFrom: []string{"registry.okteto.net/ns/base:latest"},
+			imports:   []client.CacheOptionsEntry{{Type: "registry", Attrs: map[string]string{"ref": "registry.okteto.net/ns/base:latest"}}},
+		},
+		{
+			name:      "own-image",
+			tag:       "registry.okteto.net/ns/api:okteto",
+			cacheFrom: []string{"registry.okteto.net/ns/api:okteto"},
+			imports:   []client.CacheOptionsEntry{{Type: "registry", Attrs: map[string]string{"ref": "registry.okteto.net/ns/api:okteto"}}},
+			exports:   []client.CacheOptionsEntry{{Type: "inline"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := getSolveOpt(dir, dockerfile, tt.tag, "", "", false, tt.cacheFrom, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(opt.CacheImports, tt.imports) {
+				t.Errorf("wrong cache imports: '%v'", opt.CacheImports)
+			}
+			if !reflect.DeepEqual(opt.CacheExports, tt.exports) {
+				t.Errorf("wrong cache exports: '%v'", opt.CacheExports)
+			}
+		})
+	}
+}
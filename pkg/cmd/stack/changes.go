@@ -0,0 +1,89 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/okteto/okteto/pkg/model"
+)
+
+//changedFilesGetter returns the absolute paths of the files changed since a git ref in the repository containing dir
+type changedFilesGetter func(dir, ref string) ([]string, error)
+
+//getChangedServices returns the services whose build context or dockerfile changed since a git ref
+func getChangedServices(s *model.Stack, ref string, getChangedFiles changedFilesGetter) (map[string]bool, error) {
+	result := map[string]bool{}
+	cache := map[string][]string{}
+	for name, svc := range s.Services {
+		if svc.Build == nil {
+			continue
+		}
+		files, ok := cache[svc.Build.Context]
+		if !ok {
+			var err error
+			files, err = getChangedFiles(svc.Build.Context, ref)
+			if err != nil {
+				return nil, err
+			}
+			cache[svc.Build.Context] = files
+		}
+		for _, f := range files {
+			if isPathInside(f, svc.Build.Context) || f == svc.Build.Dockerfile {
+				result[name] = true
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func isPathInside(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+//getGitChangedFiles returns the files changed since a git ref, including uncommitted and untracked files
+func getGitChangedFiles(dir, ref string) ([]string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not in a git repository: %s", dir, err)
+	}
+	root := strings.TrimSpace(string(out))
+
+	diff, err := exec.Command("git", "-C", root, "diff", "--name-only", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the changes since '%s': %s", ref, err)
+	}
+	untracked, err := exec.Command("git", "-C", root, "ls-files", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the untracked files: %s", err)
+	}
+
+	result := []string{}
+	for _, line := range strings.Split(string(diff)+"\n"+string(untracked), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		result = append(result, filepath.Join(root, filepath.FromSlash(line)))
+	}
+	return result, nil
+}
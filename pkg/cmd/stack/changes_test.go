@@ -0,0 +1,67 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+)
+
+func Test_getChangedServices(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"api":      {Build: &model.BuildInfo{Context: "/repo/api", Dockerfile: "/repo/api/Dockerfile"}},
+			"apigw":    {Build: &model.BuildInfo{Context: "/repo/apigw", Dockerfile: "/repo/apigw/Dockerfile"}},
+			"frontend": {Build: &model.BuildInfo{Context: "/repo/frontend", Dockerfile: "/repo/docker/frontend.Dockerfile"}},
+			"worker":   {Build: &model.BuildInfo{Context: "/repo/worker", Dockerfile: "/repo/worker/Dockerfile"}},
+			"db":       {Image: "postgres:13"},
+		},
+	}
+
+	calls := 0
+	getChangedFiles := func(dir, ref string) ([]string, error) {
+		calls++
+		if ref != "main" {
+			t.Errorf("wrong ref: %s", ref)
+		}
+		return []string{
+			"/repo/api/main.go",
+			"/repo/docker/frontend.Dockerfile",
+			"/repo/README.md",
+		}, nil
+	}
+
+	result, err := getChangedServices(s, "main", getChangedFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]bool{"api": true, "frontend": true}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("wrong changed services: %v, expected %v", result, expected)
+	}
+	if calls != 4 {
+		t.Errorf("wrong number of calls to the changed files provider: %d", calls)
+	}
+
+	failing := func(dir, ref string) ([]string, error) {
+		return nil, fmt.Errorf("git not found")
+	}
+	if _, err := getChangedServices(s, "main", failing); err == nil {
+		t.Errorf("getChangedServices() didn't fail when git is not available")
+	}
+}
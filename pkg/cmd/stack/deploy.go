@@ -17,6 +17,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,21 +25,44 @@ import (
 	"github.com/okteto/okteto/pkg/errors"
 	"github.com/okteto/okteto/pkg/k8s/client"
 	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	"github.com/okteto/okteto/pkg/k8s/cronjobs"
+	"github.com/okteto/okteto/pkg/k8s/daemonsets"
 	"github.com/okteto/okteto/pkg/k8s/deployments"
+	"github.com/okteto/okteto/pkg/k8s/hpa"
 	"github.com/okteto/okteto/pkg/k8s/ingress"
+	"github.com/okteto/okteto/pkg/k8s/jobs"
 	okLabels "github.com/okteto/okteto/pkg/k8s/labels"
+	"github.com/okteto/okteto/pkg/k8s/pdb"
 	"github.com/okteto/okteto/pkg/k8s/pods"
 	"github.com/okteto/okteto/pkg/k8s/services"
 	"github.com/okteto/okteto/pkg/k8s/statefulsets"
+	"github.com/okteto/okteto/pkg/k8s/volumes"
 	"github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/okteto"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	rolloutTimeout    = 15 * time.Minute
+	dependencyTimeout = 5 * time.Minute
+)
+
+//DeployOptions represents the options of a stack deployment
+type DeployOptions struct {
+	ForceBuild        bool
+	Wait              bool
+	NoCache           bool
+	ForceRecreatePods bool
+	RollbackOnFailure bool
+	ServicesToDeploy  []string
+	BuildChangedSince string
+}
+
 //Deploy deploys a stack
-func Deploy(ctx context.Context, s *model.Stack, forceBuild, wait, noCache bool) error {
+func Deploy(ctx context.Context, s *model.Stack, options *DeployOptions) error {
 	if s.Namespace == "" {
 		s.Namespace = client.GetContextNamespace("")
 	}
@@ -49,6 +73,13 @@ func Deploy(ctx context.Context, s *model.Stack, forceBuild, wait, noCache bool)
 	}
 
 	cfg := translateConfigMap(s)
+	old, err := configmaps.Get(ctx, cfg.Name, s.Namespace, c)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err := translateHistory(cfg, old, s, okteto.GetUsername(), time.Now()); err != nil {
+		return err
+	}
 	output := fmt.Sprintf("Deploying stack '%s'...", s.Name)
 	cfg.Data[statusField] = progressingStatus
 	cfg.Data[outputField] = base64.StdEncoding.EncodeToString([]byte(output))
@@ -56,7 +87,7 @@ func Deploy(ctx context.Context, s *model.Stack, forceBuild, wait, noCache bool)
 		return err
 	}
 
-	err = deploy(ctx, s, forceBuild, wait, noCache, c)
+	err = deploy(ctx, s, options, c)
 	if err != nil {
 		output = fmt.Sprintf("%s\nStack '%s' deployment failed: %s", output, s.Name, err.Error())
 		cfg.Data[statusField] = errorStatus
@@ -74,18 +105,87 @@ func Deploy(ctx context.Context, s *model.Stack, forceBuild, wait, noCache bool)
 	return err
 }
 
-func deploy(ctx context.Context, s *model.Stack, forceBuild, wait, noCache bool, c *kubernetes.Clientset) error {
+func deploy(ctx context.Context, s *model.Stack, options *DeployOptions, c *kubernetes.Clientset) error {
+	if len(options.ServicesToDeploy) > 0 {
+		var err error
+		s, err = s.Filter(options.ServicesToDeploy)
+		if err != nil {
+			return err
+		}
+	}
 
-	if err := translate(ctx, s, forceBuild, noCache); err != nil {
+	s, err := translate(ctx, s, c, options)
+	if err != nil {
 		return err
 	}
 
+	if err := checkStorageClasses(ctx, s, c); err != nil {
+		return err
+	}
+
+	if options.ForceRecreatePods {
+		for name, svc := range s.Services {
+			svc.SetRestartedAtAnnotation()
+			s.Services[name] = svc
+		}
+	}
+
 	spinner := utils.NewSpinner(fmt.Sprintf("Deploying stack '%s'...", s.Name))
 	spinner.Start()
 	defer spinner.Stop()
 
-	for name := range s.Services {
-		if len(s.Services[name].Volumes) == 0 {
+	for _, obj := range GetApplyOrder(s) {
+		log.Infof("applying %s", obj.String())
+	}
+
+	for _, name := range s.GetVolumeNames() {
+		if err := deployVolume(ctx, name, s, c); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range getServiceDeployOrder(s) {
+		svc := s.Services[name]
+		for _, dependency := range getHealthyDependencies(&svc) {
+			spinner.Update(fmt.Sprintf("Waiting for service '%s' to be healthy...", dependency))
+			if err := waitForServiceToBeHealthy(ctx, dependency, s, c, dependencyTimeout); err != nil {
+				return err
+			}
+		}
+		spinner.Update(fmt.Sprintf("Deploying stack '%s'...", s.Name))
+		if svcK8s := translateService(name, s); svcK8s != nil {
+			if err := services.Create(ctx, svcK8s, c); err != nil {
+				return err
+			}
+		}
+		if svc.IsCronJob() {
+			if err := deployCronJob(ctx, name, s, c); err != nil {
+				return err
+			}
+			spinner.Stop()
+			log.Success("Deployed cronjob '%s'", name)
+			spinner.Start()
+			continue
+		}
+		if svc.IsJob() {
+			recreated, err := deployJob(ctx, name, s, c)
+			if err != nil {
+				return err
+			}
+			spinner.Stop()
+			if recreated {
+				log.Success("Deployed job '%s'", name)
+			} else {
+				log.Success("Job '%s' didn't change and was not run again", name)
+			}
+			spinner.Start()
+			continue
+		}
+		if svc.IsGlobal() {
+			if err := deployDaemonSet(ctx, name, s, c); err != nil {
+				return err
+			}
+		} else if len(svc.Volumes) == 0 {
 			if err := deployDeployment(ctx, name, s, c); err != nil {
 				return err
 			}
@@ -94,9 +194,13 @@ func deploy(ctx context.Context, s *model.Stack, forceBuild, wait, noCache bool,
 				return err
 			}
 		}
-		if len(s.Services[name].Ports) > 0 {
-			svcK8s := translateService(name, s)
-			if err := services.Create(ctx, svcK8s, c); err != nil {
+		if hpaK8s := translateHorizontalPodAutoscaler(name, s); hpaK8s != nil {
+			if err := hpa.Deploy(ctx, hpaK8s, c); err != nil {
+				return err
+			}
+		}
+		if pdbK8s := translatePodDisruptionBudget(name, s); pdbK8s != nil {
+			if err := pdb.Deploy(ctx, pdbK8s, c); err != nil {
 				return err
 			}
 		}
@@ -105,23 +209,62 @@ func deploy(ctx context.Context, s *model.Stack, forceBuild, wait, noCache bool,
 		spinner.Start()
 	}
 
-	if err := destroyServicesNotInStack(ctx, spinner, s, c); err != nil {
-		return err
+	if len(options.ServicesToDeploy) == 0 {
+		if err := destroyServicesNotInStack(ctx, spinner, s, c); err != nil {
+			return err
+		}
 	}
 
-	for name := range s.Endpoints {
+	for _, name := range getSortedEndpointNames(s) {
 		if err := deployIngress(ctx, name, s, c); err != nil {
 			return err
 		}
 	}
 
-	if !wait {
+	if options.RollbackOnFailure {
+		for name, svc := range s.Services {
+			if len(svc.Volumes) > 0 || svc.IsJob() || svc.IsCronJob() || svc.IsGlobal() {
+				continue
+			}
+			spinner.Update(fmt.Sprintf("Waiting for service '%s' to be rolled out...", name))
+			if err := waitForDeploymentRollout(ctx, name, s, c, rolloutTimeout); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !options.Wait {
 		return nil
 	}
 
 	spinner.Update("Waiting for services to be ready...")
-	return waitForPodsToBeRunning(ctx, s, c)
+	if err := waitForPodsToBeRunning(ctx, s, c, 300*time.Second); err != nil {
+		return err
+	}
+	for _, name := range getSortedServiceNames(s) {
+		svc := s.Services[name]
+		if !svc.IsGlobal() {
+			continue
+		}
+		if err := waitForDaemonSetRollout(ctx, name, s, c, rolloutTimeout); err != nil {
+			return err
+		}
+	}
 
+	for _, name := range getSortedServiceNames(s) {
+		svc := s.Services[name]
+		if !svc.IsJob() {
+			continue
+		}
+		spinner.Update(fmt.Sprintf("Waiting for job '%s' to complete...", name))
+		if err := waitForJobToComplete(ctx, name, s, c, rolloutTimeout); err != nil {
+			return err
+		}
+		spinner.Stop()
+		log.Success("Job '%s' completed", name)
+		spinner.Start()
+	}
+	return nil
 }
 
 func deployDeployment(ctx context.Context, svcName string, s *model.Stack, c *kubernetes.Clientset) error {
@@ -141,6 +284,9 @@ func deployDeployment(ctx context.Context, svcName string, s *model.Stack, c *ku
 		if deployments.IsDevModeOn(old) {
 			deployments.RestoreDevModeFrom(d, old)
 		}
+		if d.Spec.Replicas == nil {
+			d.Spec.Replicas = old.Spec.Replicas
+		}
 	}
 	if err := deployments.Deploy(ctx, d, isNewDeployment, c); err != nil {
 		if isNewDeployment {
@@ -171,6 +317,12 @@ func deployStatefulSet(ctx context.Context, svcName string, s *model.Stack, c *k
 		if v, ok := old.Labels[okLabels.DeployedByLabel]; ok {
 			sfs.Labels[okLabels.DeployedByLabel] = v
 		}
+		if sfs.Spec.Replicas == nil {
+			sfs.Spec.Replicas = old.Spec.Replicas
+		}
+		if err := expandStatefulSetVolumes(ctx, svcName, s, c); err != nil {
+			return fmt.Errorf("error updating statefulset of service '%s': %s", svcName, err.Error())
+		}
 		if err := statefulsets.Update(ctx, sfs, c); err != nil {
 			if !strings.Contains(err.Error(), "Forbidden: updates to statefulset spec") {
 				return fmt.Errorf("error updating statefulset of service '%s': %s", svcName, err.Error())
@@ -186,7 +338,195 @@ func deployStatefulSet(ctx context.Context, svcName string, s *model.Stack, c *k
 	return nil
 }
 
+func deployDaemonSet(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface) error {
+	ds := translateDaemonSet(svcName, s)
+	old, err := daemonsets.Get(ctx, svcName, s.Namespace, c)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting daemonset of service '%s': %s", svcName, err.Error())
+	}
+	if err != nil || old.Name == "" {
+		if err := daemonsets.Create(ctx, ds, c); err != nil {
+			return fmt.Errorf("error creating daemonset of service '%s': %s", svcName, err.Error())
+		}
+		return nil
+	}
+	if old.Labels[okLabels.StackNameLabel] == "" {
+		return fmt.Errorf("name collision: the daemonset '%s' was running before deploying your stack", svcName)
+	}
+	if ds.Labels[okLabels.StackNameLabel] != old.Labels[okLabels.StackNameLabel] {
+		return fmt.Errorf("name collision: the daemonset '%s' belongs to the stack '%s'", svcName, old.Labels[okLabels.StackNameLabel])
+	}
+	if err := daemonsets.Update(ctx, ds, c); err != nil {
+		return fmt.Errorf("error updating daemonset of service '%s': %s", svcName, err.Error())
+	}
+	return nil
+}
+
+//deployJob creates the job of a service, or recreates it if it changed, since jobs are immutable.
+//It returns false if the job didn't change, so completed jobs don't run again
+func deployJob(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface) (bool, error) {
+	job := translateJob(svcName, s)
+	old, err := jobs.Get(ctx, svcName, s.Namespace, c)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("error getting job of service '%s': %s", svcName, err.Error())
+	}
+	if err == nil {
+		if old.Labels[okLabels.StackNameLabel] == "" {
+			return false, fmt.Errorf("name collision: the job '%s' was running before deploying your stack", svcName)
+		}
+		if job.Labels[okLabels.StackNameLabel] != old.Labels[okLabels.StackNameLabel] {
+			return false, fmt.Errorf("name collision: the job '%s' belongs to the stack '%s'", svcName, old.Labels[okLabels.StackNameLabel])
+		}
+		if old.Annotations[okLabels.StackJobChecksumAnnotation] == job.Annotations[okLabels.StackJobChecksumAnnotation] {
+			return false, nil
+		}
+		if err := jobs.Destroy(ctx, svcName, s.Namespace, c); err != nil {
+			return false, fmt.Errorf("error updating job of service '%s': %s", svcName, err.Error())
+		}
+	}
+	if err := jobs.Create(ctx, job, c); err != nil {
+		return false, fmt.Errorf("error creating job of service '%s': %s", svcName, err.Error())
+	}
+	return true, nil
+}
+
+func deployCronJob(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface) error {
+	cronJob := translateCronJob(svcName, s)
+	old, err := cronjobs.Get(ctx, svcName, s.Namespace, c)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting cronjob of service '%s': %s", svcName, err.Error())
+	}
+	if err != nil {
+		if err := cronjobs.Create(ctx, cronJob, c); err != nil {
+			return fmt.Errorf("error creating cronjob of service '%s': %s", svcName, err.Error())
+		}
+		return nil
+	}
+	if old.Labels[okLabels.StackNameLabel] == "" {
+		return fmt.Errorf("name collision: the cronjob '%s' was running before deploying your stack", svcName)
+	}
+	if cronJob.Labels[okLabels.StackNameLabel] != old.Labels[okLabels.StackNameLabel] {
+		return fmt.Errorf("name collision: the cronjob '%s' belongs to the stack '%s'", svcName, old.Labels[okLabels.StackNameLabel])
+	}
+	cronJob.ResourceVersion = old.ResourceVersion
+	if err := cronjobs.Update(ctx, cronJob, c); err != nil {
+		return fmt.Errorf("error updating cronjob of service '%s': %s", svcName, err.Error())
+	}
+	return nil
+}
+
+//deployVolume creates the volume claim of a named volume, or expands it if its size was increased
+func deployVolume(ctx context.Context, volumeName string, s *model.Stack, c kubernetes.Interface) error {
+	pvc := translatePersistentVolumeClaim(volumeName, s)
+	old, err := volumes.Get(ctx, volumeName, s.Namespace, c)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting volume '%s': %s", volumeName, err.Error())
+	}
+	if err != nil {
+		return volumes.CreateClaim(ctx, pvc, c)
+	}
+	if old.Labels[okLabels.StackNameLabel] == "" {
+		return fmt.Errorf("name collision: the volume '%s' was created before deploying your stack", volumeName)
+	}
+	if pvc.Labels[okLabels.StackNameLabel] != old.Labels[okLabels.StackNameLabel] {
+		return fmt.Errorf("name collision: the volume '%s' belongs to the stack '%s'", volumeName, old.Labels[okLabels.StackNameLabel])
+	}
+	size := pvc.Spec.Resources.Requests[apiv1.ResourceStorage]
+	if size.IsZero() {
+		return nil
+	}
+	return volumes.Expand(ctx, old, size, c)
+}
+
+//expandStatefulSetVolumes increases the size of the existing volumes of a service, since volume claim templates are immutable
+func expandStatefulSetVolumes(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface) error {
+	svc := s.Services[svcName]
+	size := translateStorageSize(&svc)
+	if size.IsZero() {
+		return nil
+	}
+	selector := okLabels.TransformLabelsToSelector(translateLabelSelector(svcName, s))
+	pvcList, err := volumes.List(ctx, s.Namespace, selector, c)
+	if err != nil {
+		return err
+	}
+	prefix := fmt.Sprintf("%s-%s-", pvcName, svcName)
+	for i := range pvcList {
+		if !strings.HasPrefix(pvcList[i].Name, prefix) {
+			continue
+		}
+		if err := volumes.Expand(ctx, &pvcList[i], size, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//getRequiredStorageClasses returns the storage classes of the statefulsets and volumes of the stack, with the services and volumes using each of them
+func getRequiredStorageClasses(s *model.Stack) map[string][]string {
+	result := map[string][]string{}
+	for _, name := range getSortedServiceNames(s) {
+		svc := s.Services[name]
+		if len(svc.Volumes) == 0 || svc.IsJob() || svc.IsCronJob() || svc.IsGlobal() {
+			continue
+		}
+		if class := translateStorageClass(&svc); class != nil {
+			result[*class] = append(result[*class], fmt.Sprintf("service '%s'", name))
+		}
+	}
+	for _, name := range s.GetVolumeNames() {
+		if class := s.Volumes[name].Class; class != "" {
+			result[class] = append(result[class], fmt.Sprintf("volume '%s'", name))
+		}
+	}
+	return result
+}
+
+//checkStorageClasses fails if a storage class used by the stack doesn't exist, since its volumes would be pending forever.
+//The check is skipped if the storage classes of the cluster can't be listed, like when the user doesn't have cluster permissions
+func checkStorageClasses(ctx context.Context, s *model.Stack, c kubernetes.Interface) error {
+	required := getRequiredStorageClasses(s)
+	if len(required) == 0 {
+		return nil
+	}
+	scList, err := c.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Infof("skipping the storage classes check, they couldn't be listed: %s", err)
+		return nil
+	}
+	available := make([]string, 0, len(scList.Items))
+	exists := map[string]bool{}
+	for _, sc := range scList.Items {
+		available = append(available, sc.Name)
+		exists[sc.Name] = true
+	}
+	sort.Strings(available)
+
+	classes := make([]string, 0, len(required))
+	for class := range required {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	missing := []string{}
+	for _, class := range classes {
+		if !exists[class] {
+			missing = append(missing, fmt.Sprintf("storage class '%s' used by %s", class, strings.Join(required[class], ", ")))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	msg := "the cluster doesn't have any storage class"
+	if len(available) > 0 {
+		msg = fmt.Sprintf("available storage classes are '%s'", strings.Join(available, "', '"))
+	}
+	return fmt.Errorf("some storage classes of stack '%s' don't exist:\n    - %s\n    %s", s.Name, strings.Join(missing, "\n    - "), msg)
+}
+
 func deployIngress(ctx context.Context, ingressName string, s *model.Stack, c *kubernetes.Clientset) error {
+	if s.IngressV1 {
+		return deployIngressV1(ctx, ingressName, s, c)
+	}
 	ingressK8s := translateIngress(ingressName, s)
 	old, err := c.ExtensionsV1beta1().Ingresses(s.Namespace).Get(ctx, ingressName, metav1.GetOptions{})
 	if err != nil && !errors.IsNotFound(err) {
@@ -194,11 +534,8 @@ func deployIngress(ctx context.Context, ingressName string, s *model.Stack, c *k
 	}
 	isNewIngress := old.Name == ""
 	if !isNewIngress {
-		if old.Labels[okLabels.StackNameLabel] == "" {
-			return fmt.Errorf("name collision: the ingress '%s' was running before deploying your stack", ingressName)
-		}
-		if ingressK8s.Labels[okLabels.StackNameLabel] != old.Labels[okLabels.StackNameLabel] {
-			return fmt.Errorf("name collision: the ingress '%s' belongs to the stack '%s'", ingressName, old.Labels[okLabels.StackNameLabel])
+		if err := checkIngressCollision(ingressName, old.Labels, s); err != nil {
+			return err
 		}
 		ingress.Update(ctx, ingressK8s, c)
 	} else if err := ingress.Create(ctx, ingressK8s, c); err != nil {
@@ -207,17 +544,192 @@ func deployIngress(ctx context.Context, ingressName string, s *model.Stack, c *k
 	return nil
 }
 
-func waitForPodsToBeRunning(ctx context.Context, s *model.Stack, c *kubernetes.Clientset) error {
+func deployIngressV1(ctx context.Context, ingressName string, s *model.Stack, c *kubernetes.Clientset) error {
+	ingressK8s := translateIngressV1(ingressName, s)
+	old, err := ingress.GetV1(ctx, ingressName, s.Namespace, c)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting ingress '%s': %s", ingressName, err.Error())
+	}
+	if err == nil && old.Name != "" {
+		if err := checkIngressCollision(ingressName, old.Labels, s); err != nil {
+			return err
+		}
+		return ingress.UpdateV1(ctx, ingressK8s, c)
+	}
+	return ingress.CreateV1(ctx, ingressK8s, c)
+}
+
+//checkIngressCollision returns an error if a running ingress doesn't belong to the stack
+func checkIngressCollision(ingressName string, oldLabels map[string]string, s *model.Stack) error {
+	if oldLabels[okLabels.StackNameLabel] == "" {
+		return fmt.Errorf("name collision: the ingress '%s' was running before deploying your stack", ingressName)
+	}
+	if s.Name != oldLabels[okLabels.StackNameLabel] {
+		return fmt.Errorf("name collision: the ingress '%s' belongs to the stack '%s'", ingressName, oldLabels[okLabels.StackNameLabel])
+	}
+	return nil
+}
+
+//waitForDeploymentRollout waits for the deployment of a service to be rolled out, rolling it back if its rollout fails
+func waitForDeploymentRollout(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface, timeout time.Duration) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	to := time.Now().Add(timeout)
+
+	for time.Now().Before(to) {
+		d, err := c.AppsV1().Deployments(s.Namespace).Get(ctx, svcName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error getting deployment of service '%s': %s", svcName, err.Error())
+		}
+		if deployments.IsRolledOut(d) {
+			return nil
+		}
+		if deployments.IsProgressDeadlineExceeded(d) {
+			if err := deployments.Rollback(ctx, d, c); err != nil {
+				return fmt.Errorf("service '%s' failed to roll out and couldn't be rolled back: %s", svcName, err)
+			}
+			return fmt.Errorf("service '%s' failed to roll out and was rolled back to its previous revision", svcName)
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("kubernetes is taking too long to roll out the service '%s'. Please check for errors and try again", svcName)
+}
+
+//getHealthyDependencies returns the sorted dependencies of a service with the 'service_healthy' condition
+func getHealthyDependencies(svc *model.Service) []string {
+	result := []string{}
+	for dependency, spec := range svc.DependsOn {
+		if spec.Condition == model.DependsOnServiceHealthy {
+			result = append(result, dependency)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+//waitForServiceToBeHealthy waits until the latest version of a service is healthy: jobs must complete,
+//and every pod of deployments, statefulsets and daemonsets must be updated and ready
+func waitForServiceToBeHealthy(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface, timeout time.Duration) error {
+	svc := s.Services[svcName]
+	if svc.IsJob() {
+		return waitForJobToComplete(ctx, svcName, s, c, timeout)
+	}
+	if svc.IsGlobal() {
+		return waitForDaemonSetRollout(ctx, svcName, s, c, timeout)
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	to := time.Now().Add(timeout)
+
+	for time.Now().Before(to) {
+		if len(svc.Volumes) == 0 {
+			d, err := c.AppsV1().Deployments(s.Namespace).Get(ctx, svcName, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error getting deployment of service '%s': %s", svcName, err.Error())
+			}
+			if deployments.IsRolledOut(d) {
+				return nil
+			}
+			if deployments.IsProgressDeadlineExceeded(d) {
+				return fmt.Errorf("service '%s' failed to roll out. Please check for errors and try again", svcName)
+			}
+		} else {
+			sfs, err := statefulsets.Get(ctx, svcName, s.Namespace, c)
+			if err != nil {
+				return fmt.Errorf("error getting statefulset of service '%s': %s", svcName, err.Error())
+			}
+			if statefulsets.IsRolledOut(sfs) {
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("kubernetes is taking too long to start the service '%s'. Please check for errors and try again", svcName)
+}
+
+//waitForDaemonSetRollout waits until the pods of a global service are updated and available in all its nodes
+func waitForDaemonSetRollout(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface, timeout time.Duration) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	to := time.Now().Add(timeout)
+
+	for time.Now().Before(to) {
+		ds, err := daemonsets.Get(ctx, svcName, s.Namespace, c)
+		if err != nil {
+			return fmt.Errorf("error getting daemonset of service '%s': %s", svcName, err.Error())
+		}
+		if daemonsets.IsRolledOut(ds) {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("kubernetes is taking too long to start the pods of service '%s' in every node. Please check for errors and try again", svcName)
+}
+
+//waitForJobToComplete waits until the job of a service finishes successfully
+func waitForJobToComplete(ctx context.Context, svcName string, s *model.Stack, c kubernetes.Interface, timeout time.Duration) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	to := time.Now().Add(timeout)
+
+	for time.Now().Before(to) {
+		job, err := jobs.Get(ctx, svcName, s.Namespace, c)
+		if err != nil {
+			return fmt.Errorf("error getting job of service '%s': %s", svcName, err.Error())
+		}
+		if jobs.IsCompleted(job) {
+			return nil
+		}
+		if jobs.IsFailed(job) {
+			return fmt.Errorf("job '%s' failed. Please check its logs and try again", svcName)
+		}
+
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("kubernetes is taking too long to complete the job '%s'. Please check for errors and try again", svcName)
+}
+
+//waitForPodsToBeRunning waits until the pods of every service, except jobs, are running. Autoscaled services wait for their minimum replicas.
+//The pods of global services depend on the number of nodes, and are awaited by waitForDaemonSetRollout
+func waitForPodsToBeRunning(ctx context.Context, s *model.Stack, c kubernetes.Interface, timeout time.Duration) error {
 	var numPods int32 = 0
 	for _, svc := range s.Services {
-		numPods += svc.Replicas
+		if svc.IsJob() || svc.IsCronJob() || svc.IsGlobal() {
+			continue
+		}
+		numPods += svc.GetMinReplicas()
 	}
 
 	ticker := time.NewTicker(100 * time.Millisecond)
-	timeout := time.Now().Add(300 * time.Second)
+	defer ticker.Stop()
+	to := time.Now().Add(timeout)
 
 	selector := map[string]string{okLabels.StackNameLabel: s.Name}
-	for time.Now().Before(timeout) {
+	for time.Now().Before(to) {
 		<-ticker.C
 		pendingPods := numPods
 		podList, err := pods.ListBySelector(ctx, s.Namespace, selector, c)
@@ -225,11 +737,14 @@ func waitForPodsToBeRunning(ctx context.Context, s *model.Stack, c *kubernetes.C
 			return err
 		}
 		for i := range podList {
+			if svc, ok := s.Services[podList[i].Labels[okLabels.StackServiceNameLabel]]; !ok || svc.IsJob() || svc.IsCronJob() || svc.IsGlobal() {
+				continue
+			}
 			if podList[i].Status.Phase == apiv1.PodRunning {
 				pendingPods--
 			}
 		}
-		if pendingPods == 0 {
+		if pendingPods <= 0 {
 			return nil
 		}
 	}
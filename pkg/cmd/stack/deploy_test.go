@@ -0,0 +1,539 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	okLabels "github.com/okteto/okteto/pkg/k8s/labels"
+	"github.com/okteto/okteto/pkg/model"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func newRolloutReplicaSet(name, revision, image string, owner *appsv1.Deployment) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       owner.Namespace,
+			Labels:          owner.Spec.Selector.MatchLabels,
+			Annotations:     map[string]string{"deployment.kubernetes.io/revision": revision},
+			OwnerReferences: []metav1.OwnerReference{{UID: owner.UID}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":                                  "svcName",
+						appsv1.DefaultDeploymentUniqueLabelKey: name,
+					},
+				},
+				Spec: apiv1.PodSpec{Containers: []apiv1.Container{{Name: "svcName", Image: image}}},
+			},
+		},
+	}
+}
+
+func Test_waitForDeploymentRollout(t *testing.T) {
+	tests := []struct {
+		name           string
+		conditions     []appsv1.DeploymentCondition
+		available      int32
+		wantErr        bool
+		expectedImage  string
+		withReplicaSet bool
+	}{
+		{
+			name:           "rolled-out",
+			available:      1,
+			expectedImage:  "image:2",
+			withReplicaSet: true,
+		},
+		{
+			name: "progress-deadline-exceeded",
+			conditions: []appsv1.DeploymentCondition{
+				{
+					Type:   appsv1.DeploymentProgressing,
+					Status: apiv1.ConditionFalse,
+					Reason: "ProgressDeadlineExceeded",
+				},
+			},
+			wantErr:        true,
+			expectedImage:  "image:1",
+			withReplicaSet: true,
+		},
+		{
+			name: "progress-deadline-exceeded-without-previous-revision",
+			conditions: []appsv1.DeploymentCondition{
+				{
+					Type:   appsv1.DeploymentProgressing,
+					Status: apiv1.ConditionFalse,
+					Reason: "ProgressDeadlineExceeded",
+				},
+			},
+			wantErr:       true,
+			expectedImage: "image:2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			d := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "svcName",
+					Namespace:   "namespace",
+					UID:         types.UID("uid"),
+					Annotations: map[string]string{"deployment.kubernetes.io/revision": "2"},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: pointer.Int32Ptr(1),
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "svcName"}},
+					Template: apiv1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "svcName"}},
+						Spec:       apiv1.PodSpec{Containers: []apiv1.Container{{Name: "svcName", Image: "image:2"}}},
+					},
+				},
+				Status: appsv1.DeploymentStatus{
+					Replicas:          1,
+					UpdatedReplicas:   1,
+					AvailableReplicas: tt.available,
+					Conditions:        tt.conditions,
+				},
+			}
+			objects := []runtime.Object{d}
+			if tt.withReplicaSet {
+				objects = append(
+					objects,
+					newRolloutReplicaSet("svcName-1", "1", "image:1", d),
+					newRolloutReplicaSet("svcName-2", "2", "image:2", d),
+				)
+			}
+			c := fake.NewSimpleClientset(objects...)
+			s := &model.Stack{Name: "stackName", Namespace: "namespace"}
+
+			err := waitForDeploymentRollout(ctx, "svcName", s, c, time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("waitForDeploymentRollout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			result, err := c.AppsV1().Deployments("namespace").Get(ctx, "svcName", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if image := result.Spec.Template.Spec.Containers[0].Image; image != tt.expectedImage {
+				t.Errorf("wrong image after rollout: '%s'", image)
+			}
+			if _, ok := result.Spec.Template.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+				t.Errorf("'%s' label was not removed from the pod template", appsv1.DefaultDeploymentUniqueLabelKey)
+			}
+		})
+	}
+}
+
+func newStackVolume(name, size, class string) *apiv1.PersistentVolumeClaim {
+	return &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "namespace",
+			Labels: map[string]string{
+				okLabels.StackNameLabel:        "stackName",
+				okLabels.StackServiceNameLabel: "svcName",
+			},
+		},
+		Spec: apiv1.PersistentVolumeClaimSpec{
+			StorageClassName: pointer.StringPtr(class),
+			Resources: apiv1.ResourceRequirements{
+				Requests: apiv1.ResourceList{apiv1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func Test_expandStatefulSetVolumes(t *testing.T) {
+	tests := []struct {
+		name         string
+		class        string
+		currentSize  string
+		expectedSize string
+		wantErr      bool
+	}{
+		{
+			name:         "expand",
+			class:        "expandable",
+			currentSize:  "10Gi",
+			expectedSize: "20Gi",
+		},
+		{
+			name:         "same-size",
+			class:        "fixed",
+			currentSize:  "20Gi",
+			expectedSize: "20Gi",
+		},
+		{
+			name:         "decrease",
+			class:        "expandable",
+			currentSize:  "30Gi",
+			expectedSize: "30Gi",
+			wantErr:      true,
+		},
+		{
+			name:         "expand-not-allowed",
+			class:        "fixed",
+			currentSize:  "10Gi",
+			expectedSize: "10Gi",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := fake.NewSimpleClientset(
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: pointer.BoolPtr(true)},
+				&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}},
+				newStackVolume("pvc-svcName-0", tt.currentSize, tt.class),
+				newStackVolume("pvc-svcName-1", tt.currentSize, tt.class),
+			)
+			s := &model.Stack{
+				Name:      "stackName",
+				Namespace: "namespace",
+				Services: map[string]model.Service{
+					"svcName": {
+						Image:   "image",
+						Volumes: []string{"/data"},
+						Resources: model.StackResources{
+							Requests: model.ServiceResources{
+								Storage: model.StorageResource{Size: model.Quantity{Value: resource.MustParse("20Gi")}},
+							},
+						},
+					},
+				},
+			}
+
+			err := expandStatefulSetVolumes(ctx, "svcName", s, c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandStatefulSetVolumes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, name := range []string{"pvc-svcName-0", "pvc-svcName-1"} {
+				pvc, err := c.CoreV1().PersistentVolumeClaims("namespace").Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				size := pvc.Spec.Resources.Requests[apiv1.ResourceStorage]
+				if size.Cmp(resource.MustParse(tt.expectedSize)) != 0 {
+					t.Errorf("wrong size of volume '%s': '%s'", name, size.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_waitForServiceToBeHealthy(t *testing.T) {
+	newDeployment := func(generation, observed int64, updated, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "namespace", Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: observed,
+				Replicas:           2,
+				UpdatedReplicas:    updated,
+				AvailableReplicas:  available,
+			},
+		}
+	}
+	newStatefulSet := func(current, update string, ready int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "namespace"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32Ptr(1)},
+			Status: appsv1.StatefulSetStatus{
+				CurrentRevision: current,
+				UpdateRevision:  update,
+				UpdatedReplicas: 1,
+				ReadyReplicas:   ready,
+			},
+		}
+	}
+	newJob := func(condition batchv1.JobConditionType) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "namespace"},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: condition, Status: apiv1.ConditionTrue}},
+			},
+		}
+	}
+
+	deployment := model.Service{Image: "db"}
+	statefulSet := model.Service{Image: "db", Volumes: []string{"/data"}}
+	job := model.Service{Image: "db", Kind: model.JobServiceKind}
+	tests := []struct {
+		name    string
+		svc     model.Service
+		objects []runtime.Object
+		wantErr bool
+	}{
+		{
+			name:    "no-deployment",
+			svc:     deployment,
+			wantErr: true,
+		},
+		{
+			name: "deployment-old-pod-ready",
+			svc:  deployment,
+			objects: []runtime.Object{
+				newDeployment(2, 2, 1, 2),
+				newStackPod("db-1", "db", apiv1.ConditionTrue),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "deployment-not-observed",
+			svc:     deployment,
+			objects: []runtime.Object{newDeployment(2, 1, 2, 2)},
+			wantErr: true,
+		},
+		{
+			name:    "deployment-rolled-out",
+			svc:     deployment,
+			objects: []runtime.Object{newDeployment(2, 2, 2, 2)},
+		},
+		{
+			name:    "statefulset-updating",
+			svc:     statefulSet,
+			objects: []runtime.Object{newStatefulSet("db-1", "db-2", 1)},
+			wantErr: true,
+		},
+		{
+			name:    "statefulset-not-ready",
+			svc:     statefulSet,
+			objects: []runtime.Object{newStatefulSet("db-2", "db-2", 0)},
+			wantErr: true,
+		},
+		{
+			name:    "statefulset-rolled-out",
+			svc:     statefulSet,
+			objects: []runtime.Object{newStatefulSet("db-2", "db-2", 1)},
+		},
+		{
+			name:    "job-failed",
+			svc:     job,
+			objects: []runtime.Object{newJob(batchv1.JobFailed)},
+			wantErr: true,
+		},
+		{
+			name:    "job-completed",
+			svc:     job,
+			objects: []runtime.Object{newJob(batchv1.JobComplete)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(tt.objects...)
+			s := &model.Stack{Name: "stackName", Namespace: "namespace", Services: map[string]model.Service{"db": tt.svc}}
+			err := waitForServiceToBeHealthy(context.Background(), "db", s, c, 300*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("waitForServiceToBeHealthy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_waitForPodsToBeRunning(t *testing.T) {
+	tests := []struct {
+		name    string
+		pods    []runtime.Object
+		wantErr bool
+	}{
+		{
+			name:    "no-pods",
+			wantErr: true,
+		},
+		{
+			name: "missing-autoscaled-pods",
+			pods: []runtime.Object{
+				newStackPod("db-1", "db", apiv1.ConditionTrue),
+				newStackPod("api-1", "api", apiv1.ConditionTrue),
+			},
+			wantErr: true,
+		},
+		{
+			name: "running",
+			pods: []runtime.Object{
+				newStackPod("db-1", "db", apiv1.ConditionTrue),
+				newStackPod("api-1", "api", apiv1.ConditionTrue),
+				newStackPod("api-2", "api", apiv1.ConditionTrue),
+			},
+		},
+		{
+			name: "autoscaled-above-min",
+			pods: []runtime.Object{
+				newStackPod("db-1", "db", apiv1.ConditionTrue),
+				newStackPod("api-1", "api", apiv1.ConditionTrue),
+				newStackPod("api-2", "api", apiv1.ConditionTrue),
+				newStackPod("api-3", "api", apiv1.ConditionTrue),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset(tt.pods...)
+			s := &model.Stack{
+				Name:      "stackName",
+				Namespace: "namespace",
+				Services: map[string]model.Service{
+					"db": {Replicas: 1},
+					"api": {
+						Replicas: 1,
+						Deploy:   &model.DeployInfo{Autoscaling: &model.AutoscalingInfo{Min: 2, Max: 5}},
+					},
+				},
+			}
+			err := waitForPodsToBeRunning(context.Background(), s, c, 300*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("waitForPodsToBeRunning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newStackPod(name, svcName string, ready apiv1.ConditionStatus) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "namespace",
+			Labels: map[string]string{
+				okLabels.StackNameLabel:        "stackName",
+				okLabels.StackServiceNameLabel: svcName,
+			},
+		},
+		Status: apiv1.PodStatus{
+			Phase:      apiv1.PodRunning,
+			Conditions: []apiv1.PodCondition{{Type: apiv1.PodReady, Status: ready}},
+		},
+	}
+}
+
+func Test_getHealthyDependencies(t *testing.T) {
+	svc := &model.Service{
+		DependsOn: model.DependsOn{
+			"queue": {Condition: model.DependsOnServiceHealthy},
+			"cache": {Condition: model.DependsOnServiceStarted},
+			"db":    {Condition: model.DependsOnServiceHealthy},
+		},
+	}
+	result := getHealthyDependencies(svc)
+	expected := []string{"db", "queue"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("wrong healthy dependencies: %v", result)
+	}
+}
+
+func Test_checkStorageClasses(t *testing.T) {
+	ctx := context.Background()
+	newStack := func(serviceClass, volumeClass string) *model.Stack {
+		return &model.Stack{
+			Name:      "stackName",
+			Namespace: "namespace",
+			Services: map[string]model.Service{
+				"db": {
+					Image:   "postgres",
+					Volumes: []string{"/var/lib/postgresql/data"},
+					Resources: model.StackResources{
+						Requests: model.ServiceResources{
+							Storage: model.StorageResource{Class: serviceClass},
+						},
+					},
+				},
+				"api":   {Image: "api", NamedVolumes: []model.NamedVolumeMount{{Name: "uploads", MountPath: "/uploads"}}},
+				"cache": {Image: "redis"},
+			},
+			Volumes: map[string]model.VolumeSpec{
+				"uploads": {Class: volumeClass},
+				"unused":  {Class: "unused"},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		stack    *model.Stack
+		classes  []string
+		expected []string
+	}{
+		{
+			name:    "existing-classes",
+			stack:   newStack("fast", "standard"),
+			classes: []string{"fast", "standard"},
+		},
+		{
+			name:    "default-classes",
+			stack:   newStack("", ""),
+			classes: []string{},
+		},
+		{
+			name:     "missing-service-class",
+			stack:    newStack("fast", "standard"),
+			classes:  []string{"standard", "premium"},
+			expected: []string{"storage class 'fast' used by service 'db'", "available storage classes are 'premium', 'standard'"},
+		},
+		{
+			name:     "missing-shared-class",
+			stack:    newStack("fast", "fast"),
+			classes:  []string{"standard"},
+			expected: []string{"storage class 'fast' used by service 'db', volume 'uploads'", "available storage classes are 'standard'"},
+		},
+		{
+			name:     "no-classes",
+			stack:    newStack("", "standard"),
+			classes:  []string{},
+			expected: []string{"storage class 'standard' used by volume 'uploads'", "the cluster doesn't have any storage class"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{}
+			for _, class := range tt.classes {
+				objects = append(objects, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: class}})
+			}
+			c := fake.NewSimpleClientset(objects...)
+			err := checkStorageClasses(ctx, tt.stack, c)
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("checkStorageClasses() failed: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("checkStorageClasses() didn't fail for a missing storage class")
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("error doesn't include '%s': %s", expected, err)
+				}
+			}
+			if strings.Contains(err.Error(), "unused") {
+				t.Errorf("error includes the class of an unused volume: %s", err)
+			}
+		})
+	}
+}
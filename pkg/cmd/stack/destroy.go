@@ -24,9 +24,14 @@ import (
 	"github.com/okteto/okteto/cmd/utils"
 	"github.com/okteto/okteto/pkg/k8s/client"
 	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	"github.com/okteto/okteto/pkg/k8s/cronjobs"
+	"github.com/okteto/okteto/pkg/k8s/daemonsets"
 	"github.com/okteto/okteto/pkg/k8s/deployments"
+	"github.com/okteto/okteto/pkg/k8s/hpa"
 	"github.com/okteto/okteto/pkg/k8s/ingress"
+	"github.com/okteto/okteto/pkg/k8s/jobs"
 	okLabels "github.com/okteto/okteto/pkg/k8s/labels"
+	"github.com/okteto/okteto/pkg/k8s/pdb"
 	"github.com/okteto/okteto/pkg/k8s/pods"
 	"github.com/okteto/okteto/pkg/k8s/services"
 	"github.com/okteto/okteto/pkg/k8s/statefulsets"
@@ -35,6 +40,7 @@ import (
 	"github.com/okteto/okteto/pkg/model"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -87,11 +93,9 @@ func destroy(ctx context.Context, s *model.Stack, removeVolumes bool, c *kuberne
 		return err
 	}
 
-	if removeVolumes {
-		spinner.Update("Destroying volumes...")
-		if err := destroyStackVolumes(ctx, spinner, s, c, timeout); err != nil {
-			return err
-		}
+	spinner.Update("Destroying volumes...")
+	if err := destroyStackVolumes(ctx, spinner, s, removeVolumes, c, timeout); err != nil {
+		return err
 	}
 
 	return configmaps.Destroy(ctx, s.GetConfigMapName(), s.Namespace, c)
@@ -143,14 +147,16 @@ func destroyServicesNotInStack(ctx context.Context, spinner *utils.Spinner, s *m
 		return err
 	}
 	for i := range dList {
-		if _, ok := s.Services[dList[i].Name]; ok {
+		if svc, ok := s.Services[dList[i].Name]; ok && !svc.IsJob() && !svc.IsCronJob() && !svc.IsGlobal() {
 			continue
 		}
 		if err := deployments.Destroy(ctx, dList[i].Name, dList[i].Namespace, c); err != nil {
 			return fmt.Errorf("error destroying deployment of service '%s': %s", dList[i].Name, err)
 		}
-		if err := services.Destroy(ctx, dList[i].Name, dList[i].Namespace, c); err != nil {
-			return fmt.Errorf("error destroying service '%s': %s", dList[i].Name, err)
+		if _, ok := s.Services[dList[i].Name]; !ok {
+			if err := services.Destroy(ctx, dList[i].Name, dList[i].Namespace, c); err != nil {
+				return fmt.Errorf("error destroying service '%s': %s", dList[i].Name, err)
+			}
 		}
 		spinner.Stop()
 		log.Success("Destroyed service '%s'", dList[i].Name)
@@ -162,39 +168,166 @@ func destroyServicesNotInStack(ctx context.Context, spinner *utils.Spinner, s *m
 		return err
 	}
 	for i := range sfsList {
-		if _, ok := s.Services[sfsList[i].Name]; ok {
+		if svc, ok := s.Services[sfsList[i].Name]; ok && !svc.IsJob() && !svc.IsCronJob() && !svc.IsGlobal() {
 			continue
 		}
 		if err := statefulsets.Destroy(ctx, sfsList[i].Name, sfsList[i].Namespace, c); err != nil {
 			return fmt.Errorf("error destroying statefulset of service '%s': %s", sfsList[i].Name, err)
 		}
-		if err := services.Destroy(ctx, sfsList[i].Name, sfsList[i].Namespace, c); err != nil {
-			return fmt.Errorf("error destroying service '%s': %s", sfsList[i].Name, err)
+		if _, ok := s.Services[sfsList[i].Name]; !ok {
+			if err := services.Destroy(ctx, sfsList[i].Name, sfsList[i].Namespace, c); err != nil {
+				return fmt.Errorf("error destroying service '%s': %s", sfsList[i].Name, err)
+			}
 		}
 		spinner.Stop()
 		log.Success("Destroyed service '%s'", sfsList[i].Name)
 		spinner.Start()
 	}
 
-	ingressesList, err := ingress.List(ctx, s.Namespace, s.GetLabelSelector(), c)
+	dsList, err := daemonsets.List(ctx, s.Namespace, s.GetLabelSelector(), c)
+	if err != nil {
+		return err
+	}
+	for i := range dsList {
+		if svc, ok := s.Services[dsList[i].Name]; ok && svc.IsGlobal() {
+			continue
+		}
+		if err := daemonsets.Destroy(ctx, dsList[i].Name, dsList[i].Namespace, c); err != nil {
+			return fmt.Errorf("error destroying daemonset of service '%s': %s", dsList[i].Name, err)
+		}
+		if _, ok := s.Services[dsList[i].Name]; !ok {
+			if err := services.Destroy(ctx, dsList[i].Name, dsList[i].Namespace, c); err != nil {
+				return fmt.Errorf("error destroying service '%s': %s", dsList[i].Name, err)
+			}
+		}
+		spinner.Stop()
+		log.Success("Destroyed service '%s'", dsList[i].Name)
+		spinner.Start()
+	}
+
+	jobList, err := jobs.List(ctx, s.Namespace, s.GetLabelSelector(), c)
+	if err != nil {
+		return err
+	}
+	for i := range jobList {
+		if len(jobList[i].OwnerReferences) > 0 {
+			// jobs created by a cronjob are destroyed with it
+			continue
+		}
+		if svc, ok := s.Services[jobList[i].Name]; ok && svc.IsJob() {
+			continue
+		}
+		if err := jobs.Destroy(ctx, jobList[i].Name, jobList[i].Namespace, c); err != nil {
+			return fmt.Errorf("error destroying job of service '%s': %s", jobList[i].Name, err)
+		}
+		spinner.Stop()
+		log.Success("Destroyed job '%s'", jobList[i].Name)
+		spinner.Start()
+	}
+
+	cronJobList, err := cronjobs.List(ctx, s.Namespace, s.GetLabelSelector(), c)
+	if err != nil {
+		return err
+	}
+	for i := range cronJobList {
+		if svc, ok := s.Services[cronJobList[i].Name]; ok && svc.IsCronJob() {
+			continue
+		}
+		if err := cronjobs.Destroy(ctx, cronJobList[i].Name, cronJobList[i].Namespace, c); err != nil {
+			return fmt.Errorf("error destroying cronjob of service '%s': %s", cronJobList[i].Name, err)
+		}
+		spinner.Stop()
+		log.Success("Destroyed cronjob '%s'", cronJobList[i].Name)
+		spinner.Start()
+	}
+
+	hpaList, err := hpa.List(ctx, s.Namespace, s.GetLabelSelector(), c)
+	if err != nil {
+		return err
+	}
+	for i := range hpaList {
+		if svc, ok := s.Services[hpaList[i].Name]; ok && svc.Deploy != nil && svc.Deploy.Autoscaling != nil {
+			continue
+		}
+		if err := hpa.Destroy(ctx, hpaList[i].Name, hpaList[i].Namespace, c); err != nil {
+			return fmt.Errorf("error destroying horizontal pod autoscaler of service '%s': %s", hpaList[i].Name, err)
+		}
+	}
+
+	pdbList, err := pdb.List(ctx, s.Namespace, s.GetLabelSelector(), c)
+	if err != nil {
+		return err
+	}
+	for i := range pdbList {
+		if svc, ok := s.Services[pdbList[i].Name]; ok && svc.Deploy != nil && svc.Deploy.PDB != nil && !svc.IsJob() && !svc.IsCronJob() {
+			continue
+		}
+		if err := pdb.Destroy(ctx, pdbList[i].Name, pdbList[i].Namespace, c); err != nil {
+			return fmt.Errorf("error destroying pod disruption budget of service '%s': %s", pdbList[i].Name, err)
+		}
+	}
+
+	svcList, err := services.List(ctx, s.Namespace, s.GetLabelSelector(), c)
 	if err != nil {
 		return err
 	}
-	for i := range ingressesList {
-		if _, ok := s.Endpoints[ingressesList[i].Name]; ok {
+	for i := range svcList {
+		if svc, ok := s.Services[svcList[i].Name]; ok && len(svc.GetPorts()) > 0 {
 			continue
 		}
-		if err := ingress.Destroy(ctx, ingressesList[i].Name, ingressesList[i].Namespace, c); err != nil {
-			return fmt.Errorf("error destroying ingress '%s': %s", ingressesList[i].Name, err)
+		if err := services.Destroy(ctx, svcList[i].Name, svcList[i].Namespace, c); err != nil {
+			return fmt.Errorf("error destroying service '%s': %s", svcList[i].Name, err)
+		}
+	}
+
+	isV1 := ingress.IsV1Enabled(ctx, c)
+	ingressNames, err := listIngressNames(ctx, s, isV1, c)
+	if err != nil {
+		return err
+	}
+	for _, name := range ingressNames {
+		if _, ok := s.Endpoints[name]; ok {
+			continue
+		}
+		if isV1 {
+			err = ingress.DestroyV1(ctx, name, s.Namespace, c)
+		} else {
+			err = ingress.Destroy(ctx, name, s.Namespace, c)
+		}
+		if err != nil {
+			return fmt.Errorf("error destroying ingress '%s': %s", name, err)
 		}
 		spinner.Stop()
-		log.Success("Destroyed endpoint '%s'", ingressesList[i].Name)
+		log.Success("Destroyed endpoint '%s'", name)
 		spinner.Start()
 	}
 
 	return nil
 }
 
+//listIngressNames returns the names of the ingresses of the stack, using the networking.k8s.io/v1 api when the cluster serves it
+func listIngressNames(ctx context.Context, s *model.Stack, isV1 bool, c kubernetes.Interface) ([]string, error) {
+	result := []string{}
+	if isV1 {
+		iList, err := ingress.ListV1(ctx, s.Namespace, s.GetLabelSelector(), c)
+		if err != nil {
+			return nil, err
+		}
+		for i := range iList {
+			result = append(result, iList[i].Name)
+		}
+		return result, nil
+	}
+	iList, err := ingress.List(ctx, s.Namespace, s.GetLabelSelector(), c)
+	if err != nil {
+		return nil, err
+	}
+	for i := range iList {
+		result = append(result, iList[i].Name)
+	}
+	return result, nil
+}
+
 func waitForPodsToBeDestroyed(ctx context.Context, s *model.Stack, c *kubernetes.Clientset) error {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	timeout := time.Now().Add(300 * time.Second)
@@ -213,20 +346,40 @@ func waitForPodsToBeDestroyed(ctx context.Context, s *model.Stack, c *kubernetes
 	return fmt.Errorf("kubernetes is taking too long to destroy your stack. Please check for errors and try again")
 }
 
-func destroyStackVolumes(ctx context.Context, spinner *utils.Spinner, s *model.Stack, c *kubernetes.Clientset, timeout time.Duration) error {
+func destroyStackVolumes(ctx context.Context, spinner *utils.Spinner, s *model.Stack, removeVolumes bool, c *kubernetes.Clientset, timeout time.Duration) error {
 	vList, err := volumes.List(ctx, s.Namespace, s.GetLabelSelector(), c)
 	if err != nil {
 		return err
 	}
-	for _, v := range vList {
-		if v.Labels[okLabels.StackNameLabel] == s.Name {
-			if err := volumes.Destroy(ctx, v.Name, v.Namespace, c, timeout); err != nil {
-				return fmt.Errorf("error destroying volume '%s': %s", v.Name, err)
-			}
-			spinner.Stop()
-			log.Success("Destroyed volume '%s'", v.Name)
-			spinner.Start()
+	for i := range vList {
+		v := &vList[i]
+		if v.Labels[okLabels.StackNameLabel] != s.Name || !shouldDestroyVolume(v, s, removeVolumes) {
+			continue
+		}
+		if err := volumes.Destroy(ctx, v.Name, v.Namespace, c, timeout); err != nil {
+			return fmt.Errorf("error destroying volume '%s': %s", v.Name, err)
 		}
+		spinner.Stop()
+		log.Success("Destroyed volume '%s'", v.Name)
+		spinner.Start()
 	}
 	return nil
 }
+
+//shouldDestroyVolume returns true if a volume of the stack is destroyed on stack destroy.
+//Removing volumes destroys all of them. Otherwise, only the named volumes with 'persist: false' are destroyed:
+//service volumes and named volumes no longer defined in the stack are kept
+func shouldDestroyVolume(v *apiv1.PersistentVolumeClaim, s *model.Stack, removeVolumes bool) bool {
+	if removeVolumes {
+		return true
+	}
+	name, ok := v.Labels[okLabels.StackVolumeNameLabel]
+	if !ok {
+		return false
+	}
+	volume, ok := s.Volumes[name]
+	if !ok {
+		return false
+	}
+	return !volume.IsPersistent()
+}
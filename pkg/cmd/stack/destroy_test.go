@@ -0,0 +1,75 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"testing"
+
+	okLabels "github.com/okteto/okteto/pkg/k8s/labels"
+	"github.com/okteto/okteto/pkg/model"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_shouldDestroyVolume(t *testing.T) {
+	persist := true
+	discard := false
+	s := &model.Stack{
+		Name: "stackName",
+		Volumes: map[string]model.VolumeSpec{
+			"default":   {},
+			"persisted": {Persist: &persist},
+			"discarded": {Persist: &discard},
+		},
+	}
+	namedVolume := func(name string) *apiv1.PersistentVolumeClaim {
+		return &apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					okLabels.StackNameLabel:       "stackName",
+					okLabels.StackVolumeNameLabel: name,
+				},
+			},
+		}
+	}
+	serviceVolume := &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "data-db-0",
+			Labels: map[string]string{okLabels.StackNameLabel: "stackName"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		volume        *apiv1.PersistentVolumeClaim
+		removeVolumes bool
+		expected      bool
+	}{
+		{name: "named-default", volume: namedVolume("default"), expected: false},
+		{name: "named-persist", volume: namedVolume("persisted"), expected: false},
+		{name: "named-no-persist", volume: namedVolume("discarded"), expected: true},
+		{name: "named-not-in-stack", volume: namedVolume("removed"), expected: false},
+		{name: "service-volume", volume: serviceVolume, expected: false},
+		{name: "named-persist-remove-volumes", volume: namedVolume("persisted"), removeVolumes: true, expected: true},
+		{name: "service-volume-remove-volumes", volume: serviceVolume, removeVolumes: true, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := shouldDestroyVolume(tt.volume, s, tt.removeVolumes); result != tt.expected {
+				t.Errorf("shouldDestroyVolume() = %t, expected %t", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/okteto/okteto/pkg/cmd/build"
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/client"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/pmezard/go-difflib/difflib"
+	yaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	redactedValue = "<redacted>"
+
+	redactedOldValue = "<redacted: old value>"
+
+	redactedNewValue = "<redacted: new value>"
+)
+
+//ObjectDiff represents the changes of a kubernetes object applied by a stack deployment
+type ObjectDiff struct {
+	Object ApplyObject
+	Patch  string
+}
+
+//Diff prints the unified patches between the live and the desired manifests of the objects of a stack, without building or deploying it
+func Diff(ctx context.Context, s *model.Stack, servicesToDeploy []string) error {
+	if s.Namespace == "" {
+		s.Namespace = client.GetContextNamespace("")
+	}
+	if len(servicesToDeploy) > 0 {
+		var err error
+		s, err = s.Filter(servicesToDeploy)
+		if err != nil {
+			return err
+		}
+	}
+
+	c, _, err := client.GetLocal()
+	if err != nil {
+		return err
+	}
+	s = s.DeepCopy()
+	if err := translateStackEnvVars(s); err != nil {
+		return err
+	}
+	translatePlatformOverrides(ctx, s, c)
+	translateProvider(ctx, s, c)
+	translateIngressAPI(ctx, s, c)
+	if err := translateBuiltImageNames(s); err != nil {
+		return err
+	}
+
+	diffs, err := getDiff(ctx, s, c)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Printf("Stack '%s' is up to date\n", s.Name)
+		return nil
+	}
+	for _, d := range diffs {
+		fmt.Print(d.Patch)
+	}
+	return nil
+}
+
+//translateBuiltImageNames sets the image names of the services built by okteto, without building them
+func translateBuiltImageNames(s *model.Stack) error {
+	_, isOktetoCluster, err := build.GetBuildKitHost()
+	if err != nil {
+		return err
+	}
+	for name, svc := range s.Services {
+		if svc.Build == nil {
+			continue
+		}
+		svc.Image, err = translateBuildImage(s.Name, name, &svc, isOktetoCluster)
+		if err != nil {
+			return err
+		}
+		s.Services[name] = svc
+	}
+	return nil
+}
+
+//getDiff returns the unified patches of the objects of a stack whose live manifest differs from the translated one
+func getDiff(ctx context.Context, s *model.Stack, c kubernetes.Interface) ([]ObjectDiff, error) {
+	result := []ObjectDiff{}
+	for _, obj := range GetApplyOrder(s) {
+		live, desired, err := getDiffObjects(ctx, obj, s, c)
+		if err != nil {
+			return nil, err
+		}
+		if desired == nil {
+			continue
+		}
+		patch, err := getObjectPatch(obj, live, desired)
+		if err != nil {
+			return nil, err
+		}
+		if patch != "" {
+			result = append(result, ObjectDiff{Object: obj, Patch: patch})
+		}
+	}
+	return result, nil
+}
+
+//getDiffObjects returns the live and the desired manifests of an object, or nil for the objects not compared, like the stack configmap
+func getDiffObjects(ctx context.Context, obj ApplyObject, s *model.Stack, c kubernetes.Interface) (interface{}, interface{}, error) {
+	if obj.Kind == configMapKind {
+		// the stack configmap stores the status of the deployments
+		return nil, nil, nil
+	}
+	desired := translateObject(obj, s)
+	if desired == nil {
+		return nil, nil, nil
+	}
+	var live interface{}
+	var err error
+	switch obj.Kind {
+	case pvcKind:
+		live, err = c.CoreV1().PersistentVolumeClaims(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case serviceKind:
+		live, err = c.CoreV1().Services(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case deploymentKind:
+		live, err = c.AppsV1().Deployments(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case statefulSetKind:
+		live, err = c.AppsV1().StatefulSets(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case daemonSetKind:
+		live, err = c.AppsV1().DaemonSets(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case jobKind:
+		live, err = c.BatchV1().Jobs(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case cronJobKind:
+		live, err = c.BatchV1beta1().CronJobs(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case hpaKind:
+		live, err = c.AutoscalingV2beta2().HorizontalPodAutoscalers(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case pdbKind:
+		live, err = c.PolicyV1beta1().PodDisruptionBudgets(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+	case ingressKind:
+		if s.IngressV1 {
+			live, err = c.NetworkingV1().Ingresses(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+		} else {
+			live, err = c.ExtensionsV1beta1().Ingresses(obj.Namespace).Get(ctx, obj.Name, metav1.GetOptions{})
+		}
+	default:
+		return nil, nil, nil
+	}
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("error getting %s '%s': %s", strings.ToLower(obj.Kind), obj.Name, err.Error())
+		}
+		live = nil
+	}
+	return live, desired, nil
+}
+
+//getObjectPatch returns the unified patch between the live and the desired manifests of an object, or an empty string if they are equal.
+//Only the fields set by okteto are compared, so defaults and status added by kubernetes don't show up as changes
+func getObjectPatch(obj ApplyObject, live, desired interface{}) (string, error) {
+	desiredMap, err := toDiffMap(desired)
+	if err != nil {
+		return "", err
+	}
+	delete(desiredMap, "status")
+
+	var liveMap map[string]interface{}
+	if live != nil {
+		liveMap, err = toDiffMap(live)
+		if err != nil {
+			return "", err
+		}
+		liveMap, _ = pruneDiffObject(liveMap, desiredMap).(map[string]interface{})
+	}
+
+	desiredEnv := getDiffEnv(desiredMap)
+	liveEnv := getDiffEnv(liveMap)
+	redactDiffEnv(desiredMap, liveEnv, redactedNewValue)
+	desiredYAML, err := toDiffYAML(desiredMap)
+	if err != nil {
+		return "", err
+	}
+
+	liveLines := []string{}
+	if liveMap != nil {
+		redactDiffEnv(liveMap, desiredEnv, redactedOldValue)
+		liveYAML, err := toDiffYAML(liveMap)
+		if err != nil {
+			return "", err
+		}
+		liveLines = difflib.SplitLines(liveYAML)
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        liveLines,
+		B:        difflib.SplitLines(desiredYAML),
+		FromFile: fmt.Sprintf("live/%s", obj.String()),
+		ToFile:   fmt.Sprintf("desired/%s", obj.String()),
+		Context:  3,
+	})
+}
+
+//toDiffMap returns the generic representation of an object, without null values
+func toDiffMap(obj interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	removeDiffNulls(result)
+	return result, nil
+}
+
+func removeDiffNulls(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			if v[k] == nil {
+				delete(v, k)
+				continue
+			}
+			removeDiffNulls(v[k])
+		}
+	case []interface{}:
+		for i := range v {
+			removeDiffNulls(v[i])
+		}
+	}
+}
+
+//pruneDiffObject removes the fields of the live manifest that are not set in the desired manifest
+func pruneDiffObject(live, desired interface{}) interface{} {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		result := map[string]interface{}{}
+		for k := range d {
+			if v, ok := l[k]; ok {
+				result[k] = pruneDiffObject(v, d[k])
+			}
+		}
+		return result
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		result := make([]interface{}, len(l))
+		for i := range l {
+			if i < len(d) {
+				result[i] = pruneDiffObject(l[i], d[i])
+			} else {
+				result[i] = l[i]
+			}
+		}
+		return result
+	}
+	return live
+}
+
+func toDiffYAML(obj map[string]interface{}) (string, error) {
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+//getDiffEnv returns the values of the environment variables of the containers of a manifest, indexed by container and variable name
+func getDiffEnv(obj interface{}) map[string]string {
+	result := map[string]string{}
+	walkDiffEnv(obj, func(container string, e map[string]interface{}) {
+		if value, ok := e["value"].(string); ok {
+			result[fmt.Sprintf("%s/%v", container, e["name"])] = value
+		}
+	})
+	return result
+}
+
+//redactDiffEnv hides the values of the environment variables of the containers of a manifest, keeping their names.
+//The values that differ from the ones of the other manifest are replaced by 'changed', so the patch still shows the change
+func redactDiffEnv(obj interface{}, other map[string]string, changed string) {
+	walkDiffEnv(obj, func(container string, e map[string]interface{}) {
+		value, ok := e["value"].(string)
+		if !ok {
+			return
+		}
+		e["value"] = redactedValue
+		if otherValue, ok := other[fmt.Sprintf("%s/%v", container, e["name"])]; ok && otherValue != value {
+			e["value"] = changed
+		}
+	})
+}
+
+//walkDiffEnv calls f with every environment variable of the containers of a manifest
+func walkDiffEnv(value interface{}, f func(container string, e map[string]interface{})) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if env, ok := v["env"].([]interface{}); ok {
+			container, _ := v["name"].(string)
+			for _, item := range env {
+				if e, ok := item.(map[string]interface{}); ok {
+					f(container, e)
+				}
+			}
+		}
+		for _, child := range v {
+			walkDiffEnv(child, f)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walkDiffEnv(child, f)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newDiffStack(image string, replicas int32) *model.Stack {
+	return &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"api": {Image: image, Replicas: replicas},
+		},
+	}
+}
+
+func Test_getDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		desired  *model.Stack
+		expected []string
+	}{
+		{
+			name:    "unchanged",
+			desired: newDiffStack("okteto/api:1", 1),
+		},
+		{
+			name:    "replicas",
+			desired: newDiffStack("okteto/api:1", 3),
+			expected: []string{
+				`(?m)^--- live/Deployment/namespace/api$`,
+				`(?m)^\+\+\+ desired/Deployment/namespace/api$`,
+				`(?m)^-  replicas: 1$`,
+				`(?m)^\+  replicas: 3$`,
+			},
+		},
+		{
+			name:    "image",
+			desired: newDiffStack("okteto/api:2", 1),
+			expected: []string{
+				`(?m)^--- live/Deployment/namespace/api$`,
+				`(?m)^-\s+(- )?image: okteto/api:1$`,
+				`(?m)^\+\s+(- )?image: okteto/api:2$`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := translateDeployment("api", newDiffStack("okteto/api:1", 1))
+			live.Status.Replicas = 1
+			live.ResourceVersion = "42"
+			c := fake.NewSimpleClientset(live)
+
+			diffs, err := getDiff(context.Background(), tt.desired, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.expected) == 0 {
+				if len(diffs) > 0 {
+					t.Fatalf("unexpected diff:\n%s", diffs[0].Patch)
+				}
+				return
+			}
+			if len(diffs) != 1 {
+				t.Fatalf("expected 1 diff, got %d", len(diffs))
+			}
+			for _, e := range tt.expected {
+				if !regexp.MustCompile(e).MatchString(diffs[0].Patch) {
+					t.Errorf("diff doesn't match '%s':\n%s", e, diffs[0].Patch)
+				}
+			}
+			if strings.Contains(diffs[0].Patch, "resourceVersion") || strings.Contains(diffs[0].Patch, "status") {
+				t.Errorf("diff includes fields not managed by okteto:\n%s", diffs[0].Patch)
+			}
+		})
+	}
+}
+
+func Test_getDiffNewObject(t *testing.T) {
+	c := fake.NewSimpleClientset()
+	diffs, err := getDiff(context.Background(), newDiffStack("okteto/api:1", 1), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].Object.Kind != deploymentKind {
+		t.Fatalf("expected a deployment diff, got %+v", diffs)
+	}
+	if !regexp.MustCompile(`(?m)^\+  replicas: 1$`).MatchString(diffs[0].Patch) {
+		t.Errorf("new deployment not shown as added:\n%s", diffs[0].Patch)
+	}
+}
+
+func Test_getObjectPatchRedactsEnv(t *testing.T) {
+	obj := ApplyObject{Kind: deploymentKind, Namespace: "namespace", Name: "api"}
+	liveStack := newDiffStack("okteto/api:1", 1)
+	liveStack.Services["api"] = model.Service{
+		Image:       "okteto/api:1",
+		Replicas:    1,
+		Environment: model.Environment{{Name: "PASSWORD", Value: "old-password"}, {Name: "USER", Value: "admin"}},
+	}
+	desiredStack := newDiffStack("okteto/api:1", 1)
+	desiredStack.Services["api"] = model.Service{
+		Image:       "okteto/api:1",
+		Replicas:    1,
+		Environment: model.Environment{{Name: "PASSWORD", Value: "new-password"}, {Name: "TOKEN", Value: "abc123"}, {Name: "USER", Value: "admin"}},
+	}
+	patch, err := getObjectPatch(obj, translateDeployment("api", liveStack), translateDeployment("api", desiredStack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"old-password", "new-password", "admin", "abc123"} {
+		if strings.Contains(patch, value) {
+			t.Errorf("env value '%s' not redacted:\n%s", value, patch)
+		}
+	}
+	expected := []string{
+		`(?m)^-\s+value: <redacted: old value>$`,
+		`(?m)^\+\s+value: <redacted: new value>$`,
+		`(?m)^\+\s+(- )?name: TOKEN$`,
+	}
+	for _, e := range expected {
+		if !regexp.MustCompile(e).MatchString(patch) {
+			t.Errorf("patch doesn't match '%s':\n%s", e, patch)
+		}
+	}
+
+	patch, err = getObjectPatch(obj, translateDeployment("api", liveStack), translateDeployment("api", liveStack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch != "" {
+		t.Errorf("unexpected patch for unchanged env:\n%s", patch)
+	}
+}
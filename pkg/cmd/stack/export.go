@@ -0,0 +1,126 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/okteto/okteto/pkg/model"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	kustomizationFile = "kustomization.yaml"
+
+	kustomizationAPIVersion = "kustomize.config.k8s.io/v1beta1"
+	kustomizationKind       = "Kustomization"
+)
+
+var exportAPIVersions = map[string]string{
+	configMapKind:   "v1",
+	pvcKind:         "v1",
+	serviceKind:     "v1",
+	deploymentKind:  "apps/v1",
+	statefulSetKind: "apps/v1",
+	daemonSetKind:   "apps/v1",
+	jobKind:         "batch/v1",
+	cronJobKind:     "batch/v1beta1",
+	hpaKind:         "autoscaling/v2beta2",
+	pdbKind:         "policy/v1beta1",
+	ingressKind:     "networking.k8s.io/v1",
+}
+
+//Kustomization represents the kustomization file listing the manifests exported from a stack
+type Kustomization struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Namespace  string   `yaml:"namespace,omitempty"`
+	Resources  []string `yaml:"resources"`
+}
+
+//Export writes the translated objects of a stack into a directory, one manifest per object, and a kustomization listing them in apply order.
+//It doesn't access the cluster, so platform overrides and load balancer options depending on the cluster provider are not applied,
+//and endpoints are exported as networking.k8s.io/v1 ingresses
+func Export(s *model.Stack, dir string) error {
+	s = s.DeepCopy()
+	s.IngressV1 = true
+	if err := translateStackEnvVars(s); err != nil {
+		return err
+	}
+	if err := translateBuiltImageNames(s); err != nil {
+		return err
+	}
+	return exportObjects(s, dir)
+}
+
+func exportObjects(s *model.Stack, dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating directory '%s': %s", dir, err)
+	}
+
+	kustomization := Kustomization{
+		APIVersion: kustomizationAPIVersion,
+		Kind:       kustomizationKind,
+		Namespace:  s.Namespace,
+		Resources:  []string{},
+	}
+	for _, obj := range GetApplyOrder(s) {
+		desired := translateObject(obj, s)
+		if desired == nil {
+			continue
+		}
+		manifest, err := toExportYAML(getExportAPIVersion(obj.Kind, s), obj.Kind, desired)
+		if err != nil {
+			return fmt.Errorf("error exporting %s '%s': %s", strings.ToLower(obj.Kind), obj.Name, err)
+		}
+		fileName := fmt.Sprintf("%s-%s.yaml", strings.ToLower(obj.Kind), obj.Name)
+		if err := ioutil.WriteFile(filepath.Join(dir, fileName), manifest, 0600); err != nil {
+			return fmt.Errorf("error writing '%s': %s", fileName, err)
+		}
+		kustomization.Resources = append(kustomization.Resources, fileName)
+	}
+
+	b, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, kustomizationFile), b, 0600); err != nil {
+		return fmt.Errorf("error writing '%s': %s", kustomizationFile, err)
+	}
+	return nil
+}
+
+//getExportAPIVersion returns the api version of the objects of a kind, matching the types returned by translateObject
+func getExportAPIVersion(kind string, s *model.Stack) string {
+	if kind == ingressKind && !s.IngressV1 {
+		return "extensions/v1beta1"
+	}
+	return exportAPIVersions[kind]
+}
+
+//toExportYAML returns the manifest of an object with its type meta, which is not set by the translate functions
+func toExportYAML(apiVersion, kind string, obj interface{}) ([]byte, error) {
+	m, err := toDiffMap(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(m, "status")
+	m["apiVersion"] = apiVersion
+	m["kind"] = kind
+	return yaml.Marshal(m)
+}
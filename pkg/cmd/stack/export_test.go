@@ -0,0 +1,112 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func Test_exportObjects(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"api": {Image: "okteto/api", Replicas: 1, Ports: []model.Port{{Port: 8080, ContainerPort: 8080}}},
+			"db":  {Image: "postgres", Replicas: 1, Volumes: []string{"/data"}},
+		},
+		Endpoints: map[string][]model.Endpoint{
+			"api": {{Path: "/", Service: "api", Port: 8080}},
+		},
+		IngressV1: true,
+	}
+	if err := exportObjects(s, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, kustomizationFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kustomization Kustomization
+	if err := yaml.Unmarshal(b, &kustomization); err != nil {
+		t.Fatal(err)
+	}
+	resources := []string{
+		"configmap-okteto-stackName.yaml",
+		"service-api.yaml",
+		"deployment-api.yaml",
+		"statefulset-db.yaml",
+		"ingress-api.yaml",
+	}
+	if kustomization.Namespace != "namespace" {
+		t.Errorf("wrong kustomization namespace: %s", kustomization.Namespace)
+	}
+	if !reflect.DeepEqual(kustomization.Resources, resources) {
+		t.Errorf("wrong kustomization resources: %v", kustomization.Resources)
+	}
+
+	for _, fileName := range resources {
+		b, err := ioutil.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			t.Fatalf("missing manifest '%s': %s", fileName, err)
+		}
+		manifest := map[string]interface{}{}
+		if err := yaml.Unmarshal(b, &manifest); err != nil {
+			t.Fatal(err)
+		}
+		if manifest["apiVersion"] == "" || manifest["kind"] == "" {
+			t.Errorf("manifest '%s' without type meta", fileName)
+		}
+		if _, ok := manifest["status"]; ok {
+			t.Errorf("manifest '%s' with status", fileName)
+		}
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "deployment-api.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest["apiVersion"] != "apps/v1" || manifest["kind"] != "Deployment" {
+		t.Errorf("wrong type meta: %v %v", manifest["apiVersion"], manifest["kind"])
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "ingress-api.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest = map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest["apiVersion"] != "networking.k8s.io/v1" || manifest["kind"] != "Ingress" {
+		t.Errorf("wrong ingress type meta: %v %v", manifest["apiVersion"], manifest["kind"])
+	}
+}
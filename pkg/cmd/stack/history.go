@@ -0,0 +1,131 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/client"
+	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	"github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	historyField = "history"
+
+	maxHistoryRevisions = 10
+
+	//maxHistorySize caps the size of the history, so the stack configmap stays under the 1MiB limit of kubernetes
+	maxHistorySize = 512 * 1024
+)
+
+//Revision represents a past deployment of a stack
+type Revision struct {
+	Revision     int                  `json:"revision"`
+	Timestamp    time.Time            `json:"timestamp"`
+	ManifestHash string               `json:"manifestHash"`
+	User         string               `json:"user,omitempty"`
+	Manifest     string               `json:"manifest"`
+	Dir          string               `json:"dir,omitempty"`
+	Overrides    model.StackOverrides `json:"overrides"`
+}
+
+//GetManifest returns the stack manifest deployed by the revision
+func (r Revision) GetManifest() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(r.Manifest)
+}
+
+//GetHistory returns the deployment history of a stack, from the oldest to the newest revision
+func GetHistory(ctx context.Context, s *model.Stack) ([]Revision, error) {
+	if s.Namespace == "" {
+		s.Namespace = client.GetContextNamespace("")
+	}
+
+	c, _, err := client.GetLocal()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := configmaps.Get(ctx, s.GetConfigMapName(), s.Namespace, c)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("stack '%s' has not been deployed in namespace '%s'", s.Name, s.Namespace)
+		}
+		return nil, err
+	}
+	return getHistory(cfg)
+}
+
+//getHistory returns the revisions stored in a stack configmap, tolerating configmaps without history
+func getHistory(cfg *apiv1.ConfigMap) ([]Revision, error) {
+	result := []Revision{}
+	if cfg == nil || cfg.Data[historyField] == "" {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(cfg.Data[historyField]), &result); err != nil {
+		return nil, fmt.Errorf("failed to read the history of stack '%s': %s", cfg.Data[nameField], err)
+	}
+	return result, nil
+}
+
+//translateHistory appends a revision for the current deployment to the history stored in the old configmap,
+//keeping the last 'maxHistoryRevisions' that fit in 'maxHistorySize' bytes
+func translateHistory(cfg, old *apiv1.ConfigMap, s *model.Stack, user string, now time.Time) error {
+	history, err := getHistory(old)
+	if err != nil {
+		return err
+	}
+
+	revision := 1
+	if len(history) > 0 {
+		revision = history[len(history)-1].Revision + 1
+	}
+	hash := sha256.Sum256(s.Manifest)
+	history = append(history, Revision{
+		Revision:     revision,
+		Timestamp:    now.UTC(),
+		ManifestHash: hex.EncodeToString(hash[:]),
+		User:         user,
+		Manifest:     base64.StdEncoding.EncodeToString(s.Manifest),
+		Dir:          s.Dir,
+		Overrides:    s.Overrides,
+	})
+	if len(history) > maxHistoryRevisions {
+		history = history[len(history)-maxHistoryRevisions:]
+	}
+
+	for {
+		b, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+		if len(b) <= maxHistorySize {
+			cfg.Data[historyField] = string(b)
+			return nil
+		}
+		if len(history) == 1 {
+			log.Warning("The manifest of stack '%s' is too big to be kept in its deployment history: you won't be able to roll back to revision %d", s.Name, revision)
+		}
+		history = history[1:]
+	}
+}
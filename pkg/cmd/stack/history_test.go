@@ -0,0 +1,151 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/okteto/okteto/pkg/model"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func Test_translateHistory(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &model.Stack{Name: "stackName", Manifest: []byte("name: stackName")}
+
+	oldWithoutHistory := &apiv1.ConfigMap{Data: map[string]string{nameField: "stackName"}}
+	for _, old := range []*apiv1.ConfigMap{nil, oldWithoutHistory} {
+		cfg := translateConfigMap(s)
+		if err := translateHistory(cfg, old, s, "cindy", now); err != nil {
+			t.Fatal(err)
+		}
+		history, err := getHistory(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(history) != 1 {
+			t.Fatalf("wrong history: %+v", history)
+		}
+		r := history[0]
+		if r.Revision != 1 || r.User != "cindy" || !r.Timestamp.Equal(now) || len(r.ManifestHash) != 64 {
+			t.Errorf("wrong revision: %+v", r)
+		}
+		manifest, err := r.GetManifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(manifest) != "name: stackName" {
+			t.Errorf("wrong revision manifest: %s", manifest)
+		}
+	}
+
+	var old *apiv1.ConfigMap
+	for i := 1; i <= maxHistoryRevisions+5; i++ {
+		s.Manifest = []byte(fmt.Sprintf("name: stackName-%d", i))
+		cfg := translateConfigMap(s)
+		if err := translateHistory(cfg, old, s, "cindy", now.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		old = cfg
+	}
+	history, err := getHistory(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != maxHistoryRevisions {
+		t.Fatalf("history was not capped: %d revisions", len(history))
+	}
+	if history[0].Revision != 6 || history[len(history)-1].Revision != maxHistoryRevisions+5 {
+		t.Errorf("wrong revisions kept: %d-%d", history[0].Revision, history[len(history)-1].Revision)
+	}
+	manifest, _ := history[len(history)-1].GetManifest()
+	if string(manifest) != fmt.Sprintf("name: stackName-%d", maxHistoryRevisions+5) {
+		t.Errorf("wrong last manifest: %s", manifest)
+	}
+	if history[0].ManifestHash == history[1].ManifestHash {
+		t.Errorf("different manifests have the same hash")
+	}
+}
+
+func Test_translateHistorySize(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &model.Stack{Name: "stackName"}
+
+	var old *apiv1.ConfigMap
+	for i := 1; i <= 5; i++ {
+		s.Manifest = []byte(fmt.Sprintf("name: stackName-%d\n#%s", i, strings.Repeat("a", 100*1024)))
+		cfg := translateConfigMap(s)
+		if err := translateHistory(cfg, old, s, "cindy", now); err != nil {
+			t.Fatal(err)
+		}
+		if len(cfg.Data[historyField]) > maxHistorySize {
+			t.Fatalf("history was not capped: %d bytes", len(cfg.Data[historyField]))
+		}
+		old = cfg
+	}
+	history, err := getHistory(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[0].Revision != 3 || history[2].Revision != 5 {
+		t.Fatalf("wrong revisions kept: %d revisions", len(history))
+	}
+
+	s.Manifest = []byte(strings.Repeat("a", maxHistorySize))
+	cfg := translateConfigMap(s)
+	if err := translateHistory(cfg, old, s, "cindy", now); err != nil {
+		t.Fatal(err)
+	}
+	history, err = getHistory(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Errorf("a revision bigger than the history size was kept: %d revisions", len(history))
+	}
+}
+
+func Test_translateHistoryOverrides(t *testing.T) {
+	s := &model.Stack{
+		Name:     "stackName",
+		Manifest: []byte("name: stackName"),
+		Overrides: model.StackOverrides{
+			Set:      []string{"services.api.replicas=3"},
+			Commands: []string{"api=sleep infinity"},
+			Images:   map[string]string{"api": "okteto/api@sha256:123"},
+		},
+	}
+	cfg := translateConfigMap(s)
+	if err := translateHistory(cfg, nil, s, "cindy", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	history, err := getHistory(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(history[0].Overrides, s.Overrides) {
+		t.Errorf("wrong revision overrides: %+v", history[0].Overrides)
+	}
+}
+
+func Test_getHistoryMalformed(t *testing.T) {
+	cfg := &apiv1.ConfigMap{Data: map[string]string{nameField: "stackName", historyField: "{"}}
+	if _, err := getHistory(cfg); err == nil {
+		t.Errorf("getHistory() didn't fail for a malformed history")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/okteto/okteto/pkg/cmd/build"
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/client"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/registry"
+)
+
+type imageDigestGetter func(ctx context.Context, namespace, image string) (string, error)
+
+//CheckImages checks that the images of every service exist, including the images previously built by okteto, without building them
+func CheckImages(ctx context.Context, s *model.Stack) error {
+	if s.Namespace == "" {
+		s.Namespace = client.GetContextNamespace("")
+	}
+	if err := translateStackEnvVars(s); err != nil {
+		return err
+	}
+	_, isOktetoCluster, err := build.GetBuildKitHost()
+	if err != nil {
+		return err
+	}
+	return checkImages(ctx, s, isOktetoCluster, registry.GetImageTagWithDigest)
+}
+
+func checkImages(ctx context.Context, s *model.Stack, isOktetoCluster bool, getDigest imageDigestGetter) error {
+	missing := []string{}
+	for _, name := range getSortedServiceNames(s) {
+		svc := s.Services[name]
+		image := svc.Image
+		if svc.Build != nil {
+			var err error
+			image, err = translateBuildImage(s.Name, name, &svc, isOktetoCluster)
+			if err != nil {
+				return err
+			}
+			if image == "" {
+				missing = append(missing, fmt.Sprintf("service '%s': 'build' and 'image' fields cannot be empty", name))
+				continue
+			}
+		}
+		if _, err := getDigest(ctx, s.Namespace, image); err != nil {
+			if err == errors.ErrNotFound {
+				missing = append(missing, fmt.Sprintf("service '%s': image '%s' not found", name, image))
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("service '%s': %s", name, err.Error()))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("some images of stack '%s' are not available:\n    - %s", s.Name, strings.Join(missing, "\n    - "))
+	}
+	return nil
+}
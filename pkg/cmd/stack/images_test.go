@@ -0,0 +1,125 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/model"
+)
+
+func Test_checkImages(t *testing.T) {
+	ctx := context.Background()
+	available := map[string]bool{
+		"okteto/api:1.0":                   true,
+		"okteto.dev/stackName-vote:okteto": true,
+	}
+	checked := []string{}
+	getDigest := func(ctx context.Context, namespace, image string) (string, error) {
+		checked = append(checked, image)
+		if image == "okteto/broken:1.0" {
+			return "", fmt.Errorf("error getting image tag digest: unauthorized")
+		}
+		if !available[image] {
+			return "", errors.ErrNotFound
+		}
+		return fmt.Sprintf("%s@sha256:123", image), nil
+	}
+
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"api":    {Image: "okteto/api:1.0"},
+			"db":     {Image: "okteto/db:1.0"},
+			"broken": {Image: "okteto/broken:1.0"},
+			"vote":   {Build: &model.BuildInfo{Context: "vote"}},
+			"result": {Image: "okteto.dev/result:okteto", Build: &model.BuildInfo{Context: "result"}},
+		},
+	}
+
+	err := checkImages(ctx, s, true, getDigest)
+	if err == nil {
+		t.Fatal("checkImages() didn't fail for missing images")
+	}
+	for _, expected := range []string{
+		"service 'broken': error getting image tag digest: unauthorized",
+		"service 'db': image 'okteto/db:1.0' not found",
+		"service 'result': image 'okteto.dev/result:okteto' not found",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("error doesn't include '%s': %s", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), "'api'") || strings.Contains(err.Error(), "'vote'") {
+		t.Errorf("error includes available images: %s", err)
+	}
+	if len(checked) != len(s.Services) {
+		t.Errorf("not all images were checked: %v", checked)
+	}
+	if s.Services["vote"].Image != "" {
+		t.Errorf("checkImages() modified the stack: %+v", s.Services["vote"])
+	}
+
+	delete(s.Services, "db")
+	delete(s.Services, "broken")
+	delete(s.Services, "result")
+	if err := checkImages(ctx, s, true, getDigest); err != nil {
+		t.Errorf("checkImages() failed for available images: %s", err)
+	}
+
+	if err := checkImages(ctx, s, false, getDigest); err == nil {
+		t.Errorf("checkImages() didn't fail for a build without image outside okteto")
+	}
+}
+
+func Test_checkImagesWithOverrides(t *testing.T) {
+	ctx := context.Background()
+	checked := []string{}
+	getDigest := func(ctx context.Context, namespace, image string) (string, error) {
+		checked = append(checked, image)
+		return image, nil
+	}
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"vote": {Build: &model.BuildInfo{Context: "vote"}},
+		},
+	}
+	if err := s.OverrideImages(map[string]string{"vote": "okteto/vote@sha256:123"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkImages(ctx, s, true, getDigest); err != nil {
+		t.Fatal(err)
+	}
+	if len(checked) != 1 || checked[0] != "okteto/vote@sha256:123" {
+		t.Errorf("wrong images checked: %v", checked)
+	}
+
+	changed, err := getChangedServices(s, "main", func(dir, ref string) ([]string, error) {
+		t.Errorf("the build context of an overridden image was checked: %s", dir)
+		return []string{"/repo/vote/main.go"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("services with overridden images are built: %v", changed)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/okteto/okteto/pkg/model"
+)
+
+const (
+	configMapKind   = "ConfigMap"
+	serviceKind     = "Service"
+	deploymentKind  = "Deployment"
+	statefulSetKind = "StatefulSet"
+	daemonSetKind   = "DaemonSet"
+	jobKind         = "Job"
+	cronJobKind     = "CronJob"
+	pvcKind         = "PersistentVolumeClaim"
+	hpaKind         = "HorizontalPodAutoscaler"
+	pdbKind         = "PodDisruptionBudget"
+	ingressKind     = "Ingress"
+)
+
+//ApplyObject represents a kubernetes object applied by a stack deployment
+type ApplyObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (o ApplyObject) String() string {
+	return fmt.Sprintf("%s/%s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+//GetApplyOrder returns the ordered list of objects applied by a stack deployment:
+//the stack configmap, the volume claims of the named volumes, then the service, workload, daemonset, job or cronjob, volume claims, autoscaler and disruption budget of every service, and then the ingresses.
+//Services are applied after the services they depend on
+func GetApplyOrder(s *model.Stack) []ApplyObject {
+	result := []ApplyObject{
+		{Kind: configMapKind, Namespace: s.Namespace, Name: s.GetConfigMapName()},
+	}
+
+	for _, name := range s.GetVolumeNames() {
+		result = append(result, ApplyObject{Kind: pvcKind, Namespace: s.Namespace, Name: name})
+	}
+
+	for _, name := range getServiceDeployOrder(s) {
+		svc := s.Services[name]
+		if len(svc.GetPorts()) > 0 {
+			result = append(result, ApplyObject{Kind: serviceKind, Namespace: s.Namespace, Name: name})
+		}
+		if svc.IsCronJob() {
+			result = append(result, ApplyObject{Kind: cronJobKind, Namespace: s.Namespace, Name: name})
+		} else if svc.IsJob() {
+			result = append(result, ApplyObject{Kind: jobKind, Namespace: s.Namespace, Name: name})
+		} else if svc.IsGlobal() {
+			result = append(result, ApplyObject{Kind: daemonSetKind, Namespace: s.Namespace, Name: name})
+		} else if len(svc.Volumes) == 0 {
+			result = append(result, ApplyObject{Kind: deploymentKind, Namespace: s.Namespace, Name: name})
+		} else {
+			result = append(result, ApplyObject{Kind: statefulSetKind, Namespace: s.Namespace, Name: name})
+			for i := int32(0); i < svc.Replicas; i++ {
+				result = append(result, ApplyObject{Kind: pvcKind, Namespace: s.Namespace, Name: fmt.Sprintf("%s-%s-%d", pvcName, name, i)})
+			}
+		}
+		if svc.IsJob() || svc.IsCronJob() {
+			continue
+		}
+		if svc.Deploy != nil && svc.Deploy.Autoscaling != nil {
+			result = append(result, ApplyObject{Kind: hpaKind, Namespace: s.Namespace, Name: name})
+		}
+		if svc.Deploy != nil && svc.Deploy.PDB != nil {
+			result = append(result, ApplyObject{Kind: pdbKind, Namespace: s.Namespace, Name: name})
+		}
+	}
+
+	for _, name := range getSortedEndpointNames(s) {
+		result = append(result, ApplyObject{Kind: ingressKind, Namespace: s.Namespace, Name: name})
+	}
+	return result
+}
+
+//translateObject returns the desired manifest of an object applied by a stack deployment,
+//or nil for the objects created by kubernetes, like the volume claims of statefulsets
+func translateObject(obj ApplyObject, s *model.Stack) interface{} {
+	switch obj.Kind {
+	case configMapKind:
+		return translateConfigMap(s)
+	case pvcKind:
+		if _, ok := s.Volumes[obj.Name]; !ok {
+			return nil
+		}
+		return translatePersistentVolumeClaim(obj.Name, s)
+	case serviceKind:
+		return translateService(obj.Name, s)
+	case deploymentKind:
+		return translateDeployment(obj.Name, s)
+	case statefulSetKind:
+		return translateStatefulSet(obj.Name, s)
+	case daemonSetKind:
+		return translateDaemonSet(obj.Name, s)
+	case jobKind:
+		return translateJob(obj.Name, s)
+	case cronJobKind:
+		return translateCronJob(obj.Name, s)
+	case hpaKind:
+		return translateHorizontalPodAutoscaler(obj.Name, s)
+	case pdbKind:
+		return translatePodDisruptionBudget(obj.Name, s)
+	case ingressKind:
+		if s.IngressV1 {
+			return translateIngressV1(obj.Name, s)
+		}
+		return translateIngress(obj.Name, s)
+	}
+	return nil
+}
+
+func getSortedServiceNames(s *model.Stack) []string {
+	result := []string{}
+	for name := range s.Services {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+//getServiceDeployOrder returns the services sorted by name, moving every service after the services it depends on.
+//Dependencies on services that are not part of the stack are ignored
+func getServiceDeployOrder(s *model.Stack) []string {
+	result := []string{}
+	deployed := map[string]bool{}
+	pending := getSortedServiceNames(s)
+	for len(pending) > 0 {
+		next := []string{}
+		for _, name := range pending {
+			if isDeployable(s.Services[name], s, deployed) {
+				result = append(result, name)
+				deployed[name] = true
+				continue
+			}
+			next = append(next, name)
+		}
+		if len(next) == len(pending) {
+			// there is a dependency cycle, deploy the remaining services by name
+			return append(result, next...)
+		}
+		pending = next
+	}
+	return result
+}
+
+func isDeployable(svc model.Service, s *model.Stack, deployed map[string]bool) bool {
+	for dependency := range svc.DependsOn {
+		if _, ok := s.Services[dependency]; ok && !deployed[dependency] {
+			return false
+		}
+	}
+	return true
+}
+
+func getSortedEndpointNames(s *model.Stack) []string {
+	result := []string{}
+	for name := range s.Endpoints {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/okteto/okteto/pkg/model"
+)
+
+func Test_GetApplyOrder(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"web": {
+				Replicas: 1,
+				Ports:    []model.Port{{Port: 8080}},
+				Deploy: &model.DeployInfo{
+					Autoscaling: &model.AutoscalingInfo{Min: 1, Max: 3},
+					PDB:         &model.PDBInfo{MinAvailable: "1"},
+				},
+			},
+			"db": {
+				Replicas: 2,
+				Expose:   []model.Port{{Port: 5432, ContainerPort: 5432}},
+				Volumes:  []string{"/data"},
+			},
+			"worker": {
+				Replicas: 1,
+			},
+			"migrate": {
+				Replicas: 1,
+				Restart:  model.RestartNo,
+			},
+			"agent": {
+				Replicas: 1,
+				Restart:  model.RestartNo,
+				Deploy:   &model.DeployInfo{Mode: model.GlobalDeployMode},
+			},
+		},
+		Endpoints: map[string][]model.Endpoint{
+			"api": {{Path: "/", Service: "web", Port: 8080}},
+		},
+	}
+
+	expected := []string{
+		"ConfigMap/namespace/okteto-stackName",
+		"DaemonSet/namespace/agent",
+		"Service/namespace/db",
+		"StatefulSet/namespace/db",
+		"PersistentVolumeClaim/namespace/pvc-db-0",
+		"PersistentVolumeClaim/namespace/pvc-db-1",
+		"Job/namespace/migrate",
+		"Service/namespace/web",
+		"Deployment/namespace/web",
+		"HorizontalPodAutoscaler/namespace/web",
+		"PodDisruptionBudget/namespace/web",
+		"Deployment/namespace/worker",
+		"Ingress/namespace/api",
+	}
+
+	order := GetApplyOrder(s)
+	result := []string{}
+	for _, obj := range order {
+		result = append(result, obj.String())
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("wrong apply order.\nActual %+v, \nExpected %+v", result, expected)
+	}
+
+	index := map[string]int{}
+	for i, obj := range order {
+		if _, ok := index[obj.Kind]; !ok {
+			index[obj.Kind] = i
+		}
+	}
+	lastIndex := map[string]int{}
+	for i, obj := range order {
+		lastIndex[obj.Kind] = i
+	}
+	for _, kind := range []string{deploymentKind, statefulSetKind, daemonSetKind, serviceKind} {
+		if lastIndex[configMapKind] > index[kind] {
+			t.Errorf("configmaps must be applied before %s", kind)
+		}
+	}
+	if lastIndex[serviceKind] > index[ingressKind] {
+		t.Errorf("services must be applied before ingresses")
+	}
+}
+
+func Test_GetApplyOrderIsStable(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"a": {Replicas: 1},
+			"b": {Replicas: 1},
+			"c": {Replicas: 1},
+			"d": {Replicas: 1},
+		},
+	}
+	first := GetApplyOrder(s)
+	for i := 0; i < 10; i++ {
+		if !reflect.DeepEqual(first, GetApplyOrder(s)) {
+			t.Fatal("apply order is not stable")
+		}
+	}
+}
+
+func Test_getServiceDeployOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		services map[string]model.Service
+		expected []string
+	}{
+		{
+			name: "no-dependencies",
+			services: map[string]model.Service{
+				"c": {},
+				"a": {},
+				"b": {},
+			},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "dependencies",
+			services: map[string]model.Service{
+				"api":   {DependsOn: model.DependsOn{"db": {Condition: model.DependsOnServiceHealthy}, "queue": {Condition: model.DependsOnServiceStarted}}},
+				"db":    {},
+				"web":   {DependsOn: model.DependsOn{"api": {Condition: model.DependsOnServiceStarted}}},
+				"queue": {DependsOn: model.DependsOn{"db": {Condition: model.DependsOnServiceStarted}}},
+			},
+			expected: []string{"db", "queue", "api", "web"},
+		},
+		{
+			name: "dependency-not-in-stack",
+			services: map[string]model.Service{
+				"api": {DependsOn: model.DependsOn{"db": {Condition: model.DependsOnServiceStarted}}},
+				"web": {},
+			},
+			expected: []string{"api", "web"},
+		},
+		{
+			name: "cycle",
+			services: map[string]model.Service{
+				"a": {DependsOn: model.DependsOn{"b": {Condition: model.DependsOnServiceStarted}}},
+				"b": {DependsOn: model.DependsOn{"a": {Condition: model.DependsOnServiceStarted}}},
+				"c": {},
+			},
+			expected: []string{"c", "a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{Name: "stackName", Services: tt.services}
+			result := getServiceDeployOrder(s)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("wrong deploy order: %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okteto/okteto/pkg/cmd/build"
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/client"
+	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	"github.com/okteto/okteto/pkg/log"
+	"github.com/okteto/okteto/pkg/model"
+	"github.com/okteto/okteto/pkg/registry"
+	"k8s.io/client-go/kubernetes"
+)
+
+//Rollback re-deploys the stack manifest of a previous revision
+func Rollback(ctx context.Context, s *model.Stack, revision int, options *DeployOptions) error {
+	if s.Namespace == "" {
+		s.Namespace = client.GetContextNamespace("")
+	}
+
+	c, _, err := client.GetLocal()
+	if err != nil {
+		return err
+	}
+
+	rs, err := getRevisionStack(ctx, s, revision, c)
+	if err != nil {
+		return err
+	}
+
+	if !options.ForceBuild {
+		//the environment is resolved on a copy, so Deploy resolves it from the revision stack again
+		cs := rs.DeepCopy()
+		if err := translateStackEnvVars(cs); err != nil {
+			return err
+		}
+		_, isOktetoCluster, err := build.GetBuildKitHost()
+		if err != nil {
+			return err
+		}
+		if err := checkImages(ctx, cs, isOktetoCluster, registry.GetImageTagWithDigest); err != nil {
+			return fmt.Errorf("cannot roll back stack '%s' to revision %d: %s", s.Name, revision, err)
+		}
+	}
+
+	log.Information("Rolling back stack '%s' to revision %d...", s.Name, revision)
+	return Deploy(ctx, rs, options)
+}
+
+//getRevisionStack returns the stack deployed by a revision stored in the stack configmap
+func getRevisionStack(ctx context.Context, s *model.Stack, revision int, c kubernetes.Interface) (*model.Stack, error) {
+	cfg, err := configmaps.Get(ctx, s.GetConfigMapName(), s.Namespace, c)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("stack '%s' has not been deployed in namespace '%s'", s.Name, s.Namespace)
+		}
+		return nil, err
+	}
+
+	history, err := getHistory(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range history {
+		if r.Revision != revision {
+			continue
+		}
+		manifest, err := r.GetManifest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the manifest of revision %d: %s", revision, err)
+		}
+		return model.GetStackFromBytes(s.Name, s.Namespace, r.Dir, manifest, r.Overrides)
+	}
+
+	if len(history) == 0 {
+		return nil, fmt.Errorf("stack '%s' has no deployment history", s.Name)
+	}
+	return nil, fmt.Errorf("revision %d of stack '%s' does not exist: available revisions are %d to %d", revision, s.Name, history[0].Revision, history[len(history)-1].Revision)
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/okteto/okteto/pkg/model"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_getRevisionStack(t *testing.T) {
+	ctx := context.Background()
+	s := &model.Stack{Name: "stack-name", Namespace: "namespace"}
+
+	c := fake.NewSimpleClientset()
+	if _, err := getRevisionStack(ctx, s, 1, c); err == nil {
+		t.Fatal("getRevisionStack() didn't fail for a stack not deployed")
+	}
+
+	var cfg *apiv1.ConfigMap
+	manifests := []string{
+		"services:\n  api:\n    image: okteto/api:1.0\n",
+		"services:\n  api:\n    image: okteto/api:2.0\n",
+		"services:\n  api:\n    image: okteto/api:3.0\n",
+	}
+	for i, manifest := range manifests {
+		s.Manifest = []byte(manifest)
+		current := translateConfigMap(s)
+		if err := translateHistory(current, cfg, s, "cindy", time.Now().Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		cfg = current
+	}
+	cfg.Namespace = s.Namespace
+	c = fake.NewSimpleClientset(cfg)
+
+	rs, err := getRevisionStack(ctx, s, 2, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Name != "stack-name" || rs.Namespace != "namespace" {
+		t.Errorf("wrong revision stack name: %s/%s", rs.Namespace, rs.Name)
+	}
+	if rs.Services["api"].Image != "okteto/api:2.0" {
+		t.Errorf("wrong revision stack image: %s", rs.Services["api"].Image)
+	}
+	if string(rs.Manifest) != manifests[1] {
+		t.Errorf("wrong revision stack manifest: %s", rs.Manifest)
+	}
+
+	if _, err := getRevisionStack(ctx, s, 4, c); err == nil {
+		t.Errorf("getRevisionStack() didn't fail for an unknown revision")
+	}
+}
+
+func Test_getRevisionStackOverrides(t *testing.T) {
+	ctx := context.Background()
+	s := &model.Stack{
+		Name:      "stack-name",
+		Namespace: "namespace",
+		Manifest:  []byte("services:\n  api:\n    image: okteto/api:1.0\n  worker:\n    build: .\n  db:\n    build: db\n"),
+		Dir:       filepath.Join(os.TempDir(), "stack"),
+		Overrides: model.StackOverrides{
+			Set:      []string{"services.api.replicas=3"},
+			Commands: []string{"api=sleep infinity"},
+			Images:   map[string]string{"worker": "okteto/worker@sha256:123"},
+		},
+	}
+	cfg := translateConfigMap(s)
+	if err := translateHistory(cfg, nil, s, "cindy", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Namespace = s.Namespace
+	c := fake.NewSimpleClientset(cfg)
+
+	rs, err := getRevisionStack(ctx, s, 1, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := rs.Services["api"]
+	if api.Replicas != 3 {
+		t.Errorf("'--set' override was not applied: %d replicas", api.Replicas)
+	}
+	if !reflect.DeepEqual(api.Command.Values, []string{"sleep", "infinity"}) {
+		t.Errorf("'--command' override was not applied: %v", api.Command.Values)
+	}
+	worker := rs.Services["worker"]
+	if worker.Image != "okteto/worker@sha256:123" || worker.Build != nil {
+		t.Errorf("'--images' override was not applied: %s %+v", worker.Image, worker.Build)
+	}
+	db := rs.Services["db"]
+	if db.Build == nil || db.Build.Context != filepath.Join(s.Dir, "db") || db.Build.Dockerfile != filepath.Join(s.Dir, "db", "Dockerfile") {
+		t.Errorf("build paths were not resolved against the stack folder: %+v", db.Build)
+	}
+	if rs.Dir != s.Dir {
+		t.Errorf("wrong revision stack folder: %s", rs.Dir)
+	}
+	if !reflect.DeepEqual(rs.Overrides, s.Overrides) {
+		t.Errorf("wrong revision stack overrides: %+v", rs.Overrides)
+	}
+}
@@ -15,36 +15,48 @@ package stack
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/okteto/okteto/pkg/cmd/build"
 	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/k8s/configmaps"
+	"github.com/okteto/okteto/pkg/k8s/ingress"
 	okLabels "github.com/okteto/okteto/pkg/k8s/labels"
+	"github.com/okteto/okteto/pkg/k8s/nodes"
 	"github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 	"github.com/okteto/okteto/pkg/registry"
-	"github.com/subosito/gotenv"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	apiv1 "k8s.io/api/core/v1"
 	extensions "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
 )
 
 const (
 	helmDriver = "secrets"
 
-	nameField   = "name"
-	statusField = "status"
-	yamlField   = "yaml"
-	outputField = "output"
+	nameField     = "name"
+	statusField   = "status"
+	yamlField     = "yaml"
+	outputField   = "output"
+	manifestField = "manifest"
 
 	progressingStatus = "progressing"
 	deployedStatus    = "deployed"
@@ -52,87 +64,331 @@ const (
 	destroyingStatus  = "destroying"
 
 	pvcName = "pvc"
+
+	namedVolumePrefix = "volume"
+
+	tmpfsVolumePrefix = "tmpfs"
+
+	deviceVolumePrefix = "device"
+
+	manifestVolumeName = "okteto-manifest"
+
+	populatorMountPath = "/okteto/volume"
+
+	defaultVolumeInitImage = "docker.io/library/busybox:1.33"
+
+	serviceNameToken = "{{.ServiceName}}"
+	stackNameToken   = "{{.StackName}}"
+	namespaceToken   = "{{.Namespace}}"
+
+	defaultTerminationGracePeriod = int64(30)
+
+	imageDigestVariable = "${" + model.ImageDigestEnv + "}"
 )
 
-func translate(ctx context.Context, s *model.Stack, forceBuild, noCache bool) error {
+//translate returns a copy of the stack with its environment, platform overrides, provider and images resolved.
+//The given stack is never modified, so concurrent deployments can share it
+func translate(ctx context.Context, s *model.Stack, c kubernetes.Interface, options *DeployOptions) (*model.Stack, error) {
+	s = s.DeepCopy()
 	if err := translateStackEnvVars(s); err != nil {
-		return err
+		return nil, err
 	}
+	if err := translateConfigSources(ctx, s, c); err != nil {
+		return nil, err
+	}
+
+	translatePlatformOverrides(ctx, s, c)
+	translateProvider(ctx, s, c)
+	translateIngressAPI(ctx, s, c)
 
-	return translateBuildImages(ctx, s, forceBuild, noCache)
+	if err := translateBuildImages(ctx, s, options); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
+//translateStackEnvVars resolves the environment of the stack services in place. Images and volumes are expanded by model.ReadStack.
+//Like the other translate functions modifying the stack, it must be called on a copy owned by the caller
 func translateStackEnvVars(s *model.Stack) error {
 	var err error
 	for name, svc := range s.Services {
-		svc.Image, err = model.ExpandEnv(svc.Image)
+		svc.Environment, err = s.GetServiceEnv(name)
 		if err != nil {
 			return err
 		}
-		for _, envFilepath := range svc.EnvFiles {
-			if err := translateServiceEnvFile(&svc, envFilepath); err != nil {
-				return err
+		svc.EnvFiles = nil
+		s.Services[name] = svc
+	}
+	return nil
+}
+
+//configSource is a config map or secret consumed by a service
+type configSource struct {
+	kind string
+	name string
+}
+
+//translateConfigSources sets the checksum of the data of the config maps and secrets consumed by each service, so changes in their data roll out its pods.
+//It includes the stack manifest for services with 'mount_manifest'
+func translateConfigSources(ctx context.Context, s *model.Stack, c kubernetes.Interface) error {
+	for name, svc := range s.Services {
+		sources := getConfigSources(&svc)
+		if len(sources) == 0 && svc.MountManifest == "" {
+			continue
+		}
+		h := sha256.New()
+		if svc.MountManifest != "" {
+			fmt.Fprintf(h, "manifest=%s\n", base64.StdEncoding.EncodeToString(s.Manifest))
+		}
+		for _, source := range sources {
+			data, err := getConfigSourceData(ctx, s.Namespace, source, c)
+			if err != nil {
+				return fmt.Errorf("error getting %s '%s' of service '%s': %s", source.kind, source.name, name, err)
+			}
+			fmt.Fprintf(h, "%s/%s\n", source.kind, source.name)
+			keys := make([]string, 0, len(data))
+			for k := range data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(h, "%s=%s\n", k, base64.StdEncoding.EncodeToString(data[k]))
 			}
 		}
-		sort.SliceStable(svc.Environment, func(i, j int) bool {
-			return strings.Compare(svc.Environment[i].Name, svc.Environment[j].Name) < 0
-		})
-		svc.EnvFiles = nil
+		svc.SourcesChecksum = hex.EncodeToString(h.Sum(nil))
 		s.Services[name] = svc
 	}
 	return nil
 }
 
-func translateServiceEnvFile(svc *model.Service, filename string) error {
-	var err error
-	filename, err = model.ExpandEnv(filename)
-	if err != nil {
-		return err
+//getConfigSources returns the sorted config maps and secrets referenced by the 'valueFrom' and 'env_from' fields of a service
+func getConfigSources(svc *model.Service) []configSource {
+	found := map[configSource]bool{}
+	for _, e := range svc.Environment {
+		if e.ValueFrom == nil {
+			continue
+		}
+		if e.ValueFrom.ConfigMapKeyRef != nil {
+			found[configSource{kind: "configmap", name: e.ValueFrom.ConfigMapKeyRef.Name}] = true
+		}
+		if e.ValueFrom.SecretKeyRef != nil {
+			found[configSource{kind: "secret", name: e.ValueFrom.SecretKeyRef.Name}] = true
+		}
+	}
+	for _, source := range svc.EnvFrom {
+		if source.ConfigMapRef != nil {
+			found[configSource{kind: "configmap", name: source.ConfigMapRef.Name}] = true
+		}
+		if source.SecretRef != nil {
+			found[configSource{kind: "secret", name: source.SecretRef.Name}] = true
+		}
+	}
+	result := make([]configSource, 0, len(found))
+	for source := range found {
+		result = append(result, source)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].kind != result[j].kind {
+			return result[i].kind < result[j].kind
+		}
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+//getConfigSourceData returns the data of a config map or secret of the namespace. Missing sources have no data: their pods don't start until they are created
+func getConfigSourceData(ctx context.Context, namespace string, source configSource, c kubernetes.Interface) (map[string][]byte, error) {
+	result := map[string][]byte{}
+	switch source.kind {
+	case "configmap":
+		cfg, err := configmaps.Get(ctx, source.name, namespace, c)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return result, nil
+			}
+			return nil, err
+		}
+		for k, v := range cfg.Data {
+			result[k] = []byte(v)
+		}
+		for k, v := range cfg.BinaryData {
+			result[k] = v
+		}
+	case "secret":
+		secret, err := c.CoreV1().Secrets(namespace).Get(ctx, source.name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return result, nil
+			}
+			return nil, err
+		}
+		for k, v := range secret.Data {
+			result[k] = v
+		}
 	}
+	return result, nil
+}
 
-	f, err := os.Open(filename)
+//translateBuildImage returns the expanded image of a service built by okteto, or an image of the okteto registry in okteto clusters.
+//Images templated with environment variables that resolve to an external registry, like '${REGISTRY}/app:${TAG}', are never overwritten
+func translateBuildImage(stackName, svcName string, svc *model.Service, isOktetoCluster bool) (string, error) {
+	image, err := model.ExpandStackEnv(svc.Image)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if !isOktetoCluster || strings.HasPrefix(image, "okteto.dev") {
+		return image, nil
+	}
+	if image != svc.Image && hasExternalRegistry(image) {
+		return image, nil
+	}
+	return fmt.Sprintf("okteto.dev/%s-%s:okteto", stackName, svcName), nil
+}
+
+//buildRunner builds the image of a service and pushes it, returning its digest, like build.Run
+type buildRunner func(ctx context.Context, namespace, buildKitHost string, isOktetoCluster bool, path, dockerFile, tag, target, network string, noCache bool, cacheFrom, buildArgs, secrets []string, progress string) (string, error)
+
+//buildService builds the image of a service with its build options
+func buildService(ctx context.Context, namespace string, svc *model.Service, buildKitHost string, isOktetoCluster, noCache bool, run buildRunner) (string, error) {
+	buildArgs := model.SerializeBuildArgs(svc.Build.Args)
+	cacheFrom := translateBuildCacheFrom(svc, isOktetoCluster, noCache)
+	return run(ctx, namespace, buildKitHost, isOktetoCluster, svc.Build.Context, svc.Build.Dockerfile, svc.Image, svc.Build.Target, svc.Build.Network, noCache, cacheFrom, buildArgs, nil, "tty")
+}
+
+//translateBuildCacheFrom returns the images used as build cache of a service.
+//On okteto clusters, services without 'cache_from' reuse the layers of their previous okteto.dev image
+func translateBuildCacheFrom(svc *model.Service, isOktetoCluster, noCache bool) []string {
+	if len(svc.Build.CacheFrom) > 0 {
+		return append([]string{}, svc.Build.CacheFrom...)
+	}
+	if !isOktetoCluster || noCache || !strings.HasPrefix(svc.Image, "okteto.dev") {
+		return nil
+	}
+	return []string{svc.Image}
+}
+
+//hasExternalRegistry returns true if the image name starts with a registry host, like 'registry.example.com/app' or 'localhost:5000/app'
+func hasExternalRegistry(image string) bool {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return false
+	}
+	host := image[:i]
+	return strings.ContainsAny(host, ".:") || host == "localhost"
+}
+
+//translatePlatformOverrides applies the command and args of the platform of the cluster nodes
+func translatePlatformOverrides(ctx context.Context, s *model.Stack, c kubernetes.Interface) {
+	hasPlatforms := false
+	for _, svc := range s.Services {
+		if len(svc.Platforms) > 0 {
+			hasPlatforms = true
+			break
+		}
+	}
+	if !hasPlatforms {
+		return
 	}
-	defer f.Close()
 
-	envMap, err := gotenv.StrictParse(f)
+	platforms, err := nodes.GetPlatforms(ctx, c)
 	if err != nil {
-		return fmt.Errorf("error parsing env_file %s: %s", filename, err.Error())
+		log.Infof("failed to get the platforms of the cluster: %s", err)
+		log.Warning("Ignoring 'platforms': the platform of your cluster nodes couldn't be detected")
+		return
+	}
+	if len(platforms) != 1 {
+		log.Warning("Ignoring 'platforms': your cluster nodes don't have a single platform: [%s]", strings.Join(platforms, ", "))
+		return
 	}
 
-	for _, e := range svc.Environment {
-		delete(envMap, e.Name)
+	for name, svc := range s.Services {
+		override, ok := svc.Platforms[platforms[0]]
+		if !ok {
+			continue
+		}
+		log.Infof("using the '%s' command of service '%s'", platforms[0], name)
+		if len(override.Command.Values) > 0 {
+			svc.Command.Values = override.Command.Values
+		}
+		if len(override.Args.Values) > 0 {
+			svc.Args.Values = override.Args.Values
+		}
+		s.Services[name] = svc
 	}
+}
 
-	for name, value := range envMap {
-		svc.Environment = append(
-			svc.Environment,
-			model.EnvVar{Name: name, Value: value},
-		)
+//translateIngressAPI detects if the cluster serves networking.k8s.io/v1 ingresses, falling back to extensions/v1beta1 for older clusters
+func translateIngressAPI(ctx context.Context, s *model.Stack, c kubernetes.Interface) {
+	if len(s.Endpoints) == 0 {
+		return
+	}
+	s.IngressV1 = ingress.IsV1Enabled(ctx, c)
+}
+
+//translateProvider detects the cloud provider of the cluster, needed by the load balancer options of public services
+func translateProvider(ctx context.Context, s *model.Stack, c kubernetes.Interface) {
+	hasLoadBalancerOptions := false
+	for _, svc := range s.Services {
+		if svc.HasLoadBalancerOptions() {
+			hasLoadBalancerOptions = true
+			break
+		}
+	}
+	if !hasLoadBalancerOptions {
+		return
+	}
+
+	provider, err := nodes.GetProvider(ctx, c)
+	if err != nil {
+		log.Infof("failed to get the provider of the cluster: %s", err)
+	}
+	if provider == "" {
+		log.Warning("Ignoring 'load_balancer' and 'nlb': the cloud provider of your cluster couldn't be detected")
+		return
+	}
+	s.Provider = provider
+	for name, svc := range s.Services {
+		if svc.NLB && provider != model.ProviderEKS {
+			log.Warning("Ignoring 'nlb' in service '%s': network load balancers are only supported in EKS clusters", name)
+		}
 	}
-	return nil
 }
 
-func translateBuildImages(ctx context.Context, s *model.Stack, forceBuild, noCache bool) error {
+func translateBuildImages(ctx context.Context, s *model.Stack, options *DeployOptions) error {
 	buildKitHost, isOktetoCluster, err := build.GetBuildKitHost()
 	if err != nil {
 		return err
 	}
 	building := false
+	forceBuild := options.ForceBuild
+
+	changed := map[string]bool{}
+	if options.BuildChangedSince != "" && !forceBuild {
+		changed, err = getChangedServices(s, options.BuildChangedSince, getGitChangedFiles)
+		if err != nil {
+			log.Warning("Failed to detect the services changed since '%s', building all of them: %s", options.BuildChangedSince, err)
+			forceBuild = true
+		}
+	}
 
 	for name, svc := range s.Services {
 		if svc.Build == nil {
 			continue
 		}
-		if !isOktetoCluster && svc.Image == "" {
-			return fmt.Errorf("'build' and 'image' fields of service '%s' cannot be empty", name)
+		svc.Image, err = translateBuildImage(s.Name, name, &svc, isOktetoCluster)
+		if err != nil {
+			return err
 		}
-		if isOktetoCluster && !strings.HasPrefix(svc.Image, "okteto.dev") {
-			svc.Image = fmt.Sprintf("okteto.dev/%s-%s:okteto", s.Name, name)
+		if svc.Image == "" {
+			return fmt.Errorf("'build' and 'image' fields of service '%s' cannot be empty", name)
 		}
-		if !forceBuild {
-			if _, err := registry.GetImageTagWithDigest(ctx, s.Namespace, svc.Image); err != errors.ErrNotFound {
+		if changed[name] {
+			log.Infof("the build context of service '%s' changed since '%s'", name, options.BuildChangedSince)
+		} else if !forceBuild && !s.Okteto.SkipRegistryCheck {
+			if tagWithDigest, err := registry.GetImageTagWithDigest(ctx, s.Namespace, svc.Image); err != errors.ErrNotFound {
+				if i := strings.LastIndex(tagWithDigest, "@"); err == nil && i >= 0 {
+					translateImageDigest(&svc, tagWithDigest[i+1:])
+				}
 				s.Services[name] = svc
 				continue
 			}
@@ -143,10 +399,13 @@ func translateBuildImages(ctx context.Context, s *model.Stack, forceBuild, noCac
 			log.Information("Running your build in %s...", buildKitHost)
 		}
 		log.Information("Building image for service '%s'...", name)
-		buildArgs := model.SerializeBuildArgs(svc.Build.Args)
-		if err := build.Run(ctx, s.Namespace, buildKitHost, isOktetoCluster, svc.Build.Context, svc.Build.Dockerfile, svc.Image, svc.Build.Target, noCache, svc.Build.CacheFrom, buildArgs, nil, "tty"); err != nil {
+		digest, err := buildService(ctx, s.Namespace, &svc, buildKitHost, isOktetoCluster, options.NoCache, build.Run)
+		if err != nil {
 			return fmt.Errorf("error building image for '%s': %s", name, err)
 		}
+		if digest != "" {
+			translateImageDigest(&svc, digest)
+		}
 		svc.SetLastBuiltAnnotation()
 		s.Services[name] = svc
 		log.Success("Image for service '%s' successfully pushed", name)
@@ -159,19 +418,46 @@ func translateBuildImages(ctx context.Context, s *model.Stack, forceBuild, noCac
 	return nil
 }
 
+//translateImageDigest replaces the image digest variable in the command, args and environment of a service.
+//Environment values and env files keep the variable when the manifest is read
+func translateImageDigest(svc *model.Service, digest string) {
+	for i := range svc.Command.Values {
+		svc.Command.Values[i] = strings.ReplaceAll(svc.Command.Values[i], imageDigestVariable, digest)
+	}
+	for i := range svc.Args.Values {
+		svc.Args.Values[i] = strings.ReplaceAll(svc.Args.Values[i], imageDigestVariable, digest)
+	}
+	for i := range svc.Environment {
+		svc.Environment[i].Value = strings.ReplaceAll(svc.Environment[i].Value, imageDigestVariable, digest)
+	}
+}
+
 func translateConfigMap(s *model.Stack) *apiv1.ConfigMap {
-	return &apiv1.ConfigMap{
+	cfg := &apiv1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: s.GetConfigMapName(),
-			Labels: map[string]string{
-				okLabels.StackLabel: "true",
-			},
+			Name:        s.GetConfigMapName(),
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
 		},
 		Data: map[string]string{
 			nameField: s.Name,
 			yamlField: base64.StdEncoding.EncodeToString(s.Manifest),
 		},
 	}
+	for k, v := range s.Labels {
+		cfg.Labels[k] = v
+	}
+	cfg.Labels[okLabels.StackLabel] = "true"
+	for k, v := range s.Annotations {
+		cfg.Annotations[k] = v
+	}
+	for _, svc := range s.Services {
+		if svc.MountManifest != "" {
+			cfg.Data[manifestField] = string(s.Manifest)
+			break
+		}
+	}
+	return cfg
 }
 
 func translateDeployment(svcName string, s *model.Stack) *appsv1.Deployment {
@@ -181,32 +467,91 @@ func translateDeployment(svcName string, s *model.Stack) *appsv1.Deployment {
 			Name:        svcName,
 			Namespace:   s.Namespace,
 			Labels:      translateLabels(svcName, s),
-			Annotations: translateAnnotations(&svc),
+			Annotations: translateWorkloadAnnotations(svcName, s),
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: pointer.Int32Ptr(svc.Replicas),
+			Replicas: translateReplicas(&svc),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: translateLabelSelector(svcName, s),
+			},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      translatePodLabels(svcName, s),
+					Annotations: translatePodAnnotations(svcName, s),
+				},
+				Spec: apiv1.PodSpec{
+					TerminationGracePeriodSeconds: translateTerminationGracePeriod(&svc),
+					SecurityContext:               translatePodSecurityContext(&svc),
+					NodeSelector:                  translateNodeSelector(&svc),
+					Containers: []apiv1.Container{
+						{
+							Name:            svcName,
+							Image:           svc.Image,
+							ImagePullPolicy: translateImagePullPolicy(&svc),
+							Command:         svc.Command.Values,
+							Args:            svc.Args.Values,
+							Env:             translateServiceEnvironment(&svc),
+							EnvFrom:         translateServiceEnvFrom(&svc),
+							Ports:           translateContainerPorts(&svc),
+							SecurityContext: translateSecurityContext(&svc),
+							Stdin:           svc.StdinOpen,
+							TTY:             svc.TTY,
+							VolumeMounts:    translateVolumeMounts(&svc),
+							Resources:       translateResources(&svc),
+							LivenessProbe:   translateHealthCheckProbe(&svc),
+							ReadinessProbe:  translateHealthCheckProbe(&svc),
+						},
+					},
+					Volumes: translateVolumes(&svc, s),
+				},
+			},
+		},
+	}
+}
+
+//translateDaemonSet returns the daemonset of a global service, running one pod in every node
+func translateDaemonSet(svcName string, s *model.Stack) *appsv1.DaemonSet {
+	svc := s.Services[svcName]
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        svcName,
+			Namespace:   s.Namespace,
+			Labels:      translateLabels(svcName, s),
+			Annotations: translateWorkloadAnnotations(svcName, s),
+		},
+		Spec: appsv1.DaemonSetSpec{
 			Selector: &metav1.LabelSelector{
 				MatchLabels: translateLabelSelector(svcName, s),
 			},
 			Template: apiv1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      translateLabels(svcName, s),
-					Annotations: translateAnnotations(&svc),
+					Labels:      translatePodLabels(svcName, s),
+					Annotations: translatePodAnnotations(svcName, s),
 				},
 				Spec: apiv1.PodSpec{
-					TerminationGracePeriodSeconds: pointer.Int64Ptr(svc.StopGracePeriod),
+					TerminationGracePeriodSeconds: translateTerminationGracePeriod(&svc),
+					SecurityContext:               translatePodSecurityContext(&svc),
+					NodeSelector:                  translateNodeSelector(&svc),
 					Containers: []apiv1.Container{
 						{
 							Name:            svcName,
 							Image:           svc.Image,
+							ImagePullPolicy: translateImagePullPolicy(&svc),
 							Command:         svc.Command.Values,
 							Args:            svc.Args.Values,
 							Env:             translateServiceEnvironment(&svc),
+							EnvFrom:         translateServiceEnvFrom(&svc),
 							Ports:           translateContainerPorts(&svc),
 							SecurityContext: translateSecurityContext(&svc),
+							Stdin:           svc.StdinOpen,
+							TTY:             svc.TTY,
+							VolumeMounts:    translateVolumeMounts(&svc),
 							Resources:       translateResources(&svc),
+							LivenessProbe:   translateHealthCheckProbe(&svc),
+							ReadinessProbe:  translateHealthCheckProbe(&svc),
 						},
 					},
+					Volumes: translateVolumes(&svc, s),
 				},
 			},
 		},
@@ -220,10 +565,10 @@ func translateStatefulSet(name string, s *model.Stack) *appsv1.StatefulSet {
 			Name:        name,
 			Namespace:   s.Namespace,
 			Labels:      translateLabels(name, s),
-			Annotations: translateAnnotations(&svc),
+			Annotations: translateWorkloadAnnotations(name, s),
 		},
 		Spec: appsv1.StatefulSetSpec{
-			Replicas:             pointer.Int32Ptr(svc.Replicas),
+			Replicas:             translateReplicas(&svc),
 			RevisionHistoryLimit: pointer.Int32Ptr(2),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: translateLabelSelector(name, s),
@@ -231,37 +576,34 @@ func translateStatefulSet(name string, s *model.Stack) *appsv1.StatefulSet {
 			ServiceName: name,
 			Template: apiv1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      translateLabels(name, s),
-					Annotations: translateAnnotations(&svc),
+					Labels:      translatePodLabels(name, s),
+					Annotations: translatePodAnnotations(name, s),
 				},
 				Spec: apiv1.PodSpec{
-					TerminationGracePeriodSeconds: pointer.Int64Ptr(svc.StopGracePeriod),
-					InitContainers: []apiv1.Container{
-						{
-							Name:    fmt.Sprintf("init-%s", name),
-							Image:   "busybox",
-							Command: []string{"chmod", "-R", "777", "/data"},
-							VolumeMounts: []apiv1.VolumeMount{
-								{
-									MountPath: "/data",
-									Name:      pvcName,
-								},
-							},
-						},
-					},
+					TerminationGracePeriodSeconds: translateTerminationGracePeriod(&svc),
+					SecurityContext:               translatePodSecurityContext(&svc),
+					NodeSelector:                  translateNodeSelector(&svc),
+					InitContainers:                translateInitContainers(name, &svc),
 					Containers: []apiv1.Container{
 						{
 							Name:            name,
 							Image:           svc.Image,
+							ImagePullPolicy: translateImagePullPolicy(&svc),
 							Command:         svc.Command.Values,
 							Args:            svc.Args.Values,
 							Env:             translateServiceEnvironment(&svc),
+							EnvFrom:         translateServiceEnvFrom(&svc),
 							Ports:           translateContainerPorts(&svc),
 							SecurityContext: translateSecurityContext(&svc),
+							Stdin:           svc.StdinOpen,
+							TTY:             svc.TTY,
 							VolumeMounts:    translateVolumeMounts(&svc),
 							Resources:       translateResources(&svc),
+							LivenessProbe:   translateHealthCheckProbe(&svc),
+							ReadinessProbe:  translateHealthCheckProbe(&svc),
 						},
 					},
+					Volumes: translateVolumes(&svc, s),
 				},
 			},
 			VolumeClaimTemplates: []apiv1.PersistentVolumeClaim{
@@ -269,13 +611,13 @@ func translateStatefulSet(name string, s *model.Stack) *appsv1.StatefulSet {
 					ObjectMeta: metav1.ObjectMeta{
 						Name:        pvcName,
 						Labels:      translateLabels(name, s),
-						Annotations: translateAnnotations(&svc),
+						Annotations: translateAnnotations(name, s),
 					},
 					Spec: apiv1.PersistentVolumeClaimSpec{
-						AccessModes: []apiv1.PersistentVolumeAccessMode{apiv1.ReadWriteOnce},
+						AccessModes: []apiv1.PersistentVolumeAccessMode{translateStorageAccessMode(&svc)},
 						Resources: apiv1.ResourceRequirements{
 							Requests: apiv1.ResourceList{
-								"storage": svc.Resources.Requests.Storage.Size.Value,
+								"storage": translateStorageSize(&svc),
 							},
 						},
 						StorageClassName: translateStorageClass(&svc),
@@ -286,131 +628,871 @@ func translateStatefulSet(name string, s *model.Stack) *appsv1.StatefulSet {
 	}
 }
 
-func translateService(svcName string, s *model.Stack) *apiv1.Service {
-	svc := s.Services[svcName]
-	annotations := translateAnnotations(&svc)
-	if svc.Public {
-		annotations[okLabels.OktetoAutoIngressAnnotation] = "true"
-	}
-	return &apiv1.Service{
+//translateJob returns the job running a service to completion, annotated with the checksum of its pod template
+func translateJob(svcName string, s *model.Stack) *batchv1.Job {
+	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        svcName,
 			Namespace:   s.Namespace,
 			Labels:      translateLabels(svcName, s),
-			Annotations: annotations,
-		},
-		Spec: apiv1.ServiceSpec{
-			Selector: translateLabelSelector(svcName, s),
-			Type:     translateServiceType(&svc),
-			Ports:    translateServicePorts(&svc),
+			Annotations: translateAnnotations(svcName, s),
 		},
+		Spec: translateJobSpec(svcName, s),
 	}
+	job.Annotations[okLabels.StackJobChecksumAnnotation] = translateJobChecksum(&job.Spec.Template)
+	return job
 }
 
-func translateIngress(ingressName string, s *model.Stack) *extensions.Ingress {
-	endpoints := s.Endpoints[ingressName]
-	annotations := map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"}
-	return &extensions.Ingress{
+//translateCronJob returns the cronjob running a service periodically on its schedule
+func translateCronJob(svcName string, s *model.Stack) *batchv1beta1.CronJob {
+	svc := s.Services[svcName]
+	return &batchv1beta1.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        ingressName,
+			Name:        svcName,
 			Namespace:   s.Namespace,
-			Labels:      translateIngressLabels(ingressName, s),
-			Annotations: annotations,
+			Labels:      translateLabels(svcName, s),
+			Annotations: translateAnnotations(svcName, s),
 		},
-		Spec: extensions.IngressSpec{
-			Rules: []extensions.IngressRule{
-				{
-					IngressRuleValue: extensions.IngressRuleValue{
-						HTTP: &extensions.HTTPIngressRuleValue{
-							Paths: translateEndpoints(endpoints),
-						},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: svc.Schedule,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: translateJobSpec(svcName, s),
+			},
+		},
+	}
+}
+
+func translateJobSpec(svcName string, s *model.Stack) batchv1.JobSpec {
+	svc := s.Services[svcName]
+	return batchv1.JobSpec{
+		Template: apiv1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      translatePodLabels(svcName, s),
+				Annotations: translatePodAnnotations(svcName, s),
+			},
+			Spec: apiv1.PodSpec{
+				RestartPolicy:                 translateJobRestartPolicy(&svc),
+				TerminationGracePeriodSeconds: translateTerminationGracePeriod(&svc),
+				SecurityContext:               translatePodSecurityContext(&svc),
+				NodeSelector:                  translateNodeSelector(&svc),
+				Containers: []apiv1.Container{
+					{
+						Name:            svcName,
+						Image:           svc.Image,
+						ImagePullPolicy: translateImagePullPolicy(&svc),
+						Command:         svc.Command.Values,
+						Args:            svc.Args.Values,
+						Env:             translateServiceEnvironment(&svc),
+						EnvFrom:         translateServiceEnvFrom(&svc),
+						SecurityContext: translateSecurityContext(&svc),
+						Stdin:           svc.StdinOpen,
+						TTY:             svc.TTY,
+						VolumeMounts:    translateVolumeMounts(&svc),
+						Resources:       translateResources(&svc),
 					},
 				},
+				Volumes: translateVolumes(&svc, s),
 			},
 		},
 	}
 }
 
-func translateEndpoints(endpoints []model.Endpoint) []extensions.HTTPIngressPath {
-	paths := make([]extensions.HTTPIngressPath, 0)
-	for _, endpoint := range endpoints {
-		path := extensions.HTTPIngressPath{
-			Path: endpoint.Path,
-			Backend: extensions.IngressBackend{
-				ServiceName: endpoint.Service,
-				ServicePort: intstr.IntOrString{IntVal: endpoint.Port},
+//translateJobChecksum returns a checksum of the pod template of a job, which is immutable, to detect when it must be recreated
+func translateJobChecksum(template *apiv1.PodTemplateSpec) string {
+	b, _ := json.Marshal(template)
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+//translateInitContainers returns the init container granting access to the service volumes, seeding one of them if 'volume_populator' is set.
+//There is no init container if 'skip_volume_chmod' or a 'user' group is set and the volumes are not seeded
+func translateInitContainers(name string, svc *model.Service) []apiv1.Container {
+	if svc.VolumePopulator == nil {
+		if svc.SkipVolumeChmod || hasFSGroup(svc) {
+			return nil
+		}
+		return []apiv1.Container{
+			{
+				Name:    fmt.Sprintf("init-%s", name),
+				Image:   translateVolumeInitImage(svc),
+				Command: []string{"chmod", "-R", "777", "/data"},
+				VolumeMounts: []apiv1.VolumeMount{
+					{
+						MountPath: "/data",
+						Name:      pvcName,
+					},
+				},
 			},
 		}
-		paths = append(paths, path)
 	}
-	return paths
-}
 
-func translateLabels(svcName string, s *model.Stack) map[string]string {
-	svc := s.Services[svcName]
-	labels := map[string]string{
-		okLabels.StackNameLabel:        s.Name,
-		okLabels.StackServiceNameLabel: svcName,
+	target := populatorMountPath
+	for i, v := range svc.Volumes {
+		if volume, _ := model.ParseVolume(v); volume.MountPath == svc.VolumePopulator.Target {
+			target = fmt.Sprintf("%s/data-%d", populatorMountPath, i)
+		}
 	}
-	for k := range svc.Labels {
-		labels[k] = svc.Labels[k]
+	command := fmt.Sprintf("mkdir -p %s && cp -Rn %s/. %s", target, svc.VolumePopulator.Source, target)
+	if !svc.SkipVolumeChmod && !hasFSGroup(svc) {
+		command = fmt.Sprintf("%s && chmod -R 777 %s", command, populatorMountPath)
 	}
-	return labels
-}
-
-func translateIngressLabels(endpointName string, s *model.Stack) map[string]string {
-	labels := map[string]string{
-		okLabels.StackNameLabel:         s.Name,
-		okLabels.StackEndpointNameLabel: endpointName,
+	return []apiv1.Container{
+		{
+			Name:    fmt.Sprintf("init-%s", name),
+			Image:   svc.VolumePopulator.Image,
+			Command: []string{"sh", "-c", command},
+			VolumeMounts: []apiv1.VolumeMount{
+				{
+					MountPath: populatorMountPath,
+					Name:      pvcName,
+				},
+			},
+		},
 	}
-	return labels
 }
 
-func translateLabelSelector(svcName string, s *model.Stack) map[string]string {
-	labels := map[string]string{
-		okLabels.StackNameLabel:        s.Name,
-		okLabels.StackServiceNameLabel: svcName,
-	}
-	return labels
+//hasFSGroup returns true if the volumes of the service are owned by the group of its 'user', so they don't need to be writable by everyone
+func hasFSGroup(svc *model.Service) bool {
+	_, gid, _ := model.ParseUser(svc.User)
+	return gid != nil
 }
 
-func translateAnnotations(svc *model.Service) map[string]string {
-	result := map[string]string{}
-	for k, v := range svc.Annotations {
-		result[k] = v
+//translateVolumeInitImage returns 'volume_init_image', or a fully qualified busybox image that registry mirrors can serve
+func translateVolumeInitImage(svc *model.Service) string {
+	if svc.VolumeInitImage != "" {
+		return svc.VolumeInitImage
 	}
-	return result
+	return defaultVolumeInitImage
 }
 
-func translateServiceType(svc *model.Service) apiv1.ServiceType {
-	if svc.Public {
-		return apiv1.ServiceTypeLoadBalancer
+//translateReplicas returns the static number of replicas of a service, or nil if it is autoscaled so the autoscaler owns it
+func translateReplicas(svc *model.Service) *int32 {
+	if svc.Deploy != nil && svc.Deploy.Autoscaling != nil {
+		return nil
 	}
-	return apiv1.ServiceTypeClusterIP
+	return pointer.Int32Ptr(svc.Replicas)
 }
 
-func translateVolumeMounts(svc *model.Service) []apiv1.VolumeMount {
-	result := []apiv1.VolumeMount{}
-	for i, v := range svc.Volumes {
-		result = append(
-			result,
-			apiv1.VolumeMount{
-				MountPath: v,
-				Name:      pvcName,
-				SubPath:   fmt.Sprintf("data-%d", i),
+func translateHorizontalPodAutoscaler(svcName string, s *model.Stack) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	svc := s.Services[svcName]
+	if svc.Deploy == nil || svc.Deploy.Autoscaling == nil {
+		return nil
+	}
+	autoscaling := svc.Deploy.Autoscaling
+	kind := "Deployment"
+	if len(svc.Volumes) > 0 {
+		kind = "StatefulSet"
+	}
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        svcName,
+			Namespace:   s.Namespace,
+			Labels:      translateLabels(svcName, s),
+			Annotations: translateAnnotations(svcName, s),
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       kind,
+				Name:       svcName,
 			},
-		)
+			MinReplicas: pointer.Int32Ptr(autoscaling.Min),
+			MaxReplicas: autoscaling.Max,
+			Metrics:     translateAutoscalingMetrics(autoscaling),
+			Behavior:    translateAutoscalingBehavior(autoscaling),
+		},
 	}
-	return result
 }
 
-func translateSecurityContext(svc *model.Service) *apiv1.SecurityContext {
-	if len(svc.CapAdd) == 0 && len(svc.CapDrop) == 0 {
+//translatePodDisruptionBudget returns the disruption budget of the pods of a service, or nil if 'deploy.pdb' is not set
+func translatePodDisruptionBudget(svcName string, s *model.Stack) *policyv1beta1.PodDisruptionBudget {
+	svc := s.Services[svcName]
+	if svc.Deploy == nil || svc.Deploy.PDB == nil {
 		return nil
 	}
-	result := &apiv1.SecurityContext{Capabilities: &apiv1.Capabilities{}}
-	if len(svc.CapAdd) > 0 {
-		result.Capabilities.Add = svc.CapAdd
+	spec := policyv1beta1.PodDisruptionBudgetSpec{
+		Selector: &metav1.LabelSelector{
+			MatchLabels: translateLabelSelector(svcName, s),
+		},
+	}
+	if svc.Deploy.PDB.MinAvailable != "" {
+		minAvailable := intstr.Parse(svc.Deploy.PDB.MinAvailable)
+		spec.MinAvailable = &minAvailable
+	} else {
+		maxUnavailable := intstr.Parse(svc.Deploy.PDB.MaxUnavailable)
+		spec.MaxUnavailable = &maxUnavailable
+	}
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        svcName,
+			Namespace:   s.Namespace,
+			Labels:      translateLabels(svcName, s),
+			Annotations: translateAnnotations(svcName, s),
+		},
+		Spec: spec,
+	}
+}
+
+func translateAutoscalingBehavior(autoscaling *model.AutoscalingInfo) *autoscalingv2beta2.HorizontalPodAutoscalerBehavior {
+	if autoscaling.Behavior == nil {
+		return nil
+	}
+	return &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+		ScaleUp:   translateAutoscalingRules(autoscaling.Behavior.ScaleUp),
+		ScaleDown: translateAutoscalingRules(autoscaling.Behavior.ScaleDown),
+	}
+}
+
+func translateAutoscalingRules(r *model.AutoscalingRules) *autoscalingv2beta2.HPAScalingRules {
+	if r == nil {
+		return nil
+	}
+	result := &autoscalingv2beta2.HPAScalingRules{}
+	if r.StabilizationWindow != nil {
+		result.StabilizationWindowSeconds = pointer.Int32Ptr(*r.StabilizationWindow)
+	}
+	switch r.SelectPolicy {
+	case "max":
+		selectPolicy := autoscalingv2beta2.MaxPolicySelect
+		result.SelectPolicy = &selectPolicy
+	case "min":
+		selectPolicy := autoscalingv2beta2.MinPolicySelect
+		result.SelectPolicy = &selectPolicy
+	case "disabled":
+		selectPolicy := autoscalingv2beta2.DisabledPolicySelect
+		result.SelectPolicy = &selectPolicy
+	}
+	for _, p := range r.Policies {
+		policyType := autoscalingv2beta2.PodsScalingPolicy
+		if p.Type == model.PercentPolicyType {
+			policyType = autoscalingv2beta2.PercentScalingPolicy
+		}
+		result.Policies = append(result.Policies, autoscalingv2beta2.HPAScalingPolicy{
+			Type:          policyType,
+			Value:         p.Value,
+			PeriodSeconds: p.Period,
+		})
+	}
+	return result
+}
+
+func translateAutoscalingMetrics(autoscaling *model.AutoscalingInfo) []autoscalingv2beta2.MetricSpec {
+	result := []autoscalingv2beta2.MetricSpec{}
+	if autoscaling.CPUPercent > 0 {
+		result = append(result, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: apiv1.ResourceCPU,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.UtilizationMetricType,
+					AverageUtilization: pointer.Int32Ptr(autoscaling.CPUPercent),
+				},
+			},
+		})
+	}
+	for _, m := range autoscaling.Metrics {
+		metric := autoscalingv2beta2.MetricIdentifier{Name: m.Name}
+		if len(m.Selector) > 0 {
+			metric.Selector = &metav1.LabelSelector{MatchLabels: m.Selector}
+		}
+		target := translateAutoscalingMetricTarget(m)
+		switch m.Type {
+		case model.PodsMetricType:
+			result = append(result, autoscalingv2beta2.MetricSpec{
+				Type: autoscalingv2beta2.PodsMetricSourceType,
+				Pods: &autoscalingv2beta2.PodsMetricSource{Metric: metric, Target: target},
+			})
+		case model.ExternalMetricType:
+			result = append(result, autoscalingv2beta2.MetricSpec{
+				Type:     autoscalingv2beta2.ExternalMetricSourceType,
+				External: &autoscalingv2beta2.ExternalMetricSource{Metric: metric, Target: target},
+			})
+		}
+	}
+	return result
+}
+
+func translateAutoscalingMetricTarget(m model.AutoscalingMetric) autoscalingv2beta2.MetricTarget {
+	if !m.Value.Value.IsZero() {
+		value := m.Value.Value.DeepCopy()
+		return autoscalingv2beta2.MetricTarget{
+			Type:  autoscalingv2beta2.ValueMetricType,
+			Value: &value,
+		}
+	}
+	averageValue := m.AverageValue.Value.DeepCopy()
+	return autoscalingv2beta2.MetricTarget{
+		Type:         autoscalingv2beta2.AverageValueMetricType,
+		AverageValue: &averageValue,
+	}
+}
+
+//translateService returns the kubernetes service of a service, or nil if it doesn't publish or expose any port,
+//since kubernetes rejects services without ports. Other services can't reach it by its name then
+func translateService(svcName string, s *model.Stack) *apiv1.Service {
+	svc := s.Services[svcName]
+	if len(svc.GetPorts()) == 0 {
+		return nil
+	}
+	annotations := translateAnnotations(svcName, s)
+	if svc.IsPublished() {
+		if len(svc.Endpoints) == 0 {
+			// the ports of services with 'endpoints' are routed by their own ingresses
+			annotations[okLabels.OktetoAutoIngressAnnotation] = "true"
+			if s.Okteto.AutoIngressClass != "" {
+				annotations[okLabels.IngressClassAnnotation] = s.Okteto.AutoIngressClass
+			}
+		}
+		for k, v := range translateLoadBalancerAnnotations(&svc, s.Provider) {
+			annotations[k] = v
+		}
+	}
+	return &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        svcName,
+			Namespace:   s.Namespace,
+			Labels:      translateLabels(svcName, s),
+			Annotations: annotations,
+		},
+		Spec: apiv1.ServiceSpec{
+			Selector:              translateLabelSelector(svcName, s),
+			Type:                  translateServiceType(&svc),
+			ClusterIP:             translateClusterIP(&svc),
+			Ports:                 translateServicePorts(&svc),
+			SessionAffinity:       svc.SessionAffinity.GetType(),
+			SessionAffinityConfig: translateSessionAffinityConfig(&svc),
+		},
+	}
+}
+
+//translateIngress returns the extensions/v1beta1 ingress of an endpoint, for clusters not serving networking.k8s.io/v1 ingresses
+func translateIngress(ingressName string, s *model.Stack) *extensions.Ingress {
+	endpoints := s.Endpoints[ingressName]
+	annotations := map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"}
+	if s.Okteto.AutoIngressClass != "" {
+		annotations[okLabels.IngressClassAnnotation] = s.Okteto.AutoIngressClass
+	}
+	if s.Okteto.TLSIssuer != "" {
+		annotations[okLabels.CertManagerClusterIssuerAnnotation] = s.Okteto.TLSIssuer
+	}
+	host := s.GetEndpointHost(ingressName)
+	return &extensions.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ingressName,
+			Namespace:   s.Namespace,
+			Labels:      translateIngressLabels(ingressName, s),
+			Annotations: annotations,
+		},
+		Spec: extensions.IngressSpec{
+			TLS: translateIngressTLS(ingressName, host, s),
+			Rules: []extensions.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: extensions.IngressRuleValue{
+						HTTP: &extensions.HTTPIngressRuleValue{
+							Paths: translateEndpoints(endpoints),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+//translateIngressTLS returns the tls section of the ingress of an endpoint, or nil if the endpoint is http only
+func translateIngressTLS(ingressName, host string, s *model.Stack) []extensions.IngressTLS {
+	secretName := s.GetEndpointTLSSecret(ingressName)
+	if secretName == "" {
+		return nil
+	}
+	tls := extensions.IngressTLS{SecretName: secretName}
+	if host != "" {
+		tls.Hosts = []string{host}
+	}
+	return []extensions.IngressTLS{tls}
+}
+
+//translateEndpoints returns the paths of an extensions/v1beta1 ingress
+func translateEndpoints(endpoints []model.Endpoint) []extensions.HTTPIngressPath {
+	paths := make([]extensions.HTTPIngressPath, 0)
+	for _, endpoint := range endpoints {
+		pathType := extensions.PathType(endpoint.GetPathType())
+		path := extensions.HTTPIngressPath{
+			Path:     endpoint.Path,
+			PathType: &pathType,
+			Backend: extensions.IngressBackend{
+				ServiceName: endpoint.Service,
+				ServicePort: intstr.IntOrString{IntVal: endpoint.Port},
+			},
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+//translateIngressV1 returns the networking.k8s.io/v1 ingress of an endpoint.
+//The ingress class is set in 'ingressClassName' instead of the deprecated annotation
+func translateIngressV1(ingressName string, s *model.Stack) *networkingv1.Ingress {
+	endpoints := s.Endpoints[ingressName]
+	annotations := map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"}
+	if s.Okteto.TLSIssuer != "" {
+		annotations[okLabels.CertManagerClusterIssuerAnnotation] = s.Okteto.TLSIssuer
+	}
+	var ingressClassName *string
+	if s.Okteto.AutoIngressClass != "" {
+		ingressClassName = &s.Okteto.AutoIngressClass
+	}
+	host := s.GetEndpointHost(ingressName)
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ingressName,
+			Namespace:   s.Namespace,
+			Labels:      translateIngressLabels(ingressName, s),
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: ingressClassName,
+			TLS:              translateIngressTLSV1(ingressName, host, s),
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: translateEndpointsV1(endpoints),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func translateIngressTLSV1(ingressName, host string, s *model.Stack) []networkingv1.IngressTLS {
+	secretName := s.GetEndpointTLSSecret(ingressName)
+	if secretName == "" {
+		return nil
+	}
+	tls := networkingv1.IngressTLS{SecretName: secretName}
+	if host != "" {
+		tls.Hosts = []string{host}
+	}
+	return []networkingv1.IngressTLS{tls}
+}
+
+//translateEndpointsV1 returns the paths of a networking.k8s.io/v1 ingress, where the path type is required
+func translateEndpointsV1(endpoints []model.Endpoint) []networkingv1.HTTPIngressPath {
+	paths := make([]networkingv1.HTTPIngressPath, 0)
+	for _, endpoint := range endpoints {
+		pathType := networkingv1.PathType(endpoint.GetPathType())
+		path := networkingv1.HTTPIngressPath{
+			Path:     endpoint.Path,
+			PathType: &pathType,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: endpoint.Service,
+					Port: networkingv1.ServiceBackendPort{Number: endpoint.Port},
+				},
+			},
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+//translateLabels returns the labels of the objects of a service, built from its 'deploy.labels'
+func translateLabels(svcName string, s *model.Stack) map[string]string {
+	svc := s.Services[svcName]
+	labels := map[string]string{}
+	if svc.Deploy != nil {
+		for k := range svc.Deploy.Labels {
+			labels[k] = translateTemplateTokens(svc.Deploy.Labels[k], svcName, s)
+		}
+	}
+	for k, v := range translateLabelSelector(svcName, s) {
+		labels[k] = v
+	}
+	return labels
+}
+
+//translatePodLabels returns the labels of the pods of a service, built from its 'labels'
+func translatePodLabels(svcName string, s *model.Stack) map[string]string {
+	svc := s.Services[svcName]
+	labels := map[string]string{}
+	for k := range svc.Labels {
+		labels[k] = translateTemplateTokens(svc.Labels[k], svcName, s)
+	}
+	for k, v := range translateLabelSelector(svcName, s) {
+		labels[k] = v
+	}
+	return labels
+}
+
+func translateIngressLabels(endpointName string, s *model.Stack) map[string]string {
+	labels := map[string]string{
+		okLabels.StackNameLabel:         s.Name,
+		okLabels.StackEndpointNameLabel: endpointName,
+	}
+	return labels
+}
+
+func translateLabelSelector(svcName string, s *model.Stack) map[string]string {
+	labels := map[string]string{
+		okLabels.StackNameLabel:        s.Name,
+		okLabels.StackServiceNameLabel: svcName,
+	}
+	return labels
+}
+
+func translateAnnotations(svcName string, s *model.Stack) map[string]string {
+	svc := s.Services[svcName]
+	result := map[string]string{}
+	for k, v := range svc.Annotations {
+		result[k] = translateTemplateTokens(v, svcName, s)
+	}
+	return result
+}
+
+//translateWorkloadAnnotations returns the annotations of the deployment, statefulset or daemonset of a service, including the Reloader annotation when 'reload' is enabled
+func translateWorkloadAnnotations(svcName string, s *model.Stack) map[string]string {
+	svc := s.Services[svcName]
+	result := translateAnnotations(svcName, s)
+	if svc.Reload {
+		result[okLabels.ReloaderAutoAnnotation] = "true"
+	}
+	return result
+}
+
+func translatePodAnnotations(svcName string, s *model.Stack) map[string]string {
+	svc := s.Services[svcName]
+	result := translateAnnotations(svcName, s)
+	if svc.SourcesChecksum != "" {
+		result[okLabels.StackConfigChecksumAnnotation] = svc.SourcesChecksum
+	}
+	return result
+}
+
+//translateTemplateTokens replaces the '{{.ServiceName}}', '{{.StackName}}' and '{{.Namespace}}' tokens of a label or annotation value
+func translateTemplateTokens(value, svcName string, s *model.Stack) string {
+	if !strings.Contains(value, "{{") {
+		return value
+	}
+	return strings.NewReplacer(
+		serviceNameToken, svcName,
+		stackNameToken, s.Name,
+		namespaceToken, s.Namespace,
+	).Replace(value)
+}
+
+func translateServiceType(svc *model.Service) apiv1.ServiceType {
+	return svc.GetServiceType()
+}
+
+//translateSessionAffinityConfig returns the session sticky time of services with client ip affinity, or nil to use the default of kubernetes
+func translateSessionAffinityConfig(svc *model.Service) *apiv1.SessionAffinityConfig {
+	if svc.SessionAffinity.GetType() != apiv1.ServiceAffinityClientIP || svc.SessionAffinity.Timeout == nil {
+		return nil
+	}
+	timeout := *svc.SessionAffinity.Timeout
+	return &apiv1.SessionAffinityConfig{
+		ClientIP: &apiv1.ClientIPConfig{TimeoutSeconds: &timeout},
+	}
+}
+
+//translateLoadBalancerAnnotations returns the annotations of the cloud provider of the cluster that configure the load balancer of a public service
+func translateLoadBalancerAnnotations(svc *model.Service, provider string) map[string]string {
+	result := map[string]string{}
+	switch provider {
+	case model.ProviderGKE:
+		if svc.LoadBalancer == model.InternalLoadBalancer {
+			result[okLabels.GKELoadBalancerTypeAnnotation] = "Internal"
+		}
+	case model.ProviderEKS:
+		if svc.LoadBalancer == model.InternalLoadBalancer {
+			result[okLabels.AWSLoadBalancerInternalAnnotation] = "true"
+		}
+		if svc.NLB {
+			result[okLabels.AWSLoadBalancerTypeAnnotation] = "nlb"
+		}
+	case model.ProviderAKS:
+		if svc.LoadBalancer == model.InternalLoadBalancer {
+			result[okLabels.AzureLoadBalancerInternalAnnotation] = "true"
+		}
+	}
+	return result
+}
+
+//translateClusterIP returns 'None' for headless services, so their clients do their own load balancing
+func translateClusterIP(svc *model.Service) string {
+	if svc.Deploy != nil && svc.Deploy.EndpointMode == model.DNSRREndpointMode {
+		return apiv1.ClusterIPNone
+	}
+	return ""
+}
+
+func translateVolumeMounts(svc *model.Service) []apiv1.VolumeMount {
+	result := []apiv1.VolumeMount{}
+	for i, v := range svc.Volumes {
+		volume, _ := model.ParseVolume(v)
+		result = append(
+			result,
+			apiv1.VolumeMount{
+				MountPath: volume.MountPath,
+				Name:      pvcName,
+				SubPath:   fmt.Sprintf("data-%d", i),
+				ReadOnly:  volume.ReadOnly,
+			},
+		)
+	}
+	for _, v := range svc.NamedVolumes {
+		result = append(
+			result,
+			apiv1.VolumeMount{
+				MountPath: v.MountPath,
+				Name:      fmt.Sprintf("%s-%s", namedVolumePrefix, v.Name),
+				ReadOnly:  v.ReadOnly,
+			},
+		)
+	}
+	for i, d := range svc.Devices {
+		_, path, _ := model.ParseDevice(d)
+		result = append(
+			result,
+			apiv1.VolumeMount{
+				MountPath: path,
+				Name:      fmt.Sprintf("%s-%d", deviceVolumePrefix, i),
+			},
+		)
+	}
+	for i, t := range svc.Tmpfs {
+		path, _, _ := model.ParseTmpfs(t)
+		result = append(
+			result,
+			apiv1.VolumeMount{
+				MountPath: path,
+				Name:      fmt.Sprintf("%s-%d", tmpfsVolumePrefix, i),
+			},
+		)
+	}
+	if svc.MountManifest != "" {
+		result = append(
+			result,
+			apiv1.VolumeMount{
+				MountPath: svc.MountManifest,
+				Name:      manifestVolumeName,
+				SubPath:   manifestField,
+				ReadOnly:  true,
+			},
+		)
+	}
+	return result
+}
+
+func translateVolumes(svc *model.Service, s *model.Stack) []apiv1.Volume {
+	result := translateNamedVolumes(svc)
+	result = append(result, translateDeviceVolumes(svc)...)
+	result = append(result, translateTmpfsVolumes(svc)...)
+	if svc.MountManifest != "" {
+		result = append(result, translateManifestVolume(s))
+	}
+	return result
+}
+
+//translateManifestVolume returns the volume projecting the stack manifest stored in the stack configmap
+func translateManifestVolume(s *model.Stack) apiv1.Volume {
+	return apiv1.Volume{
+		Name: manifestVolumeName,
+		VolumeSource: apiv1.VolumeSource{
+			ConfigMap: &apiv1.ConfigMapVolumeSource{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: s.GetConfigMapName()},
+				Items: []apiv1.KeyToPath{
+					{Key: manifestField, Path: manifestField},
+				},
+			},
+		},
+	}
+}
+
+//translateNamedVolumes returns the volumes of the shared volume claims mounted by the service, once per named volume
+func translateNamedVolumes(svc *model.Service) []apiv1.Volume {
+	var result []apiv1.Volume
+	added := map[string]bool{}
+	for _, v := range svc.NamedVolumes {
+		if added[v.Name] {
+			continue
+		}
+		added[v.Name] = true
+		result = append(
+			result,
+			apiv1.Volume{
+				Name: fmt.Sprintf("%s-%s", namedVolumePrefix, v.Name),
+				VolumeSource: apiv1.VolumeSource{
+					PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: v.Name},
+				},
+			},
+		)
+	}
+	return result
+}
+
+//translatePersistentVolumeClaim returns the volume claim of a named volume, shared by all the services mounting it
+func translatePersistentVolumeClaim(volumeName string, s *model.Stack) *apiv1.PersistentVolumeClaim {
+	volume := s.Volumes[volumeName]
+	labels := map[string]string{}
+	for k, v := range volume.Labels {
+		labels[k] = v
+	}
+	labels[okLabels.StackNameLabel] = s.Name
+	labels[okLabels.StackVolumeNameLabel] = volumeName
+	accessMode := volume.AccessMode
+	if accessMode == "" {
+		accessMode = apiv1.ReadWriteOnce
+	}
+	pvc := &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      volumeName,
+			Namespace: s.Namespace,
+			Labels:    labels,
+		},
+		Spec: apiv1.PersistentVolumeClaimSpec{
+			AccessModes: []apiv1.PersistentVolumeAccessMode{accessMode},
+			Resources: apiv1.ResourceRequirements{
+				Requests: apiv1.ResourceList{
+					"storage": volume.Size.Value,
+				},
+			},
+		},
+	}
+	if volume.Class != "" {
+		pvc.Spec.StorageClassName = &volume.Class
+	}
+	return pvc
+}
+
+//translateDeviceVolumes returns the host path volumes backing the devices of the service
+func translateDeviceVolumes(svc *model.Service) []apiv1.Volume {
+	var result []apiv1.Volume
+	for i, d := range svc.Devices {
+		path, _, _ := model.ParseDevice(d)
+		result = append(
+			result,
+			apiv1.Volume{
+				Name: fmt.Sprintf("%s-%d", deviceVolumePrefix, i),
+				VolumeSource: apiv1.VolumeSource{
+					HostPath: &apiv1.HostPathVolumeSource{Path: path},
+				},
+			},
+		)
+	}
+	return result
+}
+
+//translateTmpfsVolumes returns the in-memory volumes backing the tmpfs mounts of the service
+func translateTmpfsVolumes(svc *model.Service) []apiv1.Volume {
+	var result []apiv1.Volume
+	for i, t := range svc.Tmpfs {
+		_, size, _ := model.ParseTmpfs(t)
+		emptyDir := &apiv1.EmptyDirVolumeSource{Medium: apiv1.StorageMediumMemory}
+		if !size.Value.IsZero() {
+			sizeLimit := size.Value.DeepCopy()
+			emptyDir.SizeLimit = &sizeLimit
+		}
+		result = append(
+			result,
+			apiv1.Volume{
+				Name:         fmt.Sprintf("%s-%d", tmpfsVolumePrefix, i),
+				VolumeSource: apiv1.VolumeSource{EmptyDir: emptyDir},
+			},
+		)
+	}
+	return result
+}
+
+//translateTerminationGracePeriod returns 'stop_grace_period', or the kubernetes default when it is not set
+func translateTerminationGracePeriod(svc *model.Service) *int64 {
+	if svc.StopGracePeriod != nil {
+		return pointer.Int64Ptr(*svc.StopGracePeriod)
+	}
+	return pointer.Int64Ptr(defaultTerminationGracePeriod)
+}
+
+//translateJobRestartPolicy returns the closest policy to 'restart' supported by jobs: 'no' never restarts the job containers,
+//and any other value restarts them on failure
+func translateJobRestartPolicy(svc *model.Service) apiv1.RestartPolicy {
+	if svc.Restart == model.RestartNo {
+		return apiv1.RestartPolicyNever
+	}
+	return apiv1.RestartPolicyOnFailure
+}
+
+//translateHealthCheckProbe returns the probe defined by 'healthcheck', or a tcp probe on the first tcp port of the service when 'healthchecks' is enabled
+func translateHealthCheckProbe(svc *model.Service) *apiv1.Probe {
+	if svc.Healthcheck == nil {
+		if !svc.Healthchecks {
+			return nil
+		}
+		for _, p := range svc.GetPorts() {
+			if p.GetProtocol() != apiv1.ProtocolTCP {
+				continue
+			}
+			return &apiv1.Probe{
+				Handler: apiv1.Handler{
+					TCPSocket: &apiv1.TCPSocketAction{Port: intstr.IntOrString{IntVal: p.ContainerPort}},
+				},
+			}
+		}
+		return nil
+	}
+
+	h := svc.Healthcheck
+	if h.HTTP == nil && h.Test.IsDisabled() {
+		return nil
+	}
+	result := &apiv1.Probe{
+		InitialDelaySeconds: int32(h.StartPeriod / time.Second),
+		PeriodSeconds:       int32(h.Interval / time.Second),
+		TimeoutSeconds:      int32(h.Timeout / time.Second),
+		FailureThreshold:    h.Retries,
+	}
+	if h.HTTP != nil {
+		result.HTTPGet = &apiv1.HTTPGetAction{
+			Path: h.HTTP.Path,
+			Port: intstr.IntOrString{IntVal: h.HTTP.Port},
+		}
+		return result
+	}
+	result.Exec = &apiv1.ExecAction{Command: h.Test.GetCommand()}
+	return result
+}
+
+//translateImagePullPolicy avoids re-pulling the mutable tags of the images built by okteto, unless 'pull_policy' is set
+func translateImagePullPolicy(svc *model.Service) apiv1.PullPolicy {
+	switch svc.PullPolicy {
+	case "always":
+		return apiv1.PullAlways
+	case "never":
+		return apiv1.PullNever
+	case "missing", "if_not_present":
+		return apiv1.PullIfNotPresent
+	}
+	if svc.Build != nil && strings.HasPrefix(svc.Image, "okteto.dev/") {
+		return apiv1.PullIfNotPresent
+	}
+	return ""
+}
+
+func translateSecurityContext(svc *model.Service) *apiv1.SecurityContext {
+	if len(svc.CapAdd) == 0 && len(svc.CapDrop) == 0 && !svc.Privileged {
+		return nil
+	}
+	result := &apiv1.SecurityContext{}
+	if svc.Privileged {
+		result.Privileged = pointer.BoolPtr(true)
+	}
+	if len(svc.CapAdd) > 0 || len(svc.CapDrop) > 0 {
+		result.Capabilities = &apiv1.Capabilities{}
+	}
+	if len(svc.CapAdd) > 0 {
+		result.Capabilities.Add = svc.CapAdd
 	}
 	if len(svc.CapDrop) > 0 {
 		result.Capabilities.Drop = svc.CapDrop
@@ -418,6 +1500,32 @@ func translateSecurityContext(svc *model.Service) *apiv1.SecurityContext {
 	return result
 }
 
+//translatePodSecurityContext returns the user and group running the containers of the service, and the group owning its volumes
+func translatePodSecurityContext(svc *model.Service) *apiv1.PodSecurityContext {
+	uid, gid, _ := model.ParseUser(svc.User)
+	if uid == nil {
+		return nil
+	}
+	return &apiv1.PodSecurityContext{
+		RunAsUser:  uid,
+		RunAsGroup: gid,
+		FSGroup:    gid,
+	}
+}
+
+//translateStorageSize returns 'resources.requests.storage.size', or the size set inline in the service volume
+func translateStorageSize(svc *model.Service) resource.Quantity {
+	if !svc.Resources.Requests.Storage.Size.Value.IsZero() {
+		return svc.Resources.Requests.Storage.Size.Value
+	}
+	for _, v := range svc.Volumes {
+		if volume, err := model.ParseVolume(v); err == nil && !volume.Size.Value.IsZero() {
+			return volume.Size.Value
+		}
+	}
+	return svc.Resources.Requests.Storage.Size.Value
+}
+
 func translateStorageClass(svc *model.Service) *string {
 	if svc.Resources.Requests.Storage.Class != "" {
 		return &svc.Resources.Requests.Storage.Class
@@ -425,37 +1533,111 @@ func translateStorageClass(svc *model.Service) *string {
 	return nil
 }
 
+func translateStorageAccessMode(svc *model.Service) apiv1.PersistentVolumeAccessMode {
+	if svc.Resources.Requests.Storage.AccessMode != "" {
+		return svc.Resources.Requests.Storage.AccessMode
+	}
+	return apiv1.ReadWriteOnce
+}
+
 func translateServiceEnvironment(svc *model.Service) []apiv1.EnvVar {
 	result := []apiv1.EnvVar{}
 	for _, e := range svc.Environment {
-		result = append(result, apiv1.EnvVar{Name: e.Name, Value: e.Value})
+		result = append(result, apiv1.EnvVar{Name: e.Name, Value: e.Value, ValueFrom: translateEnvVarSource(e.ValueFrom)})
+	}
+	return result
+}
+
+func translateEnvVarSource(source *model.EnvVarSource) *apiv1.EnvVarSource {
+	if source == nil {
+		return nil
+	}
+	result := &apiv1.EnvVarSource{}
+	if ref := source.SecretKeyRef; ref != nil {
+		result.SecretKeyRef = &apiv1.SecretKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{Name: ref.Name},
+			Key:                  ref.Key,
+			Optional:             translateEnvSourceOptional(ref.Optional),
+		}
+	}
+	if ref := source.ConfigMapKeyRef; ref != nil {
+		result.ConfigMapKeyRef = &apiv1.ConfigMapKeySelector{
+			LocalObjectReference: apiv1.LocalObjectReference{Name: ref.Name},
+			Key:                  ref.Key,
+			Optional:             translateEnvSourceOptional(ref.Optional),
+		}
 	}
 	return result
 }
 
+//translateServiceEnvFrom returns the secrets and configmaps loaded as environment variables, or nil so services without them keep their current spec
+func translateServiceEnvFrom(svc *model.Service) []apiv1.EnvFromSource {
+	var result []apiv1.EnvFromSource
+	for _, source := range svc.EnvFrom {
+		envFrom := apiv1.EnvFromSource{}
+		if ref := source.SecretRef; ref != nil {
+			envFrom.SecretRef = &apiv1.SecretEnvSource{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: ref.Name},
+				Optional:             translateEnvSourceOptional(ref.Optional),
+			}
+		}
+		if ref := source.ConfigMapRef; ref != nil {
+			envFrom.ConfigMapRef = &apiv1.ConfigMapEnvSource{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: ref.Name},
+				Optional:             translateEnvSourceOptional(ref.Optional),
+			}
+		}
+		result = append(result, envFrom)
+	}
+	return result
+}
+
+func translateEnvSourceOptional(optional bool) *bool {
+	if !optional {
+		return nil
+	}
+	return &optional
+}
+
 func translateContainerPorts(svc *model.Service) []apiv1.ContainerPort {
 	result := []apiv1.ContainerPort{}
-	for _, p := range svc.Ports {
-		result = append(result, apiv1.ContainerPort{ContainerPort: p})
+	seen := map[apiv1.ContainerPort]bool{}
+	for _, p := range svc.GetPorts() {
+		port := apiv1.ContainerPort{ContainerPort: p.ContainerPort, Protocol: p.GetProtocol()}
+		if seen[port] {
+			continue
+		}
+		seen[port] = true
+		result = append(result, port)
 	}
 	return result
 }
 
 func translateServicePorts(svc *model.Service) []apiv1.ServicePort {
 	result := []apiv1.ServicePort{}
-	for _, p := range svc.Ports {
+	for _, p := range svc.GetPorts() {
 		result = append(
 			result,
 			apiv1.ServicePort{
-				Name:       fmt.Sprintf("p-%d", p),
-				Port:       int32(p),
-				TargetPort: intstr.IntOrString{IntVal: p},
+				Name:       translateServicePortName(p),
+				Port:       p.Port,
+				TargetPort: intstr.IntOrString{IntVal: p.ContainerPort},
+				Protocol:   p.GetProtocol(),
+				NodePort:   p.NodePort,
 			},
 		)
 	}
 	return result
 }
 
+//translateServicePortName keeps the 'p-PORT' name of tcp ports, and adds the protocol to the others so tcp and udp ports sharing a number have unique names
+func translateServicePortName(p model.Port) string {
+	if p.GetProtocol() == apiv1.ProtocolTCP {
+		return fmt.Sprintf("p-%d", p.Port)
+	}
+	return fmt.Sprintf("p-%d-%s", p.Port, strings.ToLower(string(p.Protocol)))
+}
+
 func translateResources(svc *model.Service) apiv1.ResourceRequirements {
 	result := apiv1.ResourceRequirements{}
 	if svc.Resources.Limits.CPU.Value.Cmp(resource.MustParse("0")) > 0 {
@@ -468,16 +1650,59 @@ func translateResources(svc *model.Service) apiv1.ResourceRequirements {
 		}
 		result.Limits[apiv1.ResourceMemory] = svc.Resources.Limits.Memory.Value
 	}
+	if svc.Resources.Limits.EphemeralStorage.Value.Cmp(resource.MustParse("0")) > 0 {
+		if result.Limits == nil {
+			result.Limits = apiv1.ResourceList{}
+		}
+		result.Limits[apiv1.ResourceEphemeralStorage] = svc.Resources.Limits.EphemeralStorage.Value
+	}
 
 	if svc.Resources.Requests.CPU.Value.Cmp(resource.MustParse("0")) > 0 {
-		result.Limits = apiv1.ResourceList{}
-		result.Limits[apiv1.ResourceCPU] = svc.Resources.Requests.CPU.Value
+		if result.Requests == nil {
+			result.Requests = apiv1.ResourceList{}
+		}
+		result.Requests[apiv1.ResourceCPU] = svc.Resources.Requests.CPU.Value
 	}
 	if svc.Resources.Requests.Memory.Value.Cmp(resource.MustParse("0")) > 0 {
+		if result.Requests == nil {
+			result.Requests = apiv1.ResourceList{}
+		}
+		result.Requests[apiv1.ResourceMemory] = svc.Resources.Requests.Memory.Value
+	}
+	if svc.Resources.Requests.EphemeralStorage.Value.Cmp(resource.MustParse("0")) > 0 {
+		if result.Requests == nil {
+			result.Requests = apiv1.ResourceList{}
+		}
+		result.Requests[apiv1.ResourceEphemeralStorage] = svc.Resources.Requests.EphemeralStorage.Value
+	}
+
+	if svc.HasGuaranteedResources() {
+		if result.Requests == nil {
+			result.Requests = apiv1.ResourceList{}
+		}
+		result.Requests[apiv1.ResourceCPU] = svc.Resources.Limits.CPU.Value
+		result.Requests[apiv1.ResourceMemory] = svc.Resources.Limits.Memory.Value
+	}
+
+	if svc.GPU != nil {
+		// extended resources can't be overcommitted, so their requests must match their limits
+		gpus := *resource.NewQuantity(svc.GPU.GetCount(), resource.DecimalSI)
 		if result.Limits == nil {
 			result.Limits = apiv1.ResourceList{}
 		}
-		result.Limits[apiv1.ResourceMemory] = svc.Resources.Requests.Memory.Value
+		if result.Requests == nil {
+			result.Requests = apiv1.ResourceList{}
+		}
+		result.Limits[svc.GPU.GetResourceName()] = gpus
+		result.Requests[svc.GPU.GetResourceName()] = gpus
 	}
 	return result
 }
+
+//translateNodeSelector schedules the pods of a service on the nodes providing its gpu profile
+func translateNodeSelector(svc *model.Service) map[string]string {
+	if svc.GPU == nil {
+		return nil
+	}
+	return svc.GPU.GetNodeSelector()
+}
@@ -19,14 +19,23 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	okLabels "github.com/okteto/okteto/pkg/k8s/labels"
 	"github.com/okteto/okteto/pkg/model"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	apiv1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/pointer"
 
 	extensions "k8s.io/api/extensions/v1beta1"
@@ -53,11 +62,57 @@ func Test_translate(t *testing.T) {
 			},
 		},
 	}
-	if err := translate(ctx, stack, false, false); err == nil {
+	if _, err := translate(ctx, stack, fake.NewSimpleClientset(), &DeployOptions{}); err == nil {
 		t.Fatalf("An error should be returned")
 	}
 }
 
+func Test_translateConcurrent(t *testing.T) {
+	os.Setenv("BUILDKIT_HOST", "tcp://buildkit:1234")
+	defer os.Unsetenv("BUILDKIT_HOST")
+
+	ctx := context.Background()
+	stack := &model.Stack{
+		Name: "name",
+		Services: map[string]model.Service{
+			"api": {
+				Image:       "okteto/api:1",
+				Annotations: map[string]string{"team": "api"},
+				Environment: []model.EnvVar{{Name: "PORT", Value: "8080"}},
+				Ports:       []model.Port{{Port: 8080, ContainerPort: 8080}},
+			},
+			"worker": {
+				Image:   "okteto/worker:1",
+				Command: model.Command{Values: []string{"worker"}},
+			},
+		},
+	}
+	original := stack.DeepCopy()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			translated, err := translate(ctx, stack, fake.NewSimpleClientset(), &DeployOptions{ForceRecreatePods: true})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			for name, svc := range translated.Services {
+				svc.SetRestartedAtAnnotation()
+				translated.Services[name] = svc
+				translateDeployment(name, translated)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !reflect.DeepEqual(stack, original) {
+		t.Errorf("translate modified the stack: %+v", stack)
+	}
+}
+
 func Test_translateEnvVars(t *testing.T) {
 	tmpFile, err := ioutil.TempFile("", ".env")
 	if err != nil {
@@ -68,14 +123,13 @@ func Test_translateEnvVars(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpFile.Name())
 
-	os.Setenv("IMAGE", "image")
 	os.Setenv("B", "2")
 	os.Setenv("ENV_PATH", tmpFile.Name())
 	stack := &model.Stack{
 		Name: "name",
 		Services: map[string]model.Service{
 			"1": {
-				Image:    "${IMAGE}",
+				Image:    "image",
 				EnvFiles: []string{"${ENV_PATH}"},
 				Environment: []model.EnvVar{
 					{
@@ -87,9 +141,6 @@ func Test_translateEnvVars(t *testing.T) {
 		},
 	}
 	translateStackEnvVars(stack)
-	if stack.Services["1"].Image != "image" {
-		t.Errorf("Wrong image: %s", stack.Services["1"].Image)
-	}
 	if len(stack.Services["1"].Environment) != 3 {
 		t.Errorf("Wrong environment: %v", stack.Services["1"].Environment)
 	}
@@ -106,6 +157,302 @@ func Test_translateEnvVars(t *testing.T) {
 	}
 }
 
+func Test_translateBuildImage(t *testing.T) {
+	os.Setenv("REGISTRY", "registry.example.com")
+	os.Setenv("TAG", "1.0")
+	os.Setenv("APP", "app")
+	tests := []struct {
+		name            string
+		image           string
+		isOktetoCluster bool
+		expected        string
+	}{
+		{name: "templated-external-registry", image: "${REGISTRY}/app:${TAG}", isOktetoCluster: true, expected: "registry.example.com/app:1.0"},
+		{name: "templated-okteto-registry", image: "okteto.dev/app:${TAG}", isOktetoCluster: true, expected: "okteto.dev/app:1.0"},
+		{name: "templated-without-registry", image: "${APP}:${TAG}", isOktetoCluster: true, expected: "okteto.dev/stackName-svcName:okteto"},
+		{name: "external-registry", image: "registry.example.com/app:1.0", isOktetoCluster: true, expected: "okteto.dev/stackName-svcName:okteto"},
+		{name: "empty", isOktetoCluster: true, expected: "okteto.dev/stackName-svcName:okteto"},
+		{name: "templated-not-okteto-cluster", image: "${APP}:${TAG}", expected: "app:1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &model.Service{Image: tt.image, Build: &model.BuildInfo{Context: "."}}
+			result, err := translateBuildImage("stackName", "svcName", svc, tt.isOktetoCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("Wrong image: '%s'", result)
+			}
+		})
+	}
+}
+
+func Test_buildServiceCacheFrom(t *testing.T) {
+	tests := []struct {
+		name            string
+		image           string
+		cacheFrom       []string
+		isOktetoCluster bool
+		noCache         bool
+		expected        []string
+	}{
+		{name: "okteto-cluster", image: "okteto.dev/stackName-svcName:okteto", isOktetoCluster: true, expected: []string{"okteto.dev/stackName-svcName:okteto"}},
+		{name: "okteto-cluster-explicit", image: "okteto.dev/stackName-svcName:okteto", cacheFrom: []string{"okteto.dev/base:latest"}, isOktetoCluster: true, expected: []string{"okteto.dev/base:latest"}},
+		{name: "okteto-cluster-no-cache", image: "okteto.dev/stackName-svcName:okteto", isOktetoCluster: true, noCache: true},
+		{name: "okteto-cluster-external-registry", image: "registry.example.com/app:1.0", isOktetoCluster: true},
+		{name: "not-okteto-cluster", image: "registry.example.com/app:1.0"},
+		{name: "not-okteto-cluster-explicit", image: "registry.example.com/app:1.0", cacheFrom: []string{"registry.example.com/app:cache"}, expected: []string{"registry.example.com/app:cache"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &model.Service{Image: tt.image, Build: &model.BuildInfo{Context: ".", CacheFrom: tt.cacheFrom}}
+			var cacheFrom []string
+			run := func(ctx context.Context, namespace, buildKitHost string, isOktetoCluster bool, path, dockerFile, tag, target, network string, noCache bool, cacheFromArg, buildArgs, secrets []string, progress string) (string, error) {
+				if tag != tt.image {
+					t.Errorf("Wrong tag: '%s'", tag)
+				}
+				cacheFrom = cacheFromArg
+				return "", nil
+			}
+			if _, err := buildService(context.Background(), "namespace", svc, "buildkit", tt.isOktetoCluster, tt.noCache, run); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cacheFrom, tt.expected) {
+				t.Errorf("Wrong cache from: '%v'", cacheFrom)
+			}
+		})
+	}
+}
+
+func Test_buildServiceCacheFromManifest(t *testing.T) {
+	manifest := []byte(`name: stackName
+services:
+  svcName:
+    image: okteto.dev/stackName-svcName:okteto
+    build:
+      context: .
+      cache_from:
+        - okteto.dev/base:latest
+        - okteto.dev/base:cache`)
+	s, err := model.ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := s.Services["svcName"]
+	expected := []string{"okteto.dev/base:latest", "okteto.dev/base:cache"}
+	if cacheFrom := translateBuildCacheFrom(&svc, true, false); !reflect.DeepEqual(cacheFrom, expected) {
+		t.Errorf("Wrong cache from: '%v'", cacheFrom)
+	}
+}
+
+func newPlatformNode(name, arch string) *apiv1.Node {
+	return &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: apiv1.NodeStatus{
+			NodeInfo: apiv1.NodeSystemInfo{OperatingSystem: "linux", Architecture: arch},
+		},
+	}
+}
+
+func Test_translatePlatformOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   []runtime.Object
+		command []string
+		args    []string
+	}{
+		{
+			name:    "amd64",
+			nodes:   []runtime.Object{newPlatformNode("node1", "amd64"), newPlatformNode("node2", "amd64")},
+			command: []string{"app"},
+			args:    []string{"serve"},
+		},
+		{
+			name:    "arm64",
+			nodes:   []runtime.Object{newPlatformNode("node1", "arm64")},
+			command: []string{"qemu-wrapper", "app"},
+			args:    []string{"serve"},
+		},
+		{
+			name:    "platform-without-override",
+			nodes:   []runtime.Object{newPlatformNode("node1", "s390x")},
+			command: []string{"app"},
+			args:    []string{"serve"},
+		},
+		{
+			name:    "mixed-platforms",
+			nodes:   []runtime.Object{newPlatformNode("node1", "amd64"), newPlatformNode("node2", "arm64")},
+			command: []string{"app"},
+			args:    []string{"serve"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name: "stackName",
+				Services: map[string]model.Service{
+					"svcName": {
+						Image:   "image",
+						Command: model.Command{Values: []string{"app"}},
+						Args:    model.Args{Values: []string{"serve"}},
+						Platforms: map[string]model.PlatformOverride{
+							"linux/arm64": {Command: model.Command{Values: []string{"qemu-wrapper", "app"}}},
+						},
+					},
+				},
+			}
+			translatePlatformOverrides(context.Background(), s, fake.NewSimpleClientset(tt.nodes...))
+			svc := s.Services["svcName"]
+			if !reflect.DeepEqual(svc.Command.Values, tt.command) {
+				t.Errorf("Wrong command: '%v'", svc.Command.Values)
+			}
+			if !reflect.DeepEqual(svc.Args.Values, tt.args) {
+				t.Errorf("Wrong args: '%v'", svc.Args.Values)
+			}
+		})
+	}
+}
+
+func newProviderNode(name, providerID string) *apiv1.Node {
+	return &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       apiv1.NodeSpec{ProviderID: providerID},
+	}
+}
+
+func Test_translateLoadBalancerAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     *apiv1.Node
+		svc      model.Service
+		expected map[string]string
+	}{
+		{
+			name:     "gke-internal",
+			node:     newProviderNode("node1", "gce://project/us-central1-a/node1"),
+			svc:      model.Service{Public: true, LoadBalancer: model.InternalLoadBalancer},
+			expected: map[string]string{okLabels.GKELoadBalancerTypeAnnotation: "Internal"},
+		},
+		{
+			name:     "gke-external",
+			node:     newProviderNode("node1", "gce://project/us-central1-a/node1"),
+			svc:      model.Service{Public: true, LoadBalancer: model.ExternalLoadBalancer},
+			expected: map[string]string{},
+		},
+		{
+			name:     "eks-nlb",
+			node:     newProviderNode("node1", "aws:///us-east-1a/i-0123456789"),
+			svc:      model.Service{Public: true, NLB: true},
+			expected: map[string]string{okLabels.AWSLoadBalancerTypeAnnotation: "nlb"},
+		},
+		{
+			name: "eks-internal-nlb",
+			node: newProviderNode("node1", "aws:///us-east-1a/i-0123456789"),
+			svc:  model.Service{Public: true, LoadBalancer: model.InternalLoadBalancer, NLB: true},
+			expected: map[string]string{
+				okLabels.AWSLoadBalancerInternalAnnotation: "true",
+				okLabels.AWSLoadBalancerTypeAnnotation:     "nlb",
+			},
+		},
+		{
+			name:     "aks-internal",
+			node:     newProviderNode("node1", "azure:///subscriptions/id/node1"),
+			svc:      model.Service{Public: true, LoadBalancer: model.InternalLoadBalancer},
+			expected: map[string]string{okLabels.AzureLoadBalancerInternalAnnotation: "true"},
+		},
+		{
+			name:     "unknown-provider",
+			node:     newProviderNode("node1", "kind://docker/kind/node1"),
+			svc:      model.Service{Public: true, LoadBalancer: model.InternalLoadBalancer, NLB: true},
+			expected: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.svc.Image = "image"
+			tt.svc.Ports = []model.Port{{Port: 80, ContainerPort: 8080}}
+			s := &model.Stack{
+				Name:     "stackName",
+				Services: map[string]model.Service{"svcName": tt.svc},
+			}
+			translateProvider(context.Background(), s, fake.NewSimpleClientset(tt.node))
+			svc := s.Services["svcName"]
+			if result := translateLoadBalancerAnnotations(&svc, s.Provider); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Wrong load balancer annotations: '%v'", result)
+			}
+			annotations := translateService("svcName", s).Annotations
+			for k, v := range tt.expected {
+				if annotations[k] != v {
+					t.Errorf("Wrong service annotation '%s': '%s'", k, annotations[k])
+				}
+			}
+		})
+	}
+}
+
+func Test_translateImageDigest(t *testing.T) {
+	digest := "sha256:0123456789abcdef"
+	svc := &model.Service{
+		Image:   "okteto.dev/stack-operator:okteto",
+		Command: model.Command{Values: []string{"operator", "--image=okteto/operator@${OKTETO_IMAGE_DIGEST}"}},
+		Args:    model.Args{Values: []string{"--digest", "${OKTETO_IMAGE_DIGEST}"}},
+		Environment: []model.EnvVar{
+			{Name: "IMAGE_DIGEST", Value: "${OKTETO_IMAGE_DIGEST}"},
+			{Name: "OTHER", Value: "${OTHER}"},
+		},
+	}
+	translateImageDigest(svc, digest)
+	if !reflect.DeepEqual(svc.Command.Values, []string{"operator", "--image=okteto/operator@sha256:0123456789abcdef"}) {
+		t.Errorf("Wrong command: '%v'", svc.Command.Values)
+	}
+	if !reflect.DeepEqual(svc.Args.Values, []string{"--digest", digest}) {
+		t.Errorf("Wrong args: '%v'", svc.Args.Values)
+	}
+	env := model.Environment{{Name: "IMAGE_DIGEST", Value: digest}, {Name: "OTHER", Value: "${OTHER}"}}
+	if !reflect.DeepEqual(svc.Environment, env) {
+		t.Errorf("Wrong environment: '%v'", svc.Environment)
+	}
+}
+
+func Test_translateImageDigestManifest(t *testing.T) {
+	digest := "sha256:0123456789abcdef"
+	envPath := filepath.Join(t.TempDir(), ".env")
+	if err := ioutil.WriteFile(envPath, []byte("FILE_IMAGE=okteto/operator@${OKTETO_IMAGE_DIGEST}\n"), 0600); err != nil {
+		t.Fatalf("failed to write env file: %s", err.Error())
+	}
+	os.Setenv("OKTETO_TEST_ENV_PATH", envPath)
+	defer os.Unsetenv("OKTETO_TEST_ENV_PATH")
+
+	manifest := []byte(`name: stackName
+services:
+  operator:
+    build: .
+    image: okteto.dev/stack-operator:okteto
+    env_file:
+      - ${OKTETO_TEST_ENV_PATH}
+    environment:
+      - IMAGE=okteto/operator@${OKTETO_IMAGE_DIGEST}
+      - ESCAPED=$${OKTETO_IMAGE_DIGEST}`)
+	s, err := model.ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := translateStackEnvVars(s); err != nil {
+		t.Fatal(err)
+	}
+	svc := s.Services["operator"]
+	translateImageDigest(&svc, digest)
+	env := model.Environment{
+		{Name: "ESCAPED", Value: digest},
+		{Name: "FILE_IMAGE", Value: "okteto/operator@" + digest},
+		{Name: "IMAGE", Value: "okteto/operator@" + digest},
+	}
+	if !reflect.DeepEqual(svc.Environment, env) {
+		t.Errorf("Wrong environment: '%v'", svc.Environment)
+	}
+}
+
 func Test_translateConfigMap(t *testing.T) {
 	s := &model.Stack{
 		Manifest: []byte("manifest"),
@@ -131,6 +478,39 @@ func Test_translateConfigMap(t *testing.T) {
 	}
 }
 
+func Test_translateConfigMapLabels(t *testing.T) {
+	s := &model.Stack{
+		Manifest: []byte("manifest"),
+		Name:     "stackName",
+		Labels: map[string]string{
+			"team":              "backend",
+			okLabels.StackLabel: "false",
+		},
+		Annotations: map[string]string{
+			"argocd.argoproj.io/tracking-id": "stackName",
+		},
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+			},
+		},
+	}
+	result := translateConfigMap(s)
+	labels := map[string]string{
+		"team":              "backend",
+		okLabels.StackLabel: "true",
+	}
+	if !reflect.DeepEqual(result.Labels, labels) {
+		t.Errorf("Wrong labels: '%s'", result.Labels)
+	}
+	annotations := map[string]string{
+		"argocd.argoproj.io/tracking-id": "stackName",
+	}
+	if !reflect.DeepEqual(result.Annotations, annotations) {
+		t.Errorf("Wrong annotations: '%s'", result.Annotations)
+	}
+}
+
 func Test_translateDeployment(t *testing.T) {
 	s := &model.Stack{
 		Name: "stackName",
@@ -144,9 +524,14 @@ func Test_translateDeployment(t *testing.T) {
 					"annotation1": "value1",
 					"annotation2": "value2",
 				},
+				Deploy: &model.DeployInfo{
+					Labels: map[string]string{
+						"deploy-label1": "value1",
+					},
+				},
 				Image:           "image",
 				Replicas:        3,
-				StopGracePeriod: 20,
+				StopGracePeriod: pointer.Int64Ptr(20),
 				Command:         model.Command{Values: []string{"command1", "command2"}},
 				Args:            model.Args{Values: []string{"args1", "args2"}},
 				Environment: []model.EnvVar{
@@ -159,7 +544,7 @@ func Test_translateDeployment(t *testing.T) {
 						Value: "value2",
 					},
 				},
-				Ports: []int32{80, 90},
+				Ports: []model.Port{{Port: 80, ContainerPort: 80}, {Port: 90, ContainerPort: 90}},
 			},
 		},
 	}
@@ -168,8 +553,7 @@ func Test_translateDeployment(t *testing.T) {
 		t.Errorf("Wrong deployment name: '%s'", result.Name)
 	}
 	labels := map[string]string{
-		"label1":                       "value1",
-		"label2":                       "value2",
+		"deploy-label1":                "value1",
 		okLabels.StackNameLabel:        "stackName",
 		okLabels.StackServiceNameLabel: "svcName",
 	}
@@ -193,10 +577,20 @@ func Test_translateDeployment(t *testing.T) {
 	if !reflect.DeepEqual(result.Spec.Selector.MatchLabels, selector) {
 		t.Errorf("Wrong spec.selector: '%s'", result.Spec.Selector.MatchLabels)
 	}
-	if !reflect.DeepEqual(result.Spec.Template.Labels, labels) {
+	podLabels := map[string]string{
+		"label1":                       "value1",
+		"label2":                       "value2",
+		okLabels.StackNameLabel:        "stackName",
+		okLabels.StackServiceNameLabel: "svcName",
+	}
+	if !reflect.DeepEqual(result.Spec.Template.Labels, podLabels) {
 		t.Errorf("Wrong spec.template.labels: '%s'", result.Spec.Template.Labels)
 	}
-	if !reflect.DeepEqual(result.Spec.Template.Annotations, annotations) {
+	podAnnotations := map[string]string{
+		"annotation1": "value1",
+		"annotation2": "value2",
+	}
+	if !reflect.DeepEqual(result.Spec.Template.Annotations, podAnnotations) {
 		t.Errorf("Wrong spec.template.annotations: '%s'", result.Spec.Template.Annotations)
 	}
 	if *result.Spec.Template.Spec.TerminationGracePeriodSeconds != 20 {
@@ -219,7 +613,7 @@ func Test_translateDeployment(t *testing.T) {
 	if !reflect.DeepEqual(c.Env, env) {
 		t.Errorf("Wrong container.env: '%v'", c.Env)
 	}
-	ports := []apiv1.ContainerPort{{ContainerPort: 80}, {ContainerPort: 90}}
+	ports := []apiv1.ContainerPort{{ContainerPort: 80, Protocol: apiv1.ProtocolTCP}, {ContainerPort: 90, Protocol: apiv1.ProtocolTCP}}
 	if !reflect.DeepEqual(c.Ports, ports) {
 		t.Errorf("Wrong container.ports: '%v'", c.Ports)
 	}
@@ -244,9 +638,14 @@ func Test_translateStatefulSet(t *testing.T) {
 					"annotation1": "value1",
 					"annotation2": "value2",
 				},
+				Deploy: &model.DeployInfo{
+					Labels: map[string]string{
+						"deploy-label1": "value1",
+					},
+				},
 				Image:           "image",
 				Replicas:        3,
-				StopGracePeriod: 20,
+				StopGracePeriod: pointer.Int64Ptr(20),
 				Command:         model.Command{Values: []string{"command1", "command2"}},
 				Args:            model.Args{Values: []string{"args1", "args2"}},
 				Environment: []model.EnvVar{
@@ -259,7 +658,7 @@ func Test_translateStatefulSet(t *testing.T) {
 						Value: "value2",
 					},
 				},
-				Ports:   []int32{80, 90},
+				Ports:   []model.Port{{Port: 80, ContainerPort: 80}, {Port: 90, ContainerPort: 90}},
 				CapAdd:  []apiv1.Capability{apiv1.Capability("CAP_ADD")},
 				CapDrop: []apiv1.Capability{apiv1.Capability("CAP_DROP")},
 				Volumes: []string{"/volume1", "/volume2"},
@@ -283,8 +682,7 @@ func Test_translateStatefulSet(t *testing.T) {
 		t.Errorf("Wrong statefulset name: '%s'", result.Name)
 	}
 	labels := map[string]string{
-		"label1":                       "value1",
-		"label2":                       "value2",
+		"deploy-label1":                "value1",
 		okLabels.StackNameLabel:        "stackName",
 		okLabels.StackServiceNameLabel: "svcName",
 	}
@@ -308,10 +706,20 @@ func Test_translateStatefulSet(t *testing.T) {
 	if !reflect.DeepEqual(result.Spec.Selector.MatchLabels, selector) {
 		t.Errorf("Wrong spec.selector: '%s'", result.Spec.Selector.MatchLabels)
 	}
-	if !reflect.DeepEqual(result.Spec.Template.Labels, labels) {
+	podLabels := map[string]string{
+		"label1":                       "value1",
+		"label2":                       "value2",
+		okLabels.StackNameLabel:        "stackName",
+		okLabels.StackServiceNameLabel: "svcName",
+	}
+	if !reflect.DeepEqual(result.Spec.Template.Labels, podLabels) {
 		t.Errorf("Wrong spec.template.labels: '%s'", result.Spec.Template.Labels)
 	}
-	if !reflect.DeepEqual(result.Spec.Template.Annotations, annotations) {
+	podAnnotations := map[string]string{
+		"annotation1": "value1",
+		"annotation2": "value2",
+	}
+	if !reflect.DeepEqual(result.Spec.Template.Annotations, podAnnotations) {
 		t.Errorf("Wrong spec.template.annotations: '%s'", result.Spec.Template.Annotations)
 	}
 	if *result.Spec.Template.Spec.TerminationGracePeriodSeconds != 20 {
@@ -319,7 +727,7 @@ func Test_translateStatefulSet(t *testing.T) {
 	}
 	initContainer := apiv1.Container{
 		Name:    fmt.Sprintf("init-%s", "svcName"),
-		Image:   "busybox",
+		Image:   defaultVolumeInitImage,
 		Command: []string{"chmod", "-R", "777", "/data"},
 		VolumeMounts: []apiv1.VolumeMount{
 			{
@@ -348,7 +756,7 @@ func Test_translateStatefulSet(t *testing.T) {
 	if !reflect.DeepEqual(c.Env, env) {
 		t.Errorf("Wrong container.env: '%v'", c.Env)
 	}
-	ports := []apiv1.ContainerPort{{ContainerPort: 80}, {ContainerPort: 90}}
+	ports := []apiv1.ContainerPort{{ContainerPort: 80, Protocol: apiv1.ProtocolTCP}, {ContainerPort: 90, Protocol: apiv1.ProtocolTCP}}
 	if !reflect.DeepEqual(c.Ports, ports) {
 		t.Errorf("Wrong container.ports: '%v'", c.Ports)
 	}
@@ -410,20 +818,100 @@ func Test_translateStatefulSet(t *testing.T) {
 	}
 }
 
-func Test_translateService(t *testing.T) {
+func Test_translateLabelsPrecedence(t *testing.T) {
 	s := &model.Stack{
 		Name: "stackName",
 		Services: map[string]model.Service{
 			"svcName": {
+				Image: "image",
 				Labels: map[string]string{
-					"label1": "value1",
-					"label2": "value2",
+					okLabels.StackNameLabel: "other",
+					"label":                 "pod",
+				},
+				Deploy: &model.DeployInfo{
+					Labels: map[string]string{
+						okLabels.StackServiceNameLabel: "other",
+						"label":                        "object",
+					},
+				},
+			},
+		},
+	}
+	labels := map[string]string{
+		"label":                        "object",
+		okLabels.StackNameLabel:        "stackName",
+		okLabels.StackServiceNameLabel: "svcName",
+	}
+	if result := translateLabels("svcName", s); !reflect.DeepEqual(result, labels) {
+		t.Errorf("Wrong object labels: '%s'", result)
+	}
+	podLabels := map[string]string{
+		"label":                        "pod",
+		okLabels.StackNameLabel:        "stackName",
+		okLabels.StackServiceNameLabel: "svcName",
+	}
+	if result := translatePodLabels("svcName", s); !reflect.DeepEqual(result, podLabels) {
+		t.Errorf("Wrong pod labels: '%s'", result)
+	}
+}
+
+func Test_translateTemplateTokens(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Labels: map[string]string{
+					"app": "myapp-{{.ServiceName}}",
+				},
+				Annotations: map[string]string{
+					"owner":   "{{.StackName}}.{{.Namespace}}",
+					"literal": "{{ .Values.name }}",
+				},
+				Deploy: &model.DeployInfo{
+					Labels: map[string]string{
+						"app": "{{.StackName}}-{{.ServiceName}}",
+					},
+				},
+			},
+		},
+	}
+	if result := translateLabels("svcName", s); result["app"] != "stackName-svcName" {
+		t.Errorf("Wrong object label: '%s'", result["app"])
+	}
+	if result := translatePodLabels("svcName", s); result["app"] != "myapp-svcName" {
+		t.Errorf("Wrong pod label: '%s'", result["app"])
+	}
+	annotations := map[string]string{
+		"owner":   "stackName.namespace",
+		"literal": "{{ .Values.name }}",
+	}
+	if result := translateAnnotations("svcName", s); !reflect.DeepEqual(result, annotations) {
+		t.Errorf("Wrong annotations: '%s'", result)
+	}
+	d := translateDeployment("svcName", s)
+	if d.Spec.Template.Annotations["owner"] != "stackName.namespace" {
+		t.Errorf("Wrong pod annotation: '%s'", d.Spec.Template.Annotations["owner"])
+	}
+}
+
+func Test_translateService(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Deploy: &model.DeployInfo{
+					Labels: map[string]string{
+						"label1": "value1",
+						"label2": "value2",
+					},
 				},
 				Annotations: map[string]string{
 					"annotation1": "value1",
 					"annotation2": "value2",
 				},
-				Ports: []int32{80, 90},
+				Ports: []model.Port{{Port: 80, ContainerPort: 80}, {Port: 90, ContainerPort: 90}},
 			},
 		},
 	}
@@ -452,11 +940,13 @@ func Test_translateService(t *testing.T) {
 			Name:       "p-80",
 			Port:       80,
 			TargetPort: intstr.IntOrString{IntVal: 80},
+			Protocol:   apiv1.ProtocolTCP,
 		},
 		{
 			Name:       "p-90",
 			Port:       90,
 			TargetPort: intstr.IntOrString{IntVal: 90},
+			Protocol:   apiv1.ProtocolTCP,
 		},
 	}
 	if !reflect.DeepEqual(result.Spec.Ports, ports) {
@@ -486,6 +976,74 @@ func Test_translateService(t *testing.T) {
 	}
 }
 
+func Test_translateServiceWithoutPorts(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"worker": {Image: "image"},
+			"api":    {Image: "image", Expose: []model.Port{{Port: 8080, ContainerPort: 8080}}},
+		},
+	}
+	if result := translateService("worker", s); result != nil {
+		t.Errorf("Service created for a service without ports: '%v'", result)
+	}
+	if result := translateService("api", s); result == nil {
+		t.Errorf("Service not created for a service with exposed ports")
+	}
+	for _, obj := range GetApplyOrder(s) {
+		if obj.Kind == serviceKind && obj.Name == "worker" {
+			t.Errorf("Service applied for a service without ports")
+		}
+	}
+}
+
+func Test_translateDaemonSet(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"agent": {
+				Image:    "datadog/agent",
+				Replicas: 3,
+				Reload:   true,
+				Ports:    []model.Port{{Port: 8125, ContainerPort: 8125, Protocol: apiv1.ProtocolUDP}},
+				Deploy:   &model.DeployInfo{Mode: model.GlobalDeployMode, Labels: map[string]string{"team": "observability"}},
+			},
+		},
+	}
+	ds := translateDaemonSet("agent", s)
+	if ds.Name != "agent" || ds.Namespace != "namespace" {
+		t.Errorf("Wrong daemonset metadata: '%s/%s'", ds.Namespace, ds.Name)
+	}
+	labels := map[string]string{
+		"team":                         "observability",
+		okLabels.StackNameLabel:        "stackName",
+		okLabels.StackServiceNameLabel: "agent",
+	}
+	if !reflect.DeepEqual(ds.Labels, labels) {
+		t.Errorf("Wrong daemonset labels: '%v'", ds.Labels)
+	}
+	if ds.Annotations[okLabels.ReloaderAutoAnnotation] != "true" {
+		t.Errorf("Wrong daemonset annotations: '%v'", ds.Annotations)
+	}
+	if !reflect.DeepEqual(ds.Spec.Selector.MatchLabels, translateLabelSelector("agent", s)) {
+		t.Errorf("Wrong daemonset selector: '%v'", ds.Spec.Selector.MatchLabels)
+	}
+	c := ds.Spec.Template.Spec.Containers[0]
+	if c.Image != "datadog/agent" {
+		t.Errorf("Wrong daemonset image: '%s'", c.Image)
+	}
+	ports := []apiv1.ContainerPort{{ContainerPort: 8125, Protocol: apiv1.ProtocolUDP}}
+	if !reflect.DeepEqual(c.Ports, ports) {
+		t.Errorf("Wrong daemonset ports: '%v'", c.Ports)
+	}
+
+	obj := ApplyObject{Kind: daemonSetKind, Namespace: s.Namespace, Name: "agent"}
+	if _, ok := translateObject(obj, s).(*appsv1.DaemonSet); !ok {
+		t.Errorf("Wrong object for global service")
+	}
+}
+
 func Test_translateEndpoints(t *testing.T) {
 	s := &model.Stack{
 		Name: "stackName",
@@ -513,8 +1071,10 @@ func Test_translateEndpoints(t *testing.T) {
 		t.Errorf("Wrong service annotations: '%s'", result.Annotations)
 	}
 
+	prefix := extensions.PathTypePrefix
 	paths := []extensions.HTTPIngressPath{
 		{Path: "/",
+			PathType: &prefix,
 			Backend: extensions.IngressBackend{
 				ServiceName: "svcName",
 				ServicePort: intstr.IntOrString{IntVal: 80},
@@ -534,3 +1094,2035 @@ func Test_translateEndpoints(t *testing.T) {
 		t.Errorf("Wrong labels: '%s'", result.Labels)
 	}
 }
+
+func Test_translateEndpointsPathType(t *testing.T) {
+	endpoints := []model.Endpoint{
+		{Path: "/", Port: 80, Service: "web"},
+		{Path: "/healthz", PathType: model.ExactPathType, Port: 8080, Service: "api"},
+		{Path: "/api/*", PathType: model.ImplementationSpecificPathType, Port: 8080, Service: "api"},
+	}
+	expected := []extensions.PathType{extensions.PathTypePrefix, extensions.PathTypeExact, extensions.PathTypeImplementationSpecific}
+	result := translateEndpoints(endpoints)
+	if len(result) != len(expected) {
+		t.Fatalf("Wrong paths: '%v'", result)
+	}
+	for i := range result {
+		if result[i].PathType == nil || *result[i].PathType != expected[i] {
+			t.Errorf("Wrong path type of '%s': '%v'", result[i].Path, result[i].PathType)
+		}
+	}
+}
+
+func Test_translateAutoIngressClass(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Endpoints: map[string][]model.Endpoint{
+			"endpoint": {
+				{Path: "/", Port: 80, Service: "svcName"},
+			},
+		},
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:  "image",
+				Public: true,
+				Ports:  []model.Port{{Port: 80, ContainerPort: 80}},
+			},
+		},
+		Okteto: model.OktetoOptions{AutoIngressClass: "nginx"},
+	}
+	annotations := map[string]string{
+		okLabels.OktetoAutoIngressAnnotation: "true",
+		okLabels.IngressClassAnnotation:      "nginx",
+	}
+	if result := translateIngress("endpoint", s); !reflect.DeepEqual(result.Annotations, annotations) {
+		t.Errorf("Wrong ingress annotations: '%s'", result.Annotations)
+	}
+	if result := translateService("svcName", s); !reflect.DeepEqual(result.Annotations, annotations) {
+		t.Errorf("Wrong service annotations: '%s'", result.Annotations)
+	}
+}
+
+func Test_translateReload(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"api": {
+				Image:       "image",
+				Reload:      true,
+				Annotations: map[string]string{"team": "api"},
+			},
+			"db": {
+				Image:   "image",
+				Reload:  true,
+				Volumes: []string{"/data"},
+			},
+			"worker": {
+				Image: "image",
+			},
+		},
+	}
+	annotations := map[string]string{"team": "api", okLabels.ReloaderAutoAnnotation: "true"}
+	d := translateDeployment("api", s)
+	if !reflect.DeepEqual(d.Annotations, annotations) {
+		t.Errorf("Wrong deployment annotations: '%v'", d.Annotations)
+	}
+	if _, ok := d.Spec.Template.Annotations[okLabels.ReloaderAutoAnnotation]; ok {
+		t.Errorf("Wrong pod annotations: '%v'", d.Spec.Template.Annotations)
+	}
+	sfs := translateStatefulSet("db", s)
+	if sfs.Annotations[okLabels.ReloaderAutoAnnotation] != "true" {
+		t.Errorf("Wrong statefulset annotations: '%v'", sfs.Annotations)
+	}
+	if d := translateDeployment("worker", s); len(d.Annotations) != 0 {
+		t.Errorf("Wrong deployment annotations without reload: '%v'", d.Annotations)
+	}
+}
+
+func Test_translateIngressTLS(t *testing.T) {
+	var tests = []struct {
+		name        string
+		okteto      model.OktetoOptions
+		host        string
+		tls         []extensions.IngressTLS
+		annotations map[string]string
+	}{
+		{
+			name:        "http",
+			annotations: map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"},
+		},
+		{
+			name:        "domain",
+			okteto:      model.OktetoOptions{Domain: "example.com"},
+			host:        "endpoint-namespace.example.com",
+			annotations: map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"},
+		},
+		{
+			name:        "secret",
+			okteto:      model.OktetoOptions{Domain: "example.com", TLSSecret: "wildcard-tls"},
+			host:        "endpoint-namespace.example.com",
+			tls:         []extensions.IngressTLS{{Hosts: []string{"endpoint-namespace.example.com"}, SecretName: "wildcard-tls"}},
+			annotations: map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"},
+		},
+		{
+			name:   "issuer",
+			okteto: model.OktetoOptions{Domain: "example.com", TLSIssuer: "letsencrypt"},
+			host:   "endpoint-namespace.example.com",
+			tls:    []extensions.IngressTLS{{Hosts: []string{"endpoint-namespace.example.com"}, SecretName: "endpoint-tls"}},
+			annotations: map[string]string{
+				okLabels.OktetoAutoIngressAnnotation:        "true",
+				okLabels.CertManagerClusterIssuerAnnotation: "letsencrypt",
+			},
+		},
+		{
+			name:        "secret-without-domain",
+			okteto:      model.OktetoOptions{TLSSecret: "default-tls"},
+			tls:         []extensions.IngressTLS{{SecretName: "default-tls"}},
+			annotations: map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name:      "stackName",
+				Namespace: "namespace",
+				Endpoints: map[string][]model.Endpoint{
+					"endpoint": {{Path: "/", Port: 80, Service: "svcName"}},
+				},
+				Okteto: tt.okteto,
+			}
+			result := translateIngress("endpoint", s)
+			if result.Spec.Rules[0].Host != tt.host {
+				t.Errorf("Wrong ingress host: '%s'", result.Spec.Rules[0].Host)
+			}
+			if !reflect.DeepEqual(result.Spec.TLS, tt.tls) {
+				t.Errorf("Wrong ingress tls: '%v'", result.Spec.TLS)
+			}
+			if !reflect.DeepEqual(result.Annotations, tt.annotations) {
+				t.Errorf("Wrong ingress annotations: '%v'", result.Annotations)
+			}
+		})
+	}
+}
+
+func Test_translateIngressV1(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Endpoints: map[string][]model.Endpoint{
+			"endpoint": {
+				{Path: "/", Port: 80, Service: "web"},
+				{Path: "/healthz", PathType: model.ExactPathType, Port: 8080, Service: "api"},
+			},
+		},
+		Okteto: model.OktetoOptions{AutoIngressClass: "nginx", Domain: "example.com", TLSSecret: "wildcard-tls"},
+	}
+	result := translateIngressV1("endpoint", s)
+
+	annotations := map[string]string{okLabels.OktetoAutoIngressAnnotation: "true"}
+	if !reflect.DeepEqual(result.Annotations, annotations) {
+		t.Errorf("Wrong ingress annotations: '%v'", result.Annotations)
+	}
+	if result.Spec.IngressClassName == nil || *result.Spec.IngressClassName != "nginx" {
+		t.Errorf("Wrong ingress class name: '%v'", result.Spec.IngressClassName)
+	}
+	tls := []networkingv1.IngressTLS{{Hosts: []string{"endpoint-namespace.example.com"}, SecretName: "wildcard-tls"}}
+	if !reflect.DeepEqual(result.Spec.TLS, tls) {
+		t.Errorf("Wrong ingress tls: '%v'", result.Spec.TLS)
+	}
+	if result.Spec.Rules[0].Host != "endpoint-namespace.example.com" {
+		t.Errorf("Wrong ingress host: '%s'", result.Spec.Rules[0].Host)
+	}
+
+	prefix := networkingv1.PathTypePrefix
+	exact := networkingv1.PathTypeExact
+	paths := []networkingv1.HTTPIngressPath{
+		{
+			Path:     "/",
+			PathType: &prefix,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}},
+			},
+		},
+		{
+			Path:     "/healthz",
+			PathType: &exact,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "api", Port: networkingv1.ServiceBackendPort{Number: 8080}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result.Spec.Rules[0].HTTP.Paths, paths) {
+		t.Errorf("Wrong ingress paths: '%v'", result.Spec.Rules[0].HTTP.Paths)
+	}
+
+	s.Okteto = model.OktetoOptions{}
+	if result := translateIngressV1("endpoint", s); result.Spec.IngressClassName != nil {
+		t.Errorf("Wrong ingress class name without auto ingress class: '%v'", *result.Spec.IngressClassName)
+	}
+}
+
+func Test_translateIngressAPI(t *testing.T) {
+	var tests = []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		endpoints map[string][]model.Endpoint
+		expected  bool
+	}{
+		{
+			name: "v1",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}}},
+			},
+			endpoints: map[string][]model.Endpoint{"endpoint": {{Path: "/", Port: 80, Service: "web"}}},
+			expected:  true,
+		},
+		{
+			name: "v1-without-ingresses",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "networkpolicies", Kind: "NetworkPolicy"}}},
+			},
+			endpoints: map[string][]model.Endpoint{"endpoint": {{Path: "/", Port: 80, Service: "web"}}},
+			expected:  false,
+		},
+		{
+			name: "v1beta1",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "extensions/v1beta1", APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}}},
+			},
+			endpoints: map[string][]model.Endpoint{"endpoint": {{Path: "/", Port: 80, Service: "web"}}},
+			expected:  false,
+		},
+		{
+			name: "no-endpoints",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}}},
+			},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewSimpleClientset()
+			c.Fake.Resources = tt.resources
+			s := &model.Stack{Name: "stackName", Namespace: "namespace", Endpoints: tt.endpoints}
+			translateIngressAPI(context.Background(), s, c)
+			if s.IngressV1 != tt.expected {
+				t.Errorf("Wrong ingress api: expected v1 %t, got %t", tt.expected, s.IngressV1)
+			}
+			if s.Endpoints == nil {
+				return
+			}
+
+			obj := ApplyObject{Kind: ingressKind, Namespace: s.Namespace, Name: "endpoint"}
+			switch translateObject(obj, s).(type) {
+			case *networkingv1.Ingress:
+				if !tt.expected {
+					t.Errorf("Wrong ingress type: expected extensions/v1beta1")
+				}
+			case *extensions.Ingress:
+				if tt.expected {
+					t.Errorf("Wrong ingress type: expected networking.k8s.io/v1")
+				}
+			default:
+				t.Errorf("Wrong ingress object")
+			}
+		})
+	}
+}
+
+func Test_translateServicePublishedPorts(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Ports: []model.Port{{Port: 8080, ContainerPort: 80}},
+			},
+		},
+	}
+	svc := translateService("svcName", s)
+	ports := []apiv1.ServicePort{
+		{
+			Name:       "p-8080",
+			Port:       8080,
+			TargetPort: intstr.IntOrString{IntVal: 80},
+			Protocol:   apiv1.ProtocolTCP,
+		},
+	}
+	if !reflect.DeepEqual(svc.Spec.Ports, ports) {
+		t.Errorf("Wrong service ports: '%v'", svc.Spec.Ports)
+	}
+
+	d := translateDeployment("svcName", s)
+	containerPorts := []apiv1.ContainerPort{{ContainerPort: 80, Protocol: apiv1.ProtocolTCP}}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].Ports, containerPorts) {
+		t.Errorf("Wrong container.ports: '%v'", d.Spec.Template.Spec.Containers[0].Ports)
+	}
+}
+
+func Test_translateServiceEndpointMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		deploy    *model.DeployInfo
+		clusterIP string
+	}{
+		{
+			name:      "default",
+			clusterIP: "",
+		},
+		{
+			name:      "vip",
+			deploy:    &model.DeployInfo{EndpointMode: model.VIPEndpointMode},
+			clusterIP: "",
+		},
+		{
+			name:      "dnsrr",
+			deploy:    &model.DeployInfo{EndpointMode: model.DNSRREndpointMode},
+			clusterIP: apiv1.ClusterIPNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name: "stackName",
+				Services: map[string]model.Service{
+					"svcName": {
+						Image:  "image",
+						Ports:  []model.Port{{Port: 80, ContainerPort: 80}},
+						Deploy: tt.deploy,
+					},
+				},
+			}
+			result := translateService("svcName", s)
+			if result.Spec.ClusterIP != tt.clusterIP {
+				t.Errorf("Wrong service cluster ip: '%s'", result.Spec.ClusterIP)
+			}
+			if result.Spec.Type != apiv1.ServiceTypeClusterIP {
+				t.Errorf("Wrong service type: '%s'", result.Spec.Type)
+			}
+		})
+	}
+}
+
+func Test_translateServiceExposedPorts(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:  "image",
+				Ports:  []model.Port{{Port: 8080, ContainerPort: 80}},
+				Expose: []model.Port{{Port: 80, ContainerPort: 80}, {Port: 9090, ContainerPort: 9090}},
+			},
+		},
+	}
+	svc := translateService("svcName", s)
+	ports := []apiv1.ServicePort{
+		{
+			Name:       "p-8080",
+			Port:       8080,
+			TargetPort: intstr.IntOrString{IntVal: 80},
+			Protocol:   apiv1.ProtocolTCP,
+		},
+		{
+			Name:       "p-80",
+			Port:       80,
+			TargetPort: intstr.IntOrString{IntVal: 80},
+			Protocol:   apiv1.ProtocolTCP,
+		},
+		{
+			Name:       "p-9090",
+			Port:       9090,
+			TargetPort: intstr.IntOrString{IntVal: 9090},
+			Protocol:   apiv1.ProtocolTCP,
+		},
+	}
+	if !reflect.DeepEqual(svc.Spec.Ports, ports) {
+		t.Errorf("Wrong service ports: '%v'", svc.Spec.Ports)
+	}
+
+	d := translateDeployment("svcName", s)
+	containerPorts := []apiv1.ContainerPort{{ContainerPort: 80, Protocol: apiv1.ProtocolTCP}, {ContainerPort: 9090, Protocol: apiv1.ProtocolTCP}}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].Ports, containerPorts) {
+		t.Errorf("Wrong container.ports: '%v'", d.Spec.Template.Spec.Containers[0].Ports)
+	}
+}
+
+func Test_translateServiceWithEndpoints(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"api": {
+				Image:     "image",
+				Public:    true,
+				Ports:     []model.Port{{Port: 8080, ContainerPort: 8080}, {Port: 9090, ContainerPort: 9090}},
+				Endpoints: []model.ServiceEndpoint{{Port: 8080}, {Name: "admin", Path: "/admin", Port: 9090}},
+			},
+		},
+		Endpoints: map[string][]model.Endpoint{
+			"api-8080": {{Path: "/", Service: "api", Port: 8080}},
+			"admin":    {{Path: "/admin", Service: "api", Port: 9090}},
+		},
+	}
+	svc := translateService("api", s)
+	if svc.Spec.Type != apiv1.ServiceTypeLoadBalancer {
+		t.Errorf("Wrong service type: '%s'", svc.Spec.Type)
+	}
+	if _, ok := svc.Annotations[okLabels.OktetoAutoIngressAnnotation]; ok {
+		t.Errorf("Wrong service annotations: '%v'", svc.Annotations)
+	}
+	for name, port := range map[string]int32{"api-8080": 8080, "admin": 9090} {
+		ingress := translateIngress(name, s)
+		paths := ingress.Spec.Rules[0].HTTP.Paths
+		if len(paths) != 1 || paths[0].Backend.ServiceName != "api" || paths[0].Backend.ServicePort.IntVal != port {
+			t.Errorf("Wrong paths of ingress '%s': '%v'", name, paths)
+		}
+	}
+}
+
+func Test_translateProtocolPorts(t *testing.T) {
+	svc := &model.Service{
+		Ports: []model.Port{
+			{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP},
+			{Port: 53, ContainerPort: 53},
+		},
+	}
+	containerPorts := []apiv1.ContainerPort{
+		{ContainerPort: 53, Protocol: apiv1.ProtocolUDP},
+		{ContainerPort: 53, Protocol: apiv1.ProtocolTCP},
+	}
+	if result := translateContainerPorts(svc); !reflect.DeepEqual(result, containerPorts) {
+		t.Errorf("Wrong container ports: '%v'", result)
+	}
+	servicePorts := []apiv1.ServicePort{
+		{Name: "p-53-udp", Port: 53, TargetPort: intstr.IntOrString{IntVal: 53}, Protocol: apiv1.ProtocolUDP},
+		{Name: "p-53", Port: 53, TargetPort: intstr.IntOrString{IntVal: 53}, Protocol: apiv1.ProtocolTCP},
+	}
+	if result := translateServicePorts(svc); !reflect.DeepEqual(result, servicePorts) {
+		t.Errorf("Wrong service ports: '%v'", result)
+	}
+
+	svc.Healthchecks = true
+	probe := translateHealthCheckProbe(svc)
+	if probe == nil || probe.TCPSocket == nil || probe.TCPSocket.Port.IntVal != 53 {
+		t.Errorf("Wrong healthcheck probe: '%v'", probe)
+	}
+	svc.Ports = svc.Ports[:1]
+	if probe := translateHealthCheckProbe(svc); probe != nil {
+		t.Errorf("Wrong healthcheck probe for udp ports: '%v'", probe)
+	}
+}
+
+func Test_translateServiceNodePort(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:       "image",
+				ServiceType: apiv1.ServiceTypeNodePort,
+				Ports:       []model.Port{{Port: 80, ContainerPort: 8080, NodePort: 30080}, {Port: 9090, ContainerPort: 9090}},
+			},
+		},
+	}
+	svc := translateService("svcName", s)
+	if svc.Spec.Type != apiv1.ServiceTypeNodePort {
+		t.Errorf("Wrong service type: '%s'", svc.Spec.Type)
+	}
+	ports := []apiv1.ServicePort{
+		{Name: "p-80", Port: 80, TargetPort: intstr.IntOrString{IntVal: 8080}, Protocol: apiv1.ProtocolTCP, NodePort: 30080},
+		{Name: "p-9090", Port: 9090, TargetPort: intstr.IntOrString{IntVal: 9090}, Protocol: apiv1.ProtocolTCP},
+	}
+	if !reflect.DeepEqual(svc.Spec.Ports, ports) {
+		t.Errorf("Wrong service ports: '%v'", svc.Spec.Ports)
+	}
+
+	public := s.Services["svcName"]
+	public.Public = true
+	s.Services["svcName"] = public
+	if svc := translateService("svcName", s); svc.Spec.Type != apiv1.ServiceTypeNodePort {
+		t.Errorf("Wrong service type of public service: '%s'", svc.Spec.Type)
+	}
+}
+
+func Test_translateSessionAffinity(t *testing.T) {
+	tests := []struct {
+		name            string
+		sessionAffinity *model.SessionAffinity
+		expected        apiv1.ServiceAffinity
+		config          *apiv1.SessionAffinityConfig
+	}{
+		{
+			name:     "default",
+			expected: apiv1.ServiceAffinityNone,
+		},
+		{
+			name:            "client-ip",
+			sessionAffinity: &model.SessionAffinity{Type: apiv1.ServiceAffinityClientIP},
+			expected:        apiv1.ServiceAffinityClientIP,
+		},
+		{
+			name:            "client-ip-timeout",
+			sessionAffinity: &model.SessionAffinity{Type: apiv1.ServiceAffinityClientIP, Timeout: pointer.Int32Ptr(600)},
+			expected:        apiv1.ServiceAffinityClientIP,
+			config:          &apiv1.SessionAffinityConfig{ClientIP: &apiv1.ClientIPConfig{TimeoutSeconds: pointer.Int32Ptr(600)}},
+		},
+		{
+			name:            "none",
+			sessionAffinity: &model.SessionAffinity{Type: apiv1.ServiceAffinityNone},
+			expected:        apiv1.ServiceAffinityNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name: "stackName",
+				Services: map[string]model.Service{
+					"svcName": {
+						Image:           "image",
+						Ports:           []model.Port{{Port: 80, ContainerPort: 80}},
+						SessionAffinity: tt.sessionAffinity,
+					},
+				},
+			}
+			svc := translateService("svcName", s)
+			if svc.Spec.SessionAffinity != tt.expected {
+				t.Errorf("Wrong session affinity: '%s'", svc.Spec.SessionAffinity)
+			}
+			if !reflect.DeepEqual(svc.Spec.SessionAffinityConfig, tt.config) {
+				t.Errorf("Wrong session affinity config: '%v'", svc.Spec.SessionAffinityConfig)
+			}
+		})
+	}
+}
+
+func Test_translateServiceExposeOnly(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:  "image",
+				Public: true,
+				Expose: []model.Port{{Port: 8080, ContainerPort: 8080}},
+			},
+		},
+	}
+	svc := translateService("svcName", s)
+	if svc.Spec.Type != apiv1.ServiceTypeClusterIP {
+		t.Errorf("Wrong service type: '%s'", svc.Spec.Type)
+	}
+	if _, ok := svc.Annotations[okLabels.OktetoAutoIngressAnnotation]; ok {
+		t.Errorf("Wrong service annotations: '%v'", svc.Annotations)
+	}
+	ports := []apiv1.ServicePort{{Name: "p-8080", Port: 8080, TargetPort: intstr.IntOrString{IntVal: 8080}, Protocol: apiv1.ProtocolTCP}}
+	if !reflect.DeepEqual(svc.Spec.Ports, ports) {
+		t.Errorf("Wrong service ports: '%v'", svc.Spec.Ports)
+	}
+
+	published := s.Services["svcName"]
+	published.Ports = []model.Port{{Port: 80, ContainerPort: 80}}
+	s.Services["svcName"] = published
+	svc = translateService("svcName", s)
+	if svc.Spec.Type != apiv1.ServiceTypeLoadBalancer {
+		t.Errorf("Wrong service type: '%s'", svc.Spec.Type)
+	}
+	if svc.Annotations[okLabels.OktetoAutoIngressAnnotation] != "true" {
+		t.Errorf("Wrong service annotations: '%v'", svc.Annotations)
+	}
+}
+
+func Test_translateServiceExposeUDP(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:  "image",
+				Public: true,
+				Expose: []model.Port{{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP}},
+			},
+		},
+	}
+	svc := translateService("svcName", s)
+	if svc.Spec.Type != apiv1.ServiceTypeClusterIP {
+		t.Errorf("Wrong service type: '%s'", svc.Spec.Type)
+	}
+	if _, ok := svc.Annotations[okLabels.OktetoAutoIngressAnnotation]; ok {
+		t.Errorf("Wrong service annotations: '%v'", svc.Annotations)
+	}
+	ports := []apiv1.ServicePort{{Name: "p-53-udp", Port: 53, TargetPort: intstr.IntOrString{IntVal: 53}, Protocol: apiv1.ProtocolUDP}}
+	if !reflect.DeepEqual(svc.Spec.Ports, ports) {
+		t.Errorf("Wrong service ports: '%v'", svc.Spec.Ports)
+	}
+	containerPorts := []apiv1.ContainerPort{{ContainerPort: 53, Protocol: apiv1.ProtocolUDP}}
+	stackSvc := s.Services["svcName"]
+	if result := translateContainerPorts(&stackSvc); !reflect.DeepEqual(result, containerPorts) {
+		t.Errorf("Wrong container ports: '%v'", result)
+	}
+}
+
+func Test_translateEnvSources(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Environment: []model.EnvVar{
+					{Name: "MODE", Value: "production"},
+					{Name: "DB_PASSWORD", ValueFrom: &model.EnvVarSource{SecretKeyRef: &model.EnvKeyRef{Name: "db", Key: "password"}}},
+					{Name: "FEATURES", ValueFrom: &model.EnvVarSource{ConfigMapKeyRef: &model.EnvKeyRef{Name: "features", Key: "enabled", Optional: true}}},
+				},
+				EnvFrom: []model.EnvFromSource{
+					{SecretRef: &model.EnvSourceRef{Name: "api"}},
+					{ConfigMapRef: &model.EnvSourceRef{Name: "api", Optional: true}},
+				},
+			},
+			"worker": {Image: "image"},
+		},
+	}
+	optional := true
+	env := []apiv1.EnvVar{
+		{Name: "MODE", Value: "production"},
+		{
+			Name: "DB_PASSWORD",
+			ValueFrom: &apiv1.EnvVarSource{
+				SecretKeyRef: &apiv1.SecretKeySelector{LocalObjectReference: apiv1.LocalObjectReference{Name: "db"}, Key: "password"},
+			},
+		},
+		{
+			Name: "FEATURES",
+			ValueFrom: &apiv1.EnvVarSource{
+				ConfigMapKeyRef: &apiv1.ConfigMapKeySelector{LocalObjectReference: apiv1.LocalObjectReference{Name: "features"}, Key: "enabled", Optional: &optional},
+			},
+		},
+	}
+	envFrom := []apiv1.EnvFromSource{
+		{SecretRef: &apiv1.SecretEnvSource{LocalObjectReference: apiv1.LocalObjectReference{Name: "api"}}},
+		{ConfigMapRef: &apiv1.ConfigMapEnvSource{LocalObjectReference: apiv1.LocalObjectReference{Name: "api"}, Optional: &optional}},
+	}
+
+	d := translateDeployment("svcName", s)
+	c := d.Spec.Template.Spec.Containers[0]
+	if !reflect.DeepEqual(c.Env, env) {
+		t.Errorf("Wrong env: '%v'", c.Env)
+	}
+	if !reflect.DeepEqual(c.EnvFrom, envFrom) {
+		t.Errorf("Wrong env from: '%v'", c.EnvFrom)
+	}
+
+	d = translateDeployment("worker", s)
+	if envFrom := d.Spec.Template.Spec.Containers[0].EnvFrom; envFrom != nil {
+		t.Errorf("Wrong env from for a service without sources: '%v'", envFrom)
+	}
+}
+
+func Test_translateGPU(t *testing.T) {
+	var tests = []struct {
+		name         string
+		gpu          *model.GPUInfo
+		resource     apiv1.ResourceName
+		count        int64
+		nodeSelector map[string]string
+	}{
+		{
+			name:     "full",
+			gpu:      &model.GPUInfo{Count: 2},
+			resource: "nvidia.com/gpu",
+			count:    2,
+		},
+		{
+			name:         "shared",
+			gpu:          &model.GPUInfo{Profile: "shared"},
+			resource:     "nvidia.com/gpu.shared",
+			count:        1,
+			nodeSelector: map[string]string{"nvidia.com/gpu.sharing-strategy": "time-slicing"},
+		},
+		{
+			name:         "mig",
+			gpu:          &model.GPUInfo{Count: 3, Profile: "1g.5gb"},
+			resource:     "nvidia.com/mig-1g.5gb",
+			count:        3,
+			nodeSelector: map[string]string{"nvidia.com/mig.strategy": "mixed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &model.Service{
+				GPU: tt.gpu,
+				Resources: model.StackResources{
+					Limits: model.ServiceResources{
+						Memory: model.Quantity{Value: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			result := translateResources(svc)
+			limit := result.Limits[tt.resource]
+			request := result.Requests[tt.resource]
+			if limit.Value() != tt.count || request.Value() != tt.count {
+				t.Errorf("Wrong gpu resources: limits '%v' requests '%v'", result.Limits, result.Requests)
+			}
+			if len(result.Limits) != 2 || len(result.Requests) != 1 {
+				t.Errorf("Wrong resources: limits '%v' requests '%v'", result.Limits, result.Requests)
+			}
+			if nodeSelector := translateNodeSelector(svc); !reflect.DeepEqual(nodeSelector, tt.nodeSelector) {
+				t.Errorf("Wrong node selector: '%v'", nodeSelector)
+			}
+		})
+	}
+	if nodeSelector := translateNodeSelector(&model.Service{}); nodeSelector != nil {
+		t.Errorf("Wrong node selector without gpus: '%v'", nodeSelector)
+	}
+}
+
+func Test_translateResourcesEphemeralStorage(t *testing.T) {
+	svc := &model.Service{
+		Resources: model.StackResources{
+			Limits: model.ServiceResources{
+				EphemeralStorage: model.Quantity{Value: resource.MustParse("2Gi")},
+			},
+			Requests: model.ServiceResources{
+				EphemeralStorage: model.Quantity{Value: resource.MustParse("1Gi")},
+			},
+		},
+	}
+	result := translateResources(svc)
+	expected := apiv1.ResourceRequirements{
+		Limits: apiv1.ResourceList{
+			apiv1.ResourceEphemeralStorage: resource.MustParse("2Gi"),
+		},
+		Requests: apiv1.ResourceList{
+			apiv1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Wrong container.resources: '%v'", result)
+	}
+}
+
+func Test_translateResources(t *testing.T) {
+	var tests = []struct {
+		name      string
+		resources model.StackResources
+		expected  apiv1.ResourceRequirements
+	}{
+		{
+			name:      "empty",
+			resources: model.StackResources{},
+			expected:  apiv1.ResourceRequirements{},
+		},
+		{
+			name: "requests-only",
+			resources: model.StackResources{
+				Requests: model.ServiceResources{
+					CPU:    model.Quantity{Value: resource.MustParse("100m")},
+					Memory: model.Quantity{Value: resource.MustParse("128Mi")},
+				},
+			},
+			expected: apiv1.ResourceRequirements{
+				Requests: apiv1.ResourceList{
+					apiv1.ResourceCPU:    resource.MustParse("100m"),
+					apiv1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		},
+		{
+			name: "limits-only",
+			resources: model.StackResources{
+				Limits: model.ServiceResources{
+					CPU:    model.Quantity{Value: resource.MustParse("500m")},
+					Memory: model.Quantity{Value: resource.MustParse("1Gi")},
+				},
+			},
+			expected: apiv1.ResourceRequirements{
+				Limits: apiv1.ResourceList{
+					apiv1.ResourceCPU:    resource.MustParse("500m"),
+					apiv1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		{
+			name: "both",
+			resources: model.StackResources{
+				Limits: model.ServiceResources{
+					CPU:    model.Quantity{Value: resource.MustParse("500m")},
+					Memory: model.Quantity{Value: resource.MustParse("1Gi")},
+				},
+				Requests: model.ServiceResources{
+					CPU:    model.Quantity{Value: resource.MustParse("100m")},
+					Memory: model.Quantity{Value: resource.MustParse("128Mi")},
+				},
+			},
+			expected: apiv1.ResourceRequirements{
+				Limits: apiv1.ResourceList{
+					apiv1.ResourceCPU:    resource.MustParse("500m"),
+					apiv1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Requests: apiv1.ResourceList{
+					apiv1.ResourceCPU:    resource.MustParse("100m"),
+					apiv1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		},
+		{
+			name: "requests-memory-only",
+			resources: model.StackResources{
+				Limits: model.ServiceResources{
+					CPU: model.Quantity{Value: resource.MustParse("1")},
+				},
+				Requests: model.ServiceResources{
+					Memory: model.Quantity{Value: resource.MustParse("256Mi")},
+				},
+			},
+			expected: apiv1.ResourceRequirements{
+				Limits: apiv1.ResourceList{
+					apiv1.ResourceCPU: resource.MustParse("1"),
+				},
+				Requests: apiv1.ResourceList{
+					apiv1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name: "stackName",
+				Services: map[string]model.Service{
+					"svcName": {Image: "image", Resources: tt.resources},
+				},
+			}
+			d := translateDeployment("svcName", s)
+			result := d.Spec.Template.Spec.Containers[0].Resources
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Wrong container.resources: '%v'", result)
+			}
+		})
+	}
+}
+
+func Test_translateDeployResources(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Deploy: &model.DeployInfo{
+					Resources: &model.DeployResources{
+						Limits: model.DeployResourceList{
+							CPUs:   model.Quantity{Value: resource.MustParse("1")},
+							Memory: model.Quantity{Value: resource.MustParse("1Gi")},
+						},
+						Reservations: model.DeployResourceList{
+							CPUs:   model.Quantity{Value: resource.MustParse("250m")},
+							Memory: model.Quantity{Value: resource.MustParse("256Mi")},
+						},
+					},
+				},
+			},
+		},
+	}
+	s.Normalize()
+	d := translateDeployment("svcName", s)
+	expected := apiv1.ResourceRequirements{
+		Limits: apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse("1"),
+			apiv1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Requests: apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse("250m"),
+			apiv1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+	if result := d.Spec.Template.Spec.Containers[0].Resources; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Wrong container.resources: '%v'", result)
+	}
+}
+
+func Test_translateResourcesOOMKillDisable(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:          "image",
+				OOMKillDisable: true,
+				Resources: model.StackResources{
+					Limits: model.ServiceResources{
+						CPU:    model.Quantity{Value: resource.MustParse("500m")},
+						Memory: model.Quantity{Value: resource.MustParse("1Gi")},
+					},
+					Requests: model.ServiceResources{
+						Memory: model.Quantity{Value: resource.MustParse("128Mi")},
+					},
+				},
+			},
+		},
+	}
+	d := translateDeployment("svcName", s)
+	expected := apiv1.ResourceList{
+		apiv1.ResourceCPU:    resource.MustParse("500m"),
+		apiv1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	if result := d.Spec.Template.Spec.Containers[0].Resources.Requests; !reflect.DeepEqual(result, expected) {
+		t.Errorf("Wrong container.resources.requests: '%v'", result)
+	}
+}
+
+func Test_translatePodSecurityContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		expected *apiv1.PodSecurityContext
+	}{
+		{name: "empty"},
+		{name: "uid", user: "1000", expected: &apiv1.PodSecurityContext{RunAsUser: pointer.Int64Ptr(1000)}},
+		{
+			name:     "uid-gid",
+			user:     "1000:2000",
+			expected: &apiv1.PodSecurityContext{RunAsUser: pointer.Int64Ptr(1000), RunAsGroup: pointer.Int64Ptr(2000), FSGroup: pointer.Int64Ptr(2000)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name: "stackName",
+				Services: map[string]model.Service{
+					"svcName": {Image: "image", User: tt.user, CapAdd: []apiv1.Capability{"NET_ADMIN"}},
+				},
+			}
+			d := translateDeployment("svcName", s)
+			if !reflect.DeepEqual(d.Spec.Template.Spec.SecurityContext, tt.expected) {
+				t.Errorf("Wrong pod security context: '%v'", d.Spec.Template.Spec.SecurityContext)
+			}
+			securityContext := &apiv1.SecurityContext{Capabilities: &apiv1.Capabilities{Add: []apiv1.Capability{"NET_ADMIN"}}}
+			if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].SecurityContext, securityContext) {
+				t.Errorf("Wrong container security context: '%v'", d.Spec.Template.Spec.Containers[0].SecurityContext)
+			}
+		})
+	}
+}
+
+func Test_translatePrivilegedDevices(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:      "image",
+				Privileged: true,
+				CapAdd:     []apiv1.Capability{"NET_ADMIN"},
+				Devices:    []string{"/dev/fuse", "/dev/net/tun:/dev/tun:rwm"},
+			},
+		},
+	}
+	d := translateDeployment("svcName", s)
+	securityContext := &apiv1.SecurityContext{
+		Privileged:   pointer.BoolPtr(true),
+		Capabilities: &apiv1.Capabilities{Add: []apiv1.Capability{"NET_ADMIN"}},
+	}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].SecurityContext, securityContext) {
+		t.Errorf("Wrong container security context: '%v'", d.Spec.Template.Spec.Containers[0].SecurityContext)
+	}
+	volumes := []apiv1.Volume{
+		{
+			Name:         "device-0",
+			VolumeSource: apiv1.VolumeSource{HostPath: &apiv1.HostPathVolumeSource{Path: "/dev/fuse"}},
+		},
+		{
+			Name:         "device-1",
+			VolumeSource: apiv1.VolumeSource{HostPath: &apiv1.HostPathVolumeSource{Path: "/dev/net/tun"}},
+		},
+	}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Volumes, volumes) {
+		t.Errorf("Wrong spec.template.spec.volumes: '%v'", d.Spec.Template.Spec.Volumes)
+	}
+	volumeMounts := []apiv1.VolumeMount{
+		{MountPath: "/dev/fuse", Name: "device-0"},
+		{MountPath: "/dev/tun", Name: "device-1"},
+	}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+		t.Errorf("Wrong container.volume_mounts: '%v'", d.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+
+	svc := s.Services["svcName"]
+	svc.CapAdd = nil
+	s.Services["svcName"] = svc
+	d = translateDeployment("svcName", s)
+	securityContext = &apiv1.SecurityContext{Privileged: pointer.BoolPtr(true)}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].SecurityContext, securityContext) {
+		t.Errorf("Wrong container security context: '%v'", d.Spec.Template.Spec.Containers[0].SecurityContext)
+	}
+}
+
+func Test_translateStdinTTY(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:     "image",
+				StdinOpen: true,
+				TTY:       true,
+			},
+		},
+	}
+	d := translateDeployment("svcName", s)
+	if c := d.Spec.Template.Spec.Containers[0]; !c.Stdin || !c.TTY {
+		t.Errorf("Wrong container stdin/tty: '%t/%t'", c.Stdin, c.TTY)
+	}
+
+	svc := s.Services["svcName"]
+	svc.Volumes = []string{"/data"}
+	s.Services["svcName"] = svc
+	sfs := translateStatefulSet("svcName", s)
+	if c := sfs.Spec.Template.Spec.Containers[0]; !c.Stdin || !c.TTY {
+		t.Errorf("Wrong statefulset container stdin/tty: '%t/%t'", c.Stdin, c.TTY)
+	}
+
+	svc.StdinOpen = false
+	svc.TTY = false
+	s.Services["svcName"] = svc
+	d = translateDeployment("svcName", s)
+	if c := d.Spec.Template.Spec.Containers[0]; c.Stdin || c.TTY {
+		t.Errorf("Wrong container stdin/tty: '%t/%t'", c.Stdin, c.TTY)
+	}
+}
+
+func Test_translateHorizontalPodAutoscaler(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Deploy: &model.DeployInfo{
+					Autoscaling: &model.AutoscalingInfo{
+						Min: 2,
+						Max: 5,
+						Metrics: []model.AutoscalingMetric{
+							{
+								Type:         model.PodsMetricType,
+								Name:         "http_requests_per_second",
+								AverageValue: model.Quantity{Value: resource.MustParse("100")},
+							},
+							{
+								Type:     model.ExternalMetricType,
+								Name:     "queue_messages_ready",
+								Selector: map[string]string{"queue": "jobs"},
+								Value:    model.Quantity{Value: resource.MustParse("30")},
+							},
+						},
+					},
+				},
+			},
+			"noAutoscaling": {
+				Image: "image",
+			},
+		},
+	}
+	if result := translateHorizontalPodAutoscaler("noAutoscaling", s); result != nil {
+		t.Errorf("Unexpected horizontal pod autoscaler: '%v'", result)
+	}
+
+	result := translateHorizontalPodAutoscaler("svcName", s)
+	if result.Name != "svcName" {
+		t.Errorf("Wrong hpa name: '%s'", result.Name)
+	}
+	scaleTargetRef := autoscalingv2beta2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "svcName"}
+	if !reflect.DeepEqual(result.Spec.ScaleTargetRef, scaleTargetRef) {
+		t.Errorf("Wrong hpa scale target: '%v'", result.Spec.ScaleTargetRef)
+	}
+	if *result.Spec.MinReplicas != 2 || result.Spec.MaxReplicas != 5 {
+		t.Errorf("Wrong hpa replicas: '%d-%d'", *result.Spec.MinReplicas, result.Spec.MaxReplicas)
+	}
+
+	averageValue := resource.MustParse("100")
+	value := resource.MustParse("30")
+	metrics := []autoscalingv2beta2.MetricSpec{
+		{
+			Type: autoscalingv2beta2.PodsMetricSourceType,
+			Pods: &autoscalingv2beta2.PodsMetricSource{
+				Metric: autoscalingv2beta2.MetricIdentifier{Name: "http_requests_per_second"},
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:         autoscalingv2beta2.AverageValueMetricType,
+					AverageValue: &averageValue,
+				},
+			},
+		},
+		{
+			Type: autoscalingv2beta2.ExternalMetricSourceType,
+			External: &autoscalingv2beta2.ExternalMetricSource{
+				Metric: autoscalingv2beta2.MetricIdentifier{
+					Name:     "queue_messages_ready",
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"queue": "jobs"}},
+				},
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:  autoscalingv2beta2.ValueMetricType,
+					Value: &value,
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result.Spec.Metrics, metrics) {
+		t.Errorf("Wrong hpa metrics: '%v'", result.Spec.Metrics)
+	}
+}
+
+func Test_translateHorizontalPodAutoscalerCPUPercent(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:    "image",
+				Replicas: 1,
+				Resources: model.StackResources{
+					Requests: model.ServiceResources{CPU: model.Quantity{Value: resource.MustParse("100m")}},
+				},
+				Deploy: &model.DeployInfo{
+					Autoscaling: &model.AutoscalingInfo{Min: 2, Max: 5, CPUPercent: 80},
+				},
+			},
+		},
+	}
+	result := translateHorizontalPodAutoscaler("svcName", s)
+	metrics := []autoscalingv2beta2.MetricSpec{
+		{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name: apiv1.ResourceCPU,
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:               autoscalingv2beta2.UtilizationMetricType,
+					AverageUtilization: pointer.Int32Ptr(80),
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(result.Spec.Metrics, metrics) {
+		t.Errorf("Wrong hpa metrics: '%v'", result.Spec.Metrics)
+	}
+
+	if d := translateDeployment("svcName", s); d.Spec.Replicas != nil {
+		t.Errorf("Autoscaled deployment has static replicas: %d", *d.Spec.Replicas)
+	}
+	if sfs := translateStatefulSet("svcName", s); sfs.Spec.Replicas != nil {
+		t.Errorf("Autoscaled statefulset has static replicas: %d", *sfs.Spec.Replicas)
+	}
+	svc := s.Services["svcName"]
+	svc.Deploy = nil
+	s.Services["svcName"] = svc
+	if d := translateDeployment("svcName", s); d.Spec.Replicas == nil || *d.Spec.Replicas != 1 {
+		t.Errorf("Wrong deployment replicas: %v", d.Spec.Replicas)
+	}
+}
+
+func Test_translatePodDisruptionBudget(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"minAvailable": {
+				Image:    "image",
+				Replicas: 3,
+				Deploy:   &model.DeployInfo{PDB: &model.PDBInfo{MinAvailable: "2"}},
+			},
+			"maxUnavailable": {
+				Image:    "image",
+				Replicas: 3,
+				Deploy:   &model.DeployInfo{PDB: &model.PDBInfo{MaxUnavailable: "25%"}},
+			},
+			"noPDB": {
+				Image:    "image",
+				Replicas: 3,
+			},
+		},
+	}
+	if result := translatePodDisruptionBudget("noPDB", s); result != nil {
+		t.Errorf("Unexpected pod disruption budget: '%v'", result)
+	}
+
+	result := translatePodDisruptionBudget("minAvailable", s)
+	if result.Name != "minAvailable" || result.Namespace != "namespace" {
+		t.Errorf("Wrong pdb name: '%s/%s'", result.Namespace, result.Name)
+	}
+	if !reflect.DeepEqual(result.Labels, translateLabels("minAvailable", s)) {
+		t.Errorf("Wrong pdb labels: '%v'", result.Labels)
+	}
+	if !reflect.DeepEqual(result.Spec.Selector.MatchLabels, translateLabelSelector("minAvailable", s)) {
+		t.Errorf("Wrong pdb selector: '%v'", result.Spec.Selector.MatchLabels)
+	}
+	if result.Spec.MinAvailable == nil || *result.Spec.MinAvailable != intstr.FromInt(2) || result.Spec.MaxUnavailable != nil {
+		t.Errorf("Wrong pdb spec: '%v'", result.Spec)
+	}
+
+	result = translatePodDisruptionBudget("maxUnavailable", s)
+	if result.Spec.MaxUnavailable == nil || *result.Spec.MaxUnavailable != intstr.FromString("25%") || result.Spec.MinAvailable != nil {
+		t.Errorf("Wrong pdb spec: '%v'", result.Spec)
+	}
+}
+
+func Test_translateAutoscalingBehavior(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Deploy: &model.DeployInfo{
+					Autoscaling: &model.AutoscalingInfo{
+						Min: 1,
+						Max: 5,
+						Behavior: &model.AutoscalingBehavior{
+							ScaleUp: &model.AutoscalingRules{
+								SelectPolicy: "max",
+								Policies: []model.AutoscalingPolicy{
+									{Type: model.PodsPolicyType, Value: 2, Period: 60},
+									{Type: model.PercentPolicyType, Value: 50, Period: 30},
+								},
+							},
+							ScaleDown: &model.AutoscalingRules{
+								StabilizationWindow: pointer.Int32Ptr(600),
+							},
+						},
+					},
+				},
+			},
+			"noBehavior": {
+				Image: "image",
+				Deploy: &model.DeployInfo{
+					Autoscaling: &model.AutoscalingInfo{Min: 1, Max: 5},
+				},
+			},
+		},
+	}
+	if result := translateHorizontalPodAutoscaler("noBehavior", s); result.Spec.Behavior != nil {
+		t.Errorf("Unexpected hpa behavior: '%v'", result.Spec.Behavior)
+	}
+
+	result := translateHorizontalPodAutoscaler("svcName", s)
+	selectPolicy := autoscalingv2beta2.MaxPolicySelect
+	behavior := &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+		ScaleUp: &autoscalingv2beta2.HPAScalingRules{
+			SelectPolicy: &selectPolicy,
+			Policies: []autoscalingv2beta2.HPAScalingPolicy{
+				{Type: autoscalingv2beta2.PodsScalingPolicy, Value: 2, PeriodSeconds: 60},
+				{Type: autoscalingv2beta2.PercentScalingPolicy, Value: 50, PeriodSeconds: 30},
+			},
+		},
+		ScaleDown: &autoscalingv2beta2.HPAScalingRules{
+			StabilizationWindowSeconds: pointer.Int32Ptr(600),
+		},
+	}
+	if !reflect.DeepEqual(result.Spec.Behavior, behavior) {
+		t.Errorf("Wrong hpa behavior: '%v'", result.Spec.Behavior)
+	}
+}
+
+func Test_translateConfigChecksum(t *testing.T) {
+	ctx := context.Background()
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Environment: []model.EnvVar{
+					{Name: "env1", Value: "value1"},
+					{
+						Name: "env2",
+						ValueFrom: &model.EnvVarSource{
+							SecretKeyRef: &model.EnvKeyRef{Name: "secret", Key: "key"},
+						},
+					},
+				},
+				EnvFrom: []model.EnvFromSource{
+					{ConfigMapRef: &model.EnvSourceRef{Name: "config"}},
+				},
+			},
+		},
+	}
+	cfg := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "namespace"},
+		Data:       map[string]string{"key": "value1"},
+	}
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "namespace"},
+		Data:       map[string][]byte{"key": []byte("value1")},
+	}
+	c := fake.NewSimpleClientset(cfg, secret)
+	checksum := func() string {
+		translated := s.DeepCopy()
+		if err := translateConfigSources(ctx, translated, c); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return translateDeployment("svcName", translated).Spec.Template.Annotations[okLabels.StackConfigChecksumAnnotation]
+	}
+
+	first := checksum()
+	if first == "" {
+		t.Fatalf("Missing config checksum annotation")
+	}
+	if again := checksum(); again != first {
+		t.Errorf("Config checksum is not stable: '%s' != '%s'", again, first)
+	}
+
+	cfg.Data["key"] = "value2"
+	if _, err := c.CoreV1().ConfigMaps("namespace").Update(ctx, cfg, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	changed := checksum()
+	if changed == first {
+		t.Errorf("Config checksum didn't change after a config map change")
+	}
+
+	secret.Data["key"] = []byte("value2")
+	if _, err := c.CoreV1().Secrets("namespace").Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if again := checksum(); again == changed {
+		t.Errorf("Config checksum didn't change after a secret change")
+	}
+
+	if _, ok := translateDeployment("svcName", s).Annotations[okLabels.StackConfigChecksumAnnotation]; ok {
+		t.Errorf("Config checksum should only be set on the pod template")
+	}
+
+	s.Services["other"] = model.Service{Image: "image", Environment: []model.EnvVar{{Name: "env1", Value: "value1"}}}
+	translated := s.DeepCopy()
+	if err := translateConfigSources(ctx, translated, c); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := translateDeployment("other", translated).Spec.Template.Annotations[okLabels.StackConfigChecksumAnnotation]; ok {
+		t.Errorf("Config checksum should only be set on services consuming config maps or secrets")
+	}
+}
+
+func Test_translateConfigSourcesMountManifest(t *testing.T) {
+	s := &model.Stack{
+		Name:     "stackName",
+		Manifest: []byte("services: {}"),
+		Services: map[string]model.Service{
+			"svcName": {Image: "image", MountManifest: "/etc/okteto/stack.yml"},
+		},
+	}
+	if err := translateConfigSources(context.Background(), s, fake.NewSimpleClientset()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	first := s.Services["svcName"].SourcesChecksum
+	s.Manifest = []byte("services: {api: {}}")
+	if err := translateConfigSources(context.Background(), s, fake.NewSimpleClientset()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if s.Services["svcName"].SourcesChecksum == first {
+		t.Errorf("Config checksum didn't change after a manifest change")
+	}
+}
+
+func Test_translateTmpfs(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image: "image",
+				Tmpfs: []string{"/run", "/tmp:size=64Mi"},
+			},
+		},
+	}
+	d := translateDeployment("svcName", s)
+	sizeLimit := resource.MustParse("64Mi")
+	volumes := []apiv1.Volume{
+		{
+			Name: "tmpfs-0",
+			VolumeSource: apiv1.VolumeSource{
+				EmptyDir: &apiv1.EmptyDirVolumeSource{Medium: apiv1.StorageMediumMemory},
+			},
+		},
+		{
+			Name: "tmpfs-1",
+			VolumeSource: apiv1.VolumeSource{
+				EmptyDir: &apiv1.EmptyDirVolumeSource{Medium: apiv1.StorageMediumMemory, SizeLimit: &sizeLimit},
+			},
+		},
+	}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Volumes, volumes) {
+		t.Errorf("Wrong spec.template.spec.volumes: '%v'", d.Spec.Template.Spec.Volumes)
+	}
+	volumeMounts := []apiv1.VolumeMount{
+		{MountPath: "/run", Name: "tmpfs-0"},
+		{MountPath: "/tmp", Name: "tmpfs-1"},
+	}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+		t.Errorf("Wrong container.volume_mounts: '%v'", d.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+
+	svc := s.Services["svcName"]
+	svc.Volumes = []string{"/data"}
+	s.Services["svcName"] = svc
+	sfs := translateStatefulSet("svcName", s)
+	if !reflect.DeepEqual(sfs.Spec.Template.Spec.Volumes, volumes) {
+		t.Errorf("Wrong statefulset spec.template.spec.volumes: '%v'", sfs.Spec.Template.Spec.Volumes)
+	}
+	volumeMounts = append([]apiv1.VolumeMount{{MountPath: "/data", Name: pvcName, SubPath: "data-0"}}, volumeMounts...)
+	if !reflect.DeepEqual(sfs.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+		t.Errorf("Wrong statefulset container.volume_mounts: '%v'", sfs.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func Test_translateMountManifest(t *testing.T) {
+	s := &model.Stack{
+		Name:     "stackName",
+		Manifest: []byte("manifest"),
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:         "image",
+				MountManifest: "/etc/okteto/stack.yml",
+			},
+			"other": {
+				Image: "image",
+			},
+		},
+	}
+	cfg := translateConfigMap(s)
+	if cfg.Data[manifestField] != "manifest" {
+		t.Errorf("Wrong configmap manifest: '%s'", cfg.Data[manifestField])
+	}
+
+	volumes := []apiv1.Volume{
+		{
+			Name: manifestVolumeName,
+			VolumeSource: apiv1.VolumeSource{
+				ConfigMap: &apiv1.ConfigMapVolumeSource{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: "okteto-stackName"},
+					Items:                []apiv1.KeyToPath{{Key: manifestField, Path: manifestField}},
+				},
+			},
+		},
+	}
+	volumeMounts := []apiv1.VolumeMount{
+		{MountPath: "/etc/okteto/stack.yml", Name: manifestVolumeName, SubPath: manifestField, ReadOnly: true},
+	}
+	d := translateDeployment("svcName", s)
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Volumes, volumes) {
+		t.Errorf("Wrong spec.template.spec.volumes: '%v'", d.Spec.Template.Spec.Volumes)
+	}
+	if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+		t.Errorf("Wrong container.volume_mounts: '%v'", d.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+
+	svc := s.Services["svcName"]
+	svc.Volumes = []string{"/data"}
+	s.Services["svcName"] = svc
+	sfs := translateStatefulSet("svcName", s)
+	if !reflect.DeepEqual(sfs.Spec.Template.Spec.Volumes, volumes) {
+		t.Errorf("Wrong statefulset spec.template.spec.volumes: '%v'", sfs.Spec.Template.Spec.Volumes)
+	}
+	volumeMounts = append([]apiv1.VolumeMount{{MountPath: "/data", Name: pvcName, SubPath: "data-0"}}, volumeMounts...)
+	if !reflect.DeepEqual(sfs.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+		t.Errorf("Wrong statefulset container.volume_mounts: '%v'", sfs.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+
+	other := translateDeployment("other", s)
+	if len(other.Spec.Template.Spec.Volumes) > 0 || len(other.Spec.Template.Spec.Containers[0].VolumeMounts) > 0 {
+		t.Errorf("Manifest mounted in a service without mount_manifest")
+	}
+
+	delete(s.Services, "svcName")
+	if _, ok := translateConfigMap(s).Data[manifestField]; ok {
+		t.Errorf("Plain manifest stored in the configmap without mount_manifest")
+	}
+}
+
+func Test_translateTerminationGracePeriod(t *testing.T) {
+	tests := []struct {
+		name            string
+		stopGracePeriod *int64
+		expected        int64
+	}{
+		{
+			name:     "default",
+			expected: 30,
+		},
+		{
+			name:            "explicit-zero",
+			stopGracePeriod: pointer.Int64Ptr(0),
+			expected:        0,
+		},
+		{
+			name:            "explicit",
+			stopGracePeriod: pointer.Int64Ptr(60),
+			expected:        60,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name: "stackName",
+				Services: map[string]model.Service{
+					"svcName": {Image: "image", StopGracePeriod: tt.stopGracePeriod},
+				},
+			}
+			d := translateDeployment("svcName", s)
+			if *d.Spec.Template.Spec.TerminationGracePeriodSeconds != tt.expected {
+				t.Errorf("Wrong deployment termination grace period: %d, expected %d", *d.Spec.Template.Spec.TerminationGracePeriodSeconds, tt.expected)
+			}
+
+			svc := s.Services["svcName"]
+			svc.Volumes = []string{"/data"}
+			s.Services["svcName"] = svc
+			sfs := translateStatefulSet("svcName", s)
+			if *sfs.Spec.Template.Spec.TerminationGracePeriodSeconds != tt.expected {
+				t.Errorf("Wrong statefulset termination grace period: %d, expected %d", *sfs.Spec.Template.Spec.TerminationGracePeriodSeconds, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_translateVolumePopulator(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:   "image",
+				Volumes: []string{"/var/cache", "/var/lib/data"},
+				VolumePopulator: &model.VolumePopulator{
+					Image:  "okteto/seed:1.0",
+					Source: "/seed",
+					Target: "/var/lib/data",
+				},
+			},
+		},
+	}
+	sfs := translateStatefulSet("svcName", s)
+	initContainers := []apiv1.Container{
+		{
+			Name:    "init-svcName",
+			Image:   "okteto/seed:1.0",
+			Command: []string{"sh", "-c", "mkdir -p /okteto/volume/data-1 && cp -Rn /seed/. /okteto/volume/data-1 && chmod -R 777 /okteto/volume"},
+			VolumeMounts: []apiv1.VolumeMount{
+				{MountPath: "/okteto/volume", Name: pvcName},
+			},
+		},
+	}
+	if !reflect.DeepEqual(sfs.Spec.Template.Spec.InitContainers, initContainers) {
+		t.Errorf("Wrong statefulset init containers: '%v'", sfs.Spec.Template.Spec.InitContainers)
+	}
+}
+
+func Test_translateVolumeInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		svc      model.Service
+		expected []apiv1.Container
+	}{
+		{
+			name: "custom-image",
+			svc:  model.Service{Image: "image", Volumes: []string{"/data"}, VolumeInitImage: "registry.local/busybox:1.33"},
+			expected: []apiv1.Container{
+				{
+					Name:         "init-svcName",
+					Image:        "registry.local/busybox:1.33",
+					Command:      []string{"chmod", "-R", "777", "/data"},
+					VolumeMounts: []apiv1.VolumeMount{{MountPath: "/data", Name: pvcName}},
+				},
+			},
+		},
+		{
+			name: "disabled",
+			svc:  model.Service{Image: "image", Volumes: []string{"/data"}, SkipVolumeChmod: true},
+		},
+		{
+			name: "fs-group",
+			svc:  model.Service{Image: "image", Volumes: []string{"/data"}, User: "1000:1000"},
+		},
+		{
+			name: "disabled-with-populator",
+			svc: model.Service{
+				Image:           "image",
+				Volumes:         []string{"/data"},
+				SkipVolumeChmod: true,
+				VolumePopulator: &model.VolumePopulator{Image: "okteto/seed:1.0", Source: "/seed", Target: "/data"},
+			},
+			expected: []apiv1.Container{
+				{
+					Name:         "init-svcName",
+					Image:        "okteto/seed:1.0",
+					Command:      []string{"sh", "-c", "mkdir -p /okteto/volume/data-0 && cp -Rn /seed/. /okteto/volume/data-0"},
+					VolumeMounts: []apiv1.VolumeMount{{MountPath: "/okteto/volume", Name: pvcName}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name:     "stackName",
+				Services: map[string]model.Service{"svcName": tt.svc},
+			}
+			sfs := translateStatefulSet("svcName", s)
+			if !reflect.DeepEqual(sfs.Spec.Template.Spec.InitContainers, tt.expected) {
+				t.Errorf("Wrong statefulset init containers: '%v'", sfs.Spec.Template.Spec.InitContainers)
+			}
+		})
+	}
+}
+
+func Test_translateInlineVolumeSize(t *testing.T) {
+	s := &model.Stack{
+		Name: "stackName",
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:   "image",
+				Volumes: []string{"/data:10Gi"},
+			},
+		},
+	}
+	sfs := translateStatefulSet("svcName", s)
+	size := sfs.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"]
+	if size.Cmp(resource.MustParse("10Gi")) != 0 {
+		t.Errorf("Wrong volume claim size: '%s'", size.String())
+	}
+	volumeMounts := []apiv1.VolumeMount{{MountPath: "/data", Name: pvcName, SubPath: "data-0"}}
+	if !reflect.DeepEqual(sfs.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+		t.Errorf("Wrong container.volume_mounts: '%v'", sfs.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func Test_translateStorageAccessMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		accessMode apiv1.PersistentVolumeAccessMode
+		expected   apiv1.PersistentVolumeAccessMode
+	}{
+		{name: "default", expected: apiv1.ReadWriteOnce},
+		{name: "read-write-many", accessMode: apiv1.ReadWriteMany, expected: apiv1.ReadWriteMany},
+		{name: "read-only-many", accessMode: apiv1.ReadOnlyMany, expected: apiv1.ReadOnlyMany},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name: "stackName",
+				Services: map[string]model.Service{
+					"svcName": {
+						Image:    "image",
+						Replicas: 2,
+						Volumes:  []string{"/data"},
+						Resources: model.StackResources{
+							Requests: model.ServiceResources{
+								Storage: model.StorageResource{AccessMode: tt.accessMode},
+							},
+						},
+					},
+				},
+			}
+			sfs := translateStatefulSet("svcName", s)
+			accessModes := []apiv1.PersistentVolumeAccessMode{tt.expected}
+			if !reflect.DeepEqual(sfs.Spec.VolumeClaimTemplates[0].Spec.AccessModes, accessModes) {
+				t.Errorf("Wrong volume claim access modes: '%v'", sfs.Spec.VolumeClaimTemplates[0].Spec.AccessModes)
+			}
+		})
+	}
+}
+
+func Test_translateNamedVolumes(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Volumes: map[string]model.VolumeSpec{
+			"uploads": {Size: model.Quantity{Value: resource.MustParse("5Gi")}, Class: "nfs", AccessMode: apiv1.ReadWriteMany},
+		},
+		Services: map[string]model.Service{
+			"web": {
+				Image:        "web",
+				NamedVolumes: []model.NamedVolumeMount{{Name: "uploads", MountPath: "/var/www/uploads"}},
+			},
+			"worker": {
+				Image:        "worker",
+				NamedVolumes: []model.NamedVolumeMount{{Name: "uploads", MountPath: "/uploads"}},
+			},
+		},
+	}
+	volumes := []apiv1.Volume{
+		{
+			Name: "volume-uploads",
+			VolumeSource: apiv1.VolumeSource{
+				PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: "uploads"},
+			},
+		},
+	}
+	for name, path := range map[string]string{"web": "/var/www/uploads", "worker": "/uploads"} {
+		d := translateDeployment(name, s)
+		if !reflect.DeepEqual(d.Spec.Template.Spec.Volumes, volumes) {
+			t.Errorf("Wrong spec.template.spec.volumes of '%s': '%v'", name, d.Spec.Template.Spec.Volumes)
+		}
+		volumeMounts := []apiv1.VolumeMount{{MountPath: path, Name: "volume-uploads"}}
+		if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+			t.Errorf("Wrong container.volume_mounts of '%s': '%v'", name, d.Spec.Template.Spec.Containers[0].VolumeMounts)
+		}
+	}
+
+	pvc := translatePersistentVolumeClaim("uploads", s)
+	if pvc.Name != "uploads" || pvc.Namespace != "namespace" {
+		t.Errorf("Wrong pvc name: '%s/%s'", pvc.Namespace, pvc.Name)
+	}
+	labels := map[string]string{
+		okLabels.StackNameLabel:       "stackName",
+		okLabels.StackVolumeNameLabel: "uploads",
+	}
+	if !reflect.DeepEqual(pvc.Labels, labels) {
+		t.Errorf("Wrong pvc labels: '%v'", pvc.Labels)
+	}
+	if !reflect.DeepEqual(pvc.Spec.AccessModes, []apiv1.PersistentVolumeAccessMode{apiv1.ReadWriteMany}) {
+		t.Errorf("Wrong pvc access modes: '%v'", pvc.Spec.AccessModes)
+	}
+	if size := pvc.Spec.Resources.Requests[apiv1.ResourceStorage]; size.Cmp(resource.MustParse("5Gi")) != 0 {
+		t.Errorf("Wrong pvc size: '%s'", size.String())
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "nfs" {
+		t.Errorf("Wrong pvc storage class: '%v'", pvc.Spec.StorageClassName)
+	}
+}
+
+func Test_translateReadOnlyVolumeMounts(t *testing.T) {
+	s := &model.Stack{
+		Name:    "stackName",
+		Volumes: map[string]model.VolumeSpec{"assets": {}},
+		Services: map[string]model.Service{
+			"svcName": {
+				Image:        "image",
+				Volumes:      []string{"/config:ro", "/data:rw", "/cache"},
+				NamedVolumes: []model.NamedVolumeMount{{Name: "assets", MountPath: "/assets", ReadOnly: true}},
+			},
+		},
+	}
+	sfs := translateStatefulSet("svcName", s)
+	volumeMounts := []apiv1.VolumeMount{
+		{MountPath: "/config", Name: pvcName, SubPath: "data-0", ReadOnly: true},
+		{MountPath: "/data", Name: pvcName, SubPath: "data-1"},
+		{MountPath: "/cache", Name: pvcName, SubPath: "data-2"},
+		{MountPath: "/assets", Name: "volume-assets", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(sfs.Spec.Template.Spec.Containers[0].VolumeMounts, volumeMounts) {
+		t.Errorf("Wrong container.volume_mounts: '%v'", sfs.Spec.Template.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func Test_translateImagePullPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		svc      *model.Service
+		expected apiv1.PullPolicy
+	}{
+		{
+			name:     "built-image",
+			svc:      &model.Service{Image: "okteto.dev/stack-api:okteto", Build: &model.BuildInfo{Context: "."}},
+			expected: apiv1.PullIfNotPresent,
+		},
+		{
+			name:     "external-image",
+			svc:      &model.Service{Image: "okteto/api:latest"},
+			expected: "",
+		},
+		{
+			name:     "built-image-in-external-registry",
+			svc:      &model.Service{Image: "okteto/api:latest", Build: &model.BuildInfo{Context: "."}},
+			expected: "",
+		},
+		{
+			name:     "built-image-with-override",
+			svc:      &model.Service{Image: "okteto.dev/stack-api:okteto", Build: &model.BuildInfo{Context: "."}, PullPolicy: "always"},
+			expected: apiv1.PullAlways,
+		},
+		{
+			name:     "external-image-with-override",
+			svc:      &model.Service{Image: "okteto/api:latest", PullPolicy: "missing"},
+			expected: apiv1.PullIfNotPresent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name:     "stackName",
+				Services: map[string]model.Service{"svcName": *tt.svc},
+			}
+			d := translateDeployment("svcName", s)
+			if result := d.Spec.Template.Spec.Containers[0].ImagePullPolicy; result != tt.expected {
+				t.Errorf("Wrong container.image_pull_policy: '%s'", result)
+			}
+		})
+	}
+}
+
+func Test_translateJobRestartPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		restart  string
+		expected apiv1.RestartPolicy
+	}{
+		{
+			name:     "default",
+			expected: apiv1.RestartPolicyOnFailure,
+		},
+		{
+			name:     "restart-no",
+			restart:  model.RestartNo,
+			expected: apiv1.RestartPolicyNever,
+		},
+		{
+			name:     "restart-on-failure",
+			restart:  model.RestartOnFailure,
+			expected: apiv1.RestartPolicyOnFailure,
+		},
+		{
+			name:     "restart-always",
+			restart:  model.RestartAlways,
+			expected: apiv1.RestartPolicyOnFailure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &model.Service{Image: "image", Restart: tt.restart}
+			if result := translateJobRestartPolicy(svc); result != tt.expected {
+				t.Errorf("Wrong job restart policy: '%s', expected '%s'", result, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_translateJob(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"migrate": {
+				Image:       "image",
+				Restart:     model.RestartNo,
+				Command:     model.Command{Values: []string{"./migrate.sh"}},
+				Args:        model.Args{Values: []string{"up"}},
+				Environment: []model.EnvVar{{Name: "env", Value: "value"}},
+			},
+		},
+	}
+	job := translateJob("migrate", s)
+	if job.Name != "migrate" || job.Namespace != "namespace" {
+		t.Errorf("Wrong job name: '%s/%s'", job.Namespace, job.Name)
+	}
+	if job.Labels[okLabels.StackNameLabel] != "stackName" || job.Labels[okLabels.StackServiceNameLabel] != "migrate" {
+		t.Errorf("Wrong job labels: '%v'", job.Labels)
+	}
+	podSpec := job.Spec.Template.Spec
+	if podSpec.RestartPolicy != apiv1.RestartPolicyNever {
+		t.Errorf("Wrong job restart policy: '%s'", podSpec.RestartPolicy)
+	}
+	c := podSpec.Containers[0]
+	if c.Image != "image" {
+		t.Errorf("Wrong job image: '%s'", c.Image)
+	}
+	if !reflect.DeepEqual(c.Command, []string{"./migrate.sh"}) || !reflect.DeepEqual(c.Args, []string{"up"}) {
+		t.Errorf("Wrong job command: '%v' '%v'", c.Command, c.Args)
+	}
+	if !reflect.DeepEqual(c.Env, []apiv1.EnvVar{{Name: "env", Value: "value"}}) {
+		t.Errorf("Wrong job environment: '%v'", c.Env)
+	}
+
+	first := job.Annotations[okLabels.StackJobChecksumAnnotation]
+	if first == "" {
+		t.Fatalf("Missing job checksum annotation")
+	}
+	if again := translateJob("migrate", s).Annotations[okLabels.StackJobChecksumAnnotation]; again != first {
+		t.Errorf("Job checksum is not stable: '%s' != '%s'", again, first)
+	}
+	svc := s.Services["migrate"]
+	svc.Image = "image:2"
+	s.Services["migrate"] = svc
+	if changed := translateJob("migrate", s).Annotations[okLabels.StackJobChecksumAnnotation]; changed == first {
+		t.Errorf("Job checksum didn't change after a configuration change")
+	}
+}
+
+func Test_translateCronJob(t *testing.T) {
+	s := &model.Stack{
+		Name:      "stackName",
+		Namespace: "namespace",
+		Services: map[string]model.Service{
+			"backup": {
+				Image:       "image",
+				Schedule:    "0 3 * * *",
+				Labels:      map[string]string{"label": "value"},
+				Annotations: map[string]string{"annotation": "value"},
+				Command:     model.Command{Values: []string{"./backup.sh"}},
+			},
+		},
+	}
+	cronJob := translateCronJob("backup", s)
+	if cronJob.Name != "backup" || cronJob.Namespace != "namespace" {
+		t.Errorf("Wrong cronjob name: '%s/%s'", cronJob.Namespace, cronJob.Name)
+	}
+	if !reflect.DeepEqual(cronJob.Labels, translateLabels("backup", s)) {
+		t.Errorf("Wrong cronjob labels: '%v'", cronJob.Labels)
+	}
+	if !reflect.DeepEqual(cronJob.Annotations, translateAnnotations("backup", s)) {
+		t.Errorf("Wrong cronjob annotations: '%v'", cronJob.Annotations)
+	}
+	if cronJob.Spec.Schedule != "0 3 * * *" {
+		t.Errorf("Wrong cronjob schedule: '%s'", cronJob.Spec.Schedule)
+	}
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	if podSpec.RestartPolicy != apiv1.RestartPolicyOnFailure {
+		t.Errorf("Wrong cronjob restart policy: '%s'", podSpec.RestartPolicy)
+	}
+	if !reflect.DeepEqual(podSpec.Containers[0].Command, []string{"./backup.sh"}) {
+		t.Errorf("Wrong cronjob command: '%v'", podSpec.Containers[0].Command)
+	}
+}
+
+func Test_translateHealthCheckProbe(t *testing.T) {
+	tests := []struct {
+		name     string
+		svc      model.Service
+		expected *apiv1.Probe
+	}{
+		{
+			name:     "none",
+			svc:      model.Service{Image: "image", Ports: []model.Port{{Port: 8080, ContainerPort: 8080}}},
+			expected: nil,
+		},
+		{
+			name: "healthchecks",
+			svc:  model.Service{Image: "image", Healthchecks: true, Ports: []model.Port{{Port: 80, ContainerPort: 8080}, {Port: 9090, ContainerPort: 9090}}},
+			expected: &apiv1.Probe{
+				Handler: apiv1.Handler{
+					TCPSocket: &apiv1.TCPSocketAction{Port: intstr.IntOrString{IntVal: 8080}},
+				},
+			},
+		},
+		{
+			name:     "healthchecks-without-ports",
+			svc:      model.Service{Image: "image", Healthchecks: true},
+			expected: nil,
+		},
+		{
+			name: "test",
+			svc: model.Service{
+				Image: "image",
+				Healthcheck: &model.HealthCheck{
+					Test:        []string{"pg_isready", "-U", "postgres"},
+					Interval:    10 * time.Second,
+					Timeout:     2 * time.Second,
+					Retries:     5,
+					StartPeriod: 30 * time.Second,
+				},
+			},
+			expected: &apiv1.Probe{
+				Handler: apiv1.Handler{
+					Exec: &apiv1.ExecAction{Command: []string{"pg_isready", "-U", "postgres"}},
+				},
+				InitialDelaySeconds: 30,
+				PeriodSeconds:       10,
+				TimeoutSeconds:      2,
+				FailureThreshold:    5,
+			},
+		},
+		{
+			name: "test-cmd",
+			svc: model.Service{
+				Image:       "image",
+				Healthcheck: &model.HealthCheck{Test: []string{"CMD", "pg_isready", "-U", "postgres"}},
+			},
+			expected: &apiv1.Probe{
+				Handler: apiv1.Handler{
+					Exec: &apiv1.ExecAction{Command: []string{"pg_isready", "-U", "postgres"}},
+				},
+			},
+		},
+		{
+			name: "test-cmd-shell",
+			svc: model.Service{
+				Image:       "image",
+				Healthcheck: &model.HealthCheck{Test: []string{"CMD-SHELL", "curl -f http://localhost || exit 1"}},
+			},
+			expected: &apiv1.Probe{
+				Handler: apiv1.Handler{
+					Exec: &apiv1.ExecAction{Command: []string{"sh", "-c", "curl -f http://localhost || exit 1"}},
+				},
+			},
+		},
+		{
+			name: "test-none",
+			svc: model.Service{
+				Image:       "image",
+				Ports:       []model.Port{{Port: 8080, ContainerPort: 8080}},
+				Healthcheck: &model.HealthCheck{Test: []string{"NONE"}},
+			},
+			expected: nil,
+		},
+		{
+			name: "http",
+			svc: model.Service{
+				Image:       "image",
+				Healthcheck: &model.HealthCheck{HTTP: &model.HTTPHealthCheck{Path: "/healthz", Port: 8080}},
+			},
+			expected: &apiv1.Probe{
+				Handler: apiv1.Handler{
+					HTTPGet: &apiv1.HTTPGetAction{Path: "/healthz", Port: intstr.IntOrString{IntVal: 8080}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &model.Stack{
+				Name:     "stackName",
+				Services: map[string]model.Service{"svcName": tt.svc},
+			}
+			d := translateDeployment("svcName", s)
+			if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].LivenessProbe, tt.expected) {
+				t.Errorf("Wrong deployment liveness probe: '%v'", d.Spec.Template.Spec.Containers[0].LivenessProbe)
+			}
+			if !reflect.DeepEqual(d.Spec.Template.Spec.Containers[0].ReadinessProbe, tt.expected) {
+				t.Errorf("Wrong deployment readiness probe: '%v'", d.Spec.Template.Spec.Containers[0].ReadinessProbe)
+			}
+
+			svc := s.Services["svcName"]
+			svc.Volumes = []string{"/data"}
+			s.Services["svcName"] = svc
+			sfs := translateStatefulSet("svcName", s)
+			if !reflect.DeepEqual(sfs.Spec.Template.Spec.Containers[0].LivenessProbe, tt.expected) {
+				t.Errorf("Wrong statefulset liveness probe: '%v'", sfs.Spec.Template.Spec.Containers[0].LivenessProbe)
+			}
+			if !reflect.DeepEqual(sfs.Spec.Template.Spec.Containers[0].ReadinessProbe, tt.expected) {
+				t.Errorf("Wrong statefulset readiness probe: '%v'", sfs.Spec.Template.Spec.Containers[0].ReadinessProbe)
+			}
+		})
+	}
+}
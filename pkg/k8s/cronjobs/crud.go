@@ -0,0 +1,71 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/log"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//List returns the list of cronjobs
+func List(ctx context.Context, namespace, labels string, c kubernetes.Interface) ([]batchv1beta1.CronJob, error) {
+	cronJobList, err := c.BatchV1beta1().CronJobs(namespace).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labels,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cronJobList.Items, nil
+}
+
+//Get returns a cronjob object given its name and namespace
+func Get(ctx context.Context, name, namespace string, c kubernetes.Interface) (*batchv1beta1.CronJob, error) {
+	return c.BatchV1beta1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+//Create creates a cronjob
+func Create(ctx context.Context, cronJob *batchv1beta1.CronJob, c kubernetes.Interface) error {
+	_, err := c.BatchV1beta1().CronJobs(cronJob.Namespace).Create(ctx, cronJob, metav1.CreateOptions{})
+	return err
+}
+
+//Update updates a cronjob
+func Update(ctx context.Context, cronJob *batchv1beta1.CronJob, c kubernetes.Interface) error {
+	_, err := c.BatchV1beta1().CronJobs(cronJob.Namespace).Update(ctx, cronJob, metav1.UpdateOptions{})
+	return err
+}
+
+//Destroy destroys a cronjob and the jobs it created
+func Destroy(ctx context.Context, name, namespace string, c kubernetes.Interface) error {
+	log.Infof("deleting cronjob '%s'", name)
+	propagation := metav1.DeletePropagationBackground
+	err := c.BatchV1beta1().CronJobs(namespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting cronjob '%s': %s", name, err)
+	}
+	log.Infof("cronjob '%s' deleted", name)
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemonsets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/log"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//List returns the list of daemonsets
+func List(ctx context.Context, namespace, labels string, c kubernetes.Interface) ([]appsv1.DaemonSet, error) {
+	dsList, err := c.AppsV1().DaemonSets(namespace).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labels,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return dsList.Items, nil
+}
+
+//Get returns a daemonset object given its name and namespace
+func Get(ctx context.Context, name, namespace string, c kubernetes.Interface) (*appsv1.DaemonSet, error) {
+	return c.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+//Create creates a daemonset
+func Create(ctx context.Context, ds *appsv1.DaemonSet, c kubernetes.Interface) error {
+	_, err := c.AppsV1().DaemonSets(ds.Namespace).Create(ctx, ds, metav1.CreateOptions{})
+	return err
+}
+
+//Update updates a daemonset
+func Update(ctx context.Context, ds *appsv1.DaemonSet, c kubernetes.Interface) error {
+	ds.ResourceVersion = ""
+	ds.Status = appsv1.DaemonSetStatus{}
+	_, err := c.AppsV1().DaemonSets(ds.Namespace).Update(ctx, ds, metav1.UpdateOptions{})
+	return err
+}
+
+//Destroy destroys a daemonset
+func Destroy(ctx context.Context, name, namespace string, c kubernetes.Interface) error {
+	log.Infof("deleting daemonset '%s'", name)
+	err := c.AppsV1().DaemonSets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting daemonset '%s': %s", name, err)
+	}
+	log.Infof("daemonset '%s' deleted", name)
+	return nil
+}
+
+//IsRolledOut returns true if the pods of the daemonset are updated and available in all its nodes
+func IsRolledOut(ds *appsv1.DaemonSet) bool {
+	if ds.Status.ObservedGeneration < ds.Generation {
+		return false
+	}
+	return ds.Status.UpdatedNumberScheduled == ds.Status.DesiredNumberScheduled && ds.Status.NumberAvailable == ds.Status.DesiredNumberScheduled
+}
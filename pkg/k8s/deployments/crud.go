@@ -24,6 +24,7 @@ import (
 	"github.com/okteto/okteto/pkg/errors"
 	"github.com/okteto/okteto/pkg/k8s/labels"
 	okLabels "github.com/okteto/okteto/pkg/k8s/labels"
+	"github.com/okteto/okteto/pkg/k8s/replicasets"
 	"github.com/okteto/okteto/pkg/log"
 	"github.com/okteto/okteto/pkg/model"
 	appsv1 "k8s.io/api/apps/v1"
@@ -254,6 +255,47 @@ func UpdateOktetoRevision(ctx context.Context, d *appsv1.Deployment, client *kub
 	}
 }
 
+//IsRolledOut returns true if the latest rollout of a deployment is complete
+func IsRolledOut(d *appsv1.Deployment) bool {
+	if d.Status.ObservedGeneration < d.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas == replicas && d.Status.Replicas == replicas && d.Status.AvailableReplicas == replicas
+}
+
+//IsProgressDeadlineExceeded returns true if the latest rollout of a deployment failed to progress in time
+func IsProgressDeadlineExceeded(d *appsv1.Deployment) bool {
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" && c.Status == apiv1.ConditionFalse {
+			return true
+		}
+	}
+	return false
+}
+
+//Rollback restores the pod template of the previous revision of a deployment
+func Rollback(ctx context.Context, d *appsv1.Deployment, c kubernetes.Interface) error {
+	rs, err := replicasets.GetPreviousReplicaSetByDeployment(ctx, d, c)
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		return fmt.Errorf("deployment '%s' doesn't have a previous revision", d.Name)
+	}
+
+	template := rs.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	d.Spec.Template = *template
+	if _, err := c.AppsV1().Deployments(d.Namespace).Update(ctx, d, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error rolling back deployment '%s': %s", d.Name, err)
+	}
+	return nil
+}
+
 //SetLastBuiltAnnotation sets the deployment timestacmp
 func SetLastBuiltAnnotation(d *appsv1.Deployment) {
 	if d.Spec.Template.Annotations == nil {
@@ -0,0 +1,74 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hpa
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/log"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//List returns the list of horizontal pod autoscalers
+func List(ctx context.Context, namespace, labels string, c kubernetes.Interface) ([]autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	hpaList, err := c.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labels,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return hpaList.Items, nil
+}
+
+//Deploy creates or updates a horizontal pod autoscaler
+func Deploy(ctx context.Context, hpa *autoscalingv2beta2.HorizontalPodAutoscaler, c kubernetes.Interface) error {
+	hpaClient := c.AutoscalingV2beta2().HorizontalPodAutoscalers(hpa.Namespace)
+	old, err := hpaClient.Get(ctx, hpa.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("error getting horizontal pod autoscaler '%s': %s", hpa.Name, err)
+		}
+		if _, err := hpaClient.Create(ctx, hpa, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating horizontal pod autoscaler '%s': %s", hpa.Name, err)
+		}
+		return nil
+	}
+
+	hpa.ResourceVersion = old.ResourceVersion
+	if _, err := hpaClient.Update(ctx, hpa, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating horizontal pod autoscaler '%s': %s", hpa.Name, err)
+	}
+	return nil
+}
+
+//Destroy destroys a horizontal pod autoscaler
+func Destroy(ctx context.Context, name, namespace string, c kubernetes.Interface) error {
+	log.Infof("deleting horizontal pod autoscaler '%s'", name)
+	err := c.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting horizontal pod autoscaler '%s': %s", name, err)
+	}
+	log.Infof("horizontal pod autoscaler '%s' deleted", name)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	extensions "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -57,3 +58,64 @@ func Update(ctx context.Context, i *extensions.Ingress, c kubernetes.Interface)
 	}
 	return nil
 }
+
+//IsV1Enabled returns true if the cluster serves the networking.k8s.io/v1 ingresses, available since kubernetes 1.19.
+//Older clusters only serve the extensions/v1beta1 ingresses, removed in kubernetes 1.22
+func IsV1Enabled(ctx context.Context, c kubernetes.Interface) bool {
+	resources, err := c.Discovery().ServerResourcesForGroupVersion(networkingv1.SchemeGroupVersion.String())
+	if err != nil {
+		log.Infof("failed to get the networking.k8s.io/v1 resources: %s", err)
+		return false
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == "ingresses" {
+			return true
+		}
+	}
+	return false
+}
+
+//GetV1 returns a networking.k8s.io/v1 ingress
+func GetV1(ctx context.Context, name, namespace string, c kubernetes.Interface) (*networkingv1.Ingress, error) {
+	return c.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+//CreateV1 creates a networking.k8s.io/v1 ingress
+func CreateV1(ctx context.Context, i *networkingv1.Ingress, c kubernetes.Interface) error {
+	_, err := c.NetworkingV1().Ingresses(i.Namespace).Create(ctx, i, metav1.CreateOptions{})
+	return err
+}
+
+//UpdateV1 updates a networking.k8s.io/v1 ingress
+func UpdateV1(ctx context.Context, i *networkingv1.Ingress, c kubernetes.Interface) error {
+	_, err := c.NetworkingV1().Ingresses(i.Namespace).Update(ctx, i, metav1.UpdateOptions{})
+	return err
+}
+
+//ListV1 returns the list of networking.k8s.io/v1 ingresses
+func ListV1(ctx context.Context, namespace, labels string, c kubernetes.Interface) ([]networkingv1.Ingress, error) {
+	iList, err := c.NetworkingV1().Ingresses(namespace).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labels,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return iList.Items, nil
+}
+
+//DestroyV1 destroys a networking.k8s.io/v1 ingress
+func DestroyV1(ctx context.Context, name, namespace string, c kubernetes.Interface) error {
+	log.Infof("deleting ingress '%s'", name)
+	err := c.NetworkingV1().Ingresses(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting kubernetes ingress: %s", err)
+	}
+	log.Infof("Ingress '%s' deleted", name)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	extensions "k8s.io/api/extensions/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -160,3 +161,92 @@ func TestUpdate(t *testing.T) {
 		t.Fatalf("Didn't updated correctly")
 	}
 }
+
+func TestIsV1Enabled(t *testing.T) {
+	var tests = []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		expected  bool
+	}{
+		{
+			name: "v1",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}}},
+			},
+			expected: true,
+		},
+		{
+			name: "v1-without-ingresses",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "networkpolicies", Kind: "NetworkPolicy"}}},
+			},
+			expected: false,
+		},
+		{
+			name: "v1beta1",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "extensions/v1beta1", APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}}},
+			},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			clientset.Fake.Resources = tt.resources
+			if result := IsV1Enabled(context.Background(), clientset); result != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCRUDV1(t *testing.T) {
+	ctx := context.Background()
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fake",
+			Namespace: "test",
+			Labels:    map[string]string{"key": "value"},
+		},
+	}
+
+	clientset := fake.NewSimpleClientset()
+	if err := CreateV1(ctx, ingress, clientset); err != nil {
+		t.Fatal(err)
+	}
+	retrieved, err := GetV1(ctx, ingress.Name, ingress.Namespace, clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(retrieved, ingress) {
+		t.Fatalf("Didn't created correctly")
+	}
+
+	updatedIngress := ingress.DeepCopy()
+	updatedIngress.Labels["key2"] = "value2"
+	if err := UpdateV1(ctx, updatedIngress, clientset); err != nil {
+		t.Fatal(err)
+	}
+	iList, err := ListV1(ctx, ingress.Namespace, "key2=value2", clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(iList) != 1 || !reflect.DeepEqual(&iList[0], updatedIngress) {
+		t.Fatalf("Didn't updated correctly: %v", iList)
+	}
+
+	if err := DestroyV1(ctx, ingress.Name, ingress.Namespace, clientset); err != nil {
+		t.Fatal(err)
+	}
+	if err := DestroyV1(ctx, ingress.Name, ingress.Namespace, clientset); err != nil {
+		t.Fatalf("destroying a missing ingress failed: %s", err)
+	}
+	iList, err = ListV1(ctx, ingress.Namespace, "", clientset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(iList) != 0 {
+		t.Fatalf("Didn't destroyed correctly: %v", iList)
+	}
+}
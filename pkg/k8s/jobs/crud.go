@@ -0,0 +1,85 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/log"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//List returns the list of jobs
+func List(ctx context.Context, namespace, labels string, c kubernetes.Interface) ([]batchv1.Job, error) {
+	jobList, err := c.BatchV1().Jobs(namespace).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labels,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return jobList.Items, nil
+}
+
+//Get returns a job object given its name and namespace
+func Get(ctx context.Context, name, namespace string, c kubernetes.Interface) (*batchv1.Job, error) {
+	return c.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+//Create creates a job
+func Create(ctx context.Context, job *batchv1.Job, c kubernetes.Interface) error {
+	_, err := c.BatchV1().Jobs(job.Namespace).Create(ctx, job, metav1.CreateOptions{})
+	return err
+}
+
+//Destroy destroys a job and its pods
+func Destroy(ctx context.Context, name, namespace string, c kubernetes.Interface) error {
+	log.Infof("deleting job '%s'", name)
+	propagation := metav1.DeletePropagationBackground
+	err := c.BatchV1().Jobs(namespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting job '%s': %s", name, err)
+	}
+	log.Infof("job '%s' deleted", name)
+	return nil
+}
+
+//IsCompleted returns true if the job finished successfully
+func IsCompleted(job *batchv1.Job) bool {
+	return hasCondition(job, batchv1.JobComplete)
+}
+
+//IsFailed returns true if the job failed
+func IsFailed(job *batchv1.Job) bool {
+	return hasCondition(job, batchv1.JobFailed)
+}
+
+func hasCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == apiv1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -76,6 +76,18 @@ const (
 	// StackServiceNameLabel indicates the name of the stack service an object belongs to
 	StackServiceNameLabel = "stack.okteto.com/service"
 
+	// StackConfigChecksumAnnotation indicates the checksum of the configuration consumed by a stack service
+	StackConfigChecksumAnnotation = "stack.okteto.com/config-checksum"
+
+	// StackRestartedAtAnnotation indicates the timestamp when the pods of a stack service were forced to be recreated
+	StackRestartedAtAnnotation = "stack.okteto.com/restarted-at"
+
+	// StackJobChecksumAnnotation indicates the checksum of the pod template of a stack job, so unchanged jobs are not run again
+	StackJobChecksumAnnotation = "stack.okteto.com/job-checksum"
+
+	// StackVolumeNameLabel indicates the name of the named volume an object belongs to
+	StackVolumeNameLabel = "stack.okteto.com/volume"
+
 	// StackEndpointNameLabel indicates the name of the endpoint an object belongs to
 	StackEndpointNameLabel = "stack.okteto.com/endpoint"
 
@@ -85,6 +97,27 @@ const (
 	// OktetoAutoIngressAnnotation indicates an ingress must be created for a service
 	OktetoAutoIngressAnnotation = "dev.okteto.com/auto-ingress"
 
+	// IngressClassAnnotation indicates the ingress controller that must handle an ingress
+	IngressClassAnnotation = "kubernetes.io/ingress.class"
+
+	// CertManagerClusterIssuerAnnotation indicates the cert-manager cluster issuer requesting the certificate of an ingress
+	CertManagerClusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
+
+	// ReloaderAutoAnnotation indicates the workload is restarted by Reloader when the secrets and configmaps it consumes change
+	ReloaderAutoAnnotation = "reloader.stakater.com/auto"
+
+	// GKELoadBalancerTypeAnnotation indicates the type of the load balancer of a service in GKE
+	GKELoadBalancerTypeAnnotation = "networking.gke.io/load-balancer-type"
+
+	// AWSLoadBalancerInternalAnnotation indicates the load balancer of a service in EKS is internal
+	AWSLoadBalancerInternalAnnotation = "service.beta.kubernetes.io/aws-load-balancer-internal"
+
+	// AWSLoadBalancerTypeAnnotation indicates the type of the load balancer of a service in EKS
+	AWSLoadBalancerTypeAnnotation = "service.beta.kubernetes.io/aws-load-balancer-type"
+
+	// AzureLoadBalancerInternalAnnotation indicates the load balancer of a service in AKS is internal
+	AzureLoadBalancerInternalAnnotation = "service.beta.kubernetes.io/azure-load-balancer-internal"
+
 	// OktetoInstallerRunningLabel indicates the okteto installer is running on this resource
 	OktetoInstallerRunningLabel = "dev.okteto.com/installer-running"
 )
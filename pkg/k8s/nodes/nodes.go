@@ -0,0 +1,68 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/okteto/okteto/pkg/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//GetPlatforms returns the sorted list of platforms, with the format 'os/arch', of the nodes of the cluster
+func GetPlatforms(ctx context.Context, c kubernetes.Interface) ([]string, error) {
+	nodeList, err := c.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %s", err)
+	}
+
+	seen := map[string]bool{}
+	result := []string{}
+	for i := range nodeList.Items {
+		info := nodeList.Items[i].Status.NodeInfo
+		platform := fmt.Sprintf("%s/%s", info.OperatingSystem, info.Architecture)
+		if seen[platform] {
+			continue
+		}
+		seen[platform] = true
+		result = append(result, platform)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+//GetProvider returns the cloud provider of the nodes of the cluster, detected from their provider id, or an empty string if it is unknown
+func GetProvider(ctx context.Context, c kubernetes.Interface) (string, error) {
+	nodeList, err := c.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error listing nodes: %s", err)
+	}
+
+	for i := range nodeList.Items {
+		providerID := nodeList.Items[i].Spec.ProviderID
+		switch {
+		case strings.HasPrefix(providerID, "gce://"):
+			return model.ProviderGKE, nil
+		case strings.HasPrefix(providerID, "aws://"):
+			return model.ProviderEKS, nil
+		case strings.HasPrefix(providerID, "azure://"):
+			return model.ProviderAKS, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okteto/okteto/pkg/errors"
+	"github.com/okteto/okteto/pkg/log"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+//List returns the list of pod disruption budgets
+func List(ctx context.Context, namespace, labels string, c kubernetes.Interface) ([]policyv1beta1.PodDisruptionBudget, error) {
+	pdbList, err := c.PolicyV1beta1().PodDisruptionBudgets(namespace).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labels,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pdbList.Items, nil
+}
+
+//Deploy creates or updates a pod disruption budget
+func Deploy(ctx context.Context, pdb *policyv1beta1.PodDisruptionBudget, c kubernetes.Interface) error {
+	pdbClient := c.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace)
+	old, err := pdbClient.Get(ctx, pdb.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("error getting pod disruption budget '%s': %s", pdb.Name, err)
+		}
+		if _, err := pdbClient.Create(ctx, pdb, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating pod disruption budget '%s': %s", pdb.Name, err)
+		}
+		return nil
+	}
+
+	pdb.ResourceVersion = old.ResourceVersion
+	if _, err := pdbClient.Update(ctx, pdb, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error updating pod disruption budget '%s': %s", pdb.Name, err)
+	}
+	return nil
+}
+
+//Destroy destroys a pod disruption budget
+func Destroy(ctx context.Context, name, namespace string, c kubernetes.Interface) error {
+	log.Infof("deleting pod disruption budget '%s'", name)
+	err := c.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting pod disruption budget '%s': %s", name, err)
+	}
+	log.Infof("pod disruption budget '%s' deleted", name)
+	return nil
+}
@@ -426,6 +426,11 @@ func waitUntilRunning(ctx context.Context, namespace, selector string, c *kubern
 	return fmt.Errorf("Pod(s) %s didn't restart after 60 seconds", strings.Join(pods, ","))
 }
 
+//IsReady returns true if the pod is running and ready
+func IsReady(p *apiv1.Pod) bool {
+	return isRunning(p)
+}
+
 func isRunning(p *apiv1.Pod) bool {
 	if p.Status.Phase != apiv1.PodRunning {
 		return false
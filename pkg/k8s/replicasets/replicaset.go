@@ -16,7 +16,9 @@ package replicasets
 import (
 	"context"
 	"fmt"
+	"strconv"
 
+	"github.com/okteto/okteto/pkg/k8s/labels"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -49,3 +51,48 @@ func GetReplicaSetByDeployment(ctx context.Context, d *appsv1.Deployment, labels
 	}
 	return nil, nil
 }
+
+// GetPreviousReplicaSetByDeployment given a deployment, returns the replica set of its previous revision, or nil if there is none
+func GetPreviousReplicaSetByDeployment(ctx context.Context, d *appsv1.Deployment, c kubernetes.Interface) (*appsv1.ReplicaSet, error) {
+	current, err := strconv.ParseInt(d.Annotations[deploymentRevisionAnnotation], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the revision of deployment '%s': %s", d.Name, err)
+	}
+
+	selector := labels.TransformLabelsToSelector(d.Spec.Selector.MatchLabels)
+	rsList, err := c.AppsV1().ReplicaSets(d.Namespace).List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: selector,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replicaset using %s: %s", selector, err)
+	}
+
+	var result *appsv1.ReplicaSet
+	var resultRevision int64
+	for i := range rsList.Items {
+		if !isOwnedBy(&rsList.Items[i], d) {
+			continue
+		}
+		revision, err := strconv.ParseInt(rsList.Items[i].Annotations[deploymentRevisionAnnotation], 10, 64)
+		if err != nil || revision >= current {
+			continue
+		}
+		if revision > resultRevision {
+			result = &rsList.Items[i]
+			resultRevision = revision
+		}
+	}
+	return result, nil
+}
+
+func isOwnedBy(rs *appsv1.ReplicaSet, d *appsv1.Deployment) bool {
+	for _, or := range rs.OwnerReferences {
+		if or.UID == d.UID {
+			return true
+		}
+	}
+	return false
+}
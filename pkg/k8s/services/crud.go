@@ -62,6 +62,15 @@ func Create(ctx context.Context, s *apiv1.Service, c *kubernetes.Clientset) erro
 			return fmt.Errorf("error creating kubernetes service: %s", err)
 		}
 		log.Infof("created service '%s'", s.Name)
+	} else if (old.Spec.ClusterIP == apiv1.ClusterIPNone) != (s.Spec.ClusterIP == apiv1.ClusterIPNone) {
+		log.Infof("recreating service '%s' to change its cluster ip", s.Name)
+		if err := sClient.Delete(ctx, s.Name, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("error deleting kubernetes service: %s", err)
+		}
+		if _, err := sClient.Create(ctx, s, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("error creating kubernetes service: %s", err)
+		}
+		log.Infof("recreated service '%s'", s.Name)
 	} else {
 		log.Infof("updating service '%s'", s.Name)
 		old.Spec.Ports = s.Spec.Ports
@@ -46,6 +46,18 @@ func Get(ctx context.Context, name, namespace string, c kubernetes.Interface) (*
 	return sfs, nil
 }
 
+//IsRolledOut returns true if all the pods of a statefulset are updated to its latest revision and ready
+func IsRolledOut(sfs *appsv1.StatefulSet) bool {
+	if sfs.Status.ObservedGeneration < sfs.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if sfs.Spec.Replicas != nil {
+		replicas = *sfs.Spec.Replicas
+	}
+	return sfs.Status.UpdateRevision == sfs.Status.CurrentRevision && sfs.Status.UpdatedReplicas == replicas && sfs.Status.ReadyReplicas == replicas
+}
+
 //Create creates a statefulset
 func Create(ctx context.Context, sfs *appsv1.StatefulSet, c *kubernetes.Clientset) error {
 	_, err := c.AppsV1().StatefulSets(sfs.Namespace).Create(ctx, sfs, metav1.CreateOptions{})
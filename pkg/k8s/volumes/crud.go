@@ -44,6 +44,20 @@ func List(ctx context.Context, namespace, labels string, c kubernetes.Interface)
 	return vList.Items, nil
 }
 
+//Get returns a persistent volume claim by name
+func Get(ctx context.Context, name, namespace string, c kubernetes.Interface) (*apiv1.PersistentVolumeClaim, error) {
+	return c.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+//CreateClaim creates a persistent volume claim
+func CreateClaim(ctx context.Context, pvc *apiv1.PersistentVolumeClaim, c kubernetes.Interface) error {
+	log.Infof("creating volume claim '%s'", pvc.Name)
+	if _, err := c.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating kubernetes volume claim: %s", err)
+	}
+	return nil
+}
+
 //Create deploys the volume claim for a given development container
 func Create(ctx context.Context, dev *model.Dev, c *kubernetes.Clientset) error {
 	vClient := c.CoreV1().PersistentVolumeClaims(dev.Namespace)
@@ -95,6 +109,35 @@ func checkPVCValues(pvc *apiv1.PersistentVolumeClaim, dev *model.Dev) error {
 
 }
 
+//Expand increases the storage request of a persistent volume claim, if its storage class allows volume expansion
+func Expand(ctx context.Context, pvc *apiv1.PersistentVolumeClaim, size resource.Quantity, c kubernetes.Interface) error {
+	currentSize := pvc.Spec.Resources.Requests[apiv1.ResourceStorage]
+	switch size.Cmp(currentSize) {
+	case 0:
+		return nil
+	case -1:
+		return fmt.Errorf("the size of volume '%s' can't be decreased from '%s' to '%s'", pvc.Name, currentSize.String(), size.String())
+	}
+
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return fmt.Errorf("the size of volume '%s' can't be increased: it doesn't have a storage class", pvc.Name)
+	}
+	sc, err := c.StorageV1().StorageClasses().Get(ctx, *pvc.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting storage class '%s': %s", *pvc.Spec.StorageClassName, err)
+	}
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		return fmt.Errorf("the size of volume '%s' can't be increased: storage class '%s' doesn't allow volume expansion", pvc.Name, sc.Name)
+	}
+
+	log.Infof("expanding volume '%s' from '%s' to '%s'", pvc.Name, currentSize.String(), size.String())
+	pvc.Spec.Resources.Requests[apiv1.ResourceStorage] = size
+	if _, err := c.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("error expanding volume '%s': %s", pvc.Name, err)
+	}
+	return nil
+}
+
 //DestroyDev destroys the persistent volume claim for a given development container
 func DestroyDev(ctx context.Context, dev *model.Dev, c *kubernetes.Clientset) error {
 	return Destroy(ctx, dev.GetVolumeName(), dev.Namespace, c, dev.Timeout)
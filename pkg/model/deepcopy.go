@@ -0,0 +1,303 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+)
+
+//DeepCopy returns a copy of the stack not sharing any map, slice or pointer with it,
+//so the copy can be translated while other goroutines read the original
+func (s *Stack) DeepCopy() *Stack {
+	if s == nil {
+		return nil
+	}
+	result := *s
+	result.Labels = copyStringMap(s.Labels)
+	result.Annotations = copyStringMap(s.Annotations)
+	if s.Services != nil {
+		result.Services = make(map[string]Service, len(s.Services))
+		for name, svc := range s.Services {
+			result.Services[name] = *svc.DeepCopy()
+		}
+	}
+	if s.Endpoints != nil {
+		result.Endpoints = make(map[string][]Endpoint, len(s.Endpoints))
+		for name, endpoints := range s.Endpoints {
+			result.Endpoints[name] = append([]Endpoint{}, endpoints...)
+		}
+	}
+	if s.Volumes != nil {
+		result.Volumes = make(map[string]VolumeSpec, len(s.Volumes))
+		for name, volume := range s.Volumes {
+			volume.Size = volume.Size.DeepCopy()
+			volume.Labels = copyStringMap(volume.Labels)
+			volume.Persist = copyBool(volume.Persist)
+			result.Volumes[name] = volume
+		}
+	}
+	if s.Manifest != nil {
+		result.Manifest = append([]byte{}, s.Manifest...)
+	}
+	result.Overrides.Set = copyStrings(s.Overrides.Set)
+	result.Overrides.Commands = copyStrings(s.Overrides.Commands)
+	result.Overrides.Images = copyStringMap(s.Overrides.Images)
+	return &result
+}
+
+//DeepCopy returns a copy of the service not sharing any map, slice or pointer with it
+func (svc *Service) DeepCopy() *Service {
+	if svc == nil {
+		return nil
+	}
+	result := *svc
+	result.Labels = copyStringMap(svc.Labels)
+	result.Annotations = copyStringMap(svc.Annotations)
+	if svc.Build != nil {
+		build := *svc.Build
+		build.CacheFrom = copyStrings(svc.Build.CacheFrom)
+		build.Args = copyEnvVars(svc.Build.Args)
+		result.Build = &build
+	}
+	result.Entrypoint.Values = copyStrings(svc.Entrypoint.Values)
+	result.Command.Values = copyStrings(svc.Command.Values)
+	result.Args.Values = copyStrings(svc.Args.Values)
+	if svc.Platforms != nil {
+		result.Platforms = make(map[string]PlatformOverride, len(svc.Platforms))
+		for platform, override := range svc.Platforms {
+			override.Command.Values = copyStrings(override.Command.Values)
+			override.Args.Values = copyStrings(override.Args.Values)
+			result.Platforms[platform] = override
+		}
+	}
+	result.Environment = copyEnvVars(svc.Environment)
+	result.EnvFiles = copyStrings(svc.EnvFiles)
+	if svc.EnvFrom != nil {
+		result.EnvFrom = make([]EnvFromSource, len(svc.EnvFrom))
+		for i, source := range svc.EnvFrom {
+			if source.SecretRef != nil {
+				ref := *source.SecretRef
+				source.SecretRef = &ref
+			}
+			if source.ConfigMapRef != nil {
+				ref := *source.ConfigMapRef
+				source.ConfigMapRef = &ref
+			}
+			result.EnvFrom[i] = source
+		}
+	}
+	result.CapAdd = copyCapabilities(svc.CapAdd)
+	result.CapDrop = copyCapabilities(svc.CapDrop)
+	result.Devices = copyStrings(svc.Devices)
+	if svc.GPU != nil {
+		gpu := *svc.GPU
+		result.GPU = &gpu
+	}
+	if svc.SessionAffinity != nil {
+		sessionAffinity := *svc.SessionAffinity
+		sessionAffinity.Timeout = copyInt32(svc.SessionAffinity.Timeout)
+		result.SessionAffinity = &sessionAffinity
+	}
+	if svc.Healthcheck != nil {
+		healthcheck := *svc.Healthcheck
+		if svc.Healthcheck.HTTP != nil {
+			http := *svc.Healthcheck.HTTP
+			healthcheck.HTTP = &http
+		}
+		healthcheck.Test = copyStrings(svc.Healthcheck.Test)
+		result.Healthcheck = &healthcheck
+	}
+	if svc.Ports != nil {
+		result.Ports = append([]Port{}, svc.Ports...)
+	}
+	if svc.Expose != nil {
+		result.Expose = append([]Port{}, svc.Expose...)
+	}
+	if svc.Endpoints != nil {
+		result.Endpoints = append([]ServiceEndpoint{}, svc.Endpoints...)
+	}
+	result.Volumes = copyStrings(svc.Volumes)
+	if svc.NamedVolumes != nil {
+		result.NamedVolumes = append([]NamedVolumeMount{}, svc.NamedVolumes...)
+	}
+	result.Tmpfs = copyStrings(svc.Tmpfs)
+	if svc.VolumePopulator != nil {
+		populator := *svc.VolumePopulator
+		result.VolumePopulator = &populator
+	}
+	result.StopGracePeriod = copyInt64(svc.StopGracePeriod)
+	result.Resources = svc.Resources.deepCopy()
+	result.MemSwappiness = copyInt64(svc.MemSwappiness)
+	result.Deploy = svc.Deploy.deepCopy()
+	if svc.DependsOn != nil {
+		result.DependsOn = make(DependsOn, len(svc.DependsOn))
+		for name, condition := range svc.DependsOn {
+			result.DependsOn[name] = condition
+		}
+	}
+	return &result
+}
+
+//DeepCopy returns a copy of the quantity not sharing its internal representation
+func (q Quantity) DeepCopy() Quantity {
+	return Quantity{Value: q.Value.DeepCopy()}
+}
+
+func (r StackResources) deepCopy() StackResources {
+	return StackResources{
+		Limits:   r.Limits.deepCopy(),
+		Requests: r.Requests.deepCopy(),
+	}
+}
+
+func (r ServiceResources) deepCopy() ServiceResources {
+	result := r
+	result.CPU = r.CPU.DeepCopy()
+	result.Memory = r.Memory.DeepCopy()
+	result.EphemeralStorage = r.EphemeralStorage.DeepCopy()
+	result.Storage.Size = r.Storage.Size.DeepCopy()
+	return result
+}
+
+func (d *DeployInfo) deepCopy() *DeployInfo {
+	if d == nil {
+		return nil
+	}
+	result := *d
+	result.Labels = copyStringMap(d.Labels)
+	if d.Autoscaling != nil {
+		autoscaling := *d.Autoscaling
+		if d.Autoscaling.Metrics != nil {
+			autoscaling.Metrics = make([]AutoscalingMetric, len(d.Autoscaling.Metrics))
+			for i, m := range d.Autoscaling.Metrics {
+				m.Selector = copyStringMap(m.Selector)
+				m.Value = m.Value.DeepCopy()
+				m.AverageValue = m.AverageValue.DeepCopy()
+				autoscaling.Metrics[i] = m
+			}
+		}
+		if d.Autoscaling.Behavior != nil {
+			autoscaling.Behavior = &AutoscalingBehavior{
+				ScaleUp:   d.Autoscaling.Behavior.ScaleUp.deepCopy(),
+				ScaleDown: d.Autoscaling.Behavior.ScaleDown.deepCopy(),
+			}
+		}
+		result.Autoscaling = &autoscaling
+	}
+	if d.PDB != nil {
+		pdb := *d.PDB
+		result.PDB = &pdb
+	}
+	if d.Resources != nil {
+		result.Resources = &DeployResources{
+			Limits:       d.Resources.Limits.deepCopy(),
+			Reservations: d.Resources.Reservations.deepCopy(),
+		}
+	}
+	return &result
+}
+
+func (r DeployResourceList) deepCopy() DeployResourceList {
+	return DeployResourceList{
+		CPUs:   r.CPUs.DeepCopy(),
+		Memory: r.Memory.DeepCopy(),
+	}
+}
+
+func (r *AutoscalingRules) deepCopy() *AutoscalingRules {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.StabilizationWindow = copyInt32(r.StabilizationWindow)
+	if r.Policies != nil {
+		result.Policies = append([]AutoscalingPolicy{}, r.Policies...)
+	}
+	return &result
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+func copyEnvVars(values []EnvVar) []EnvVar {
+	if values == nil {
+		return nil
+	}
+	result := append([]EnvVar{}, values...)
+	for i := range result {
+		if result[i].ValueFrom != nil {
+			result[i].ValueFrom = result[i].ValueFrom.deepCopy()
+		}
+	}
+	return result
+}
+
+func (source *EnvVarSource) deepCopy() *EnvVarSource {
+	result := &EnvVarSource{}
+	if source.SecretKeyRef != nil {
+		ref := *source.SecretKeyRef
+		result.SecretKeyRef = &ref
+	}
+	if source.ConfigMapKeyRef != nil {
+		ref := *source.ConfigMapKeyRef
+		result.ConfigMapKeyRef = &ref
+	}
+	return result
+}
+
+func copyCapabilities(values []apiv1.Capability) []apiv1.Capability {
+	if values == nil {
+		return nil
+	}
+	return append([]apiv1.Capability{}, values...)
+}
+
+func copyBool(value *bool) *bool {
+	if value == nil {
+		return nil
+	}
+	result := *value
+	return &result
+}
+
+func copyInt32(value *int32) *int32 {
+	if value == nil {
+		return nil
+	}
+	result := *value
+	return &result
+}
+
+func copyInt64(value *int64) *int64 {
+	if value == nil {
+		return nil
+	}
+	result := *value
+	return &result
+}
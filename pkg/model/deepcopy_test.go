@@ -0,0 +1,143 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestStack_DeepCopy(t *testing.T) {
+	manifest := []byte(`name: voting-app
+services:
+  vote:
+    image: okteto/vote:1
+    command: ["python", "app.py"]
+    annotations:
+      team: vote
+    environment:
+      - OPTION_A=Cats
+      - name: TOKEN
+        valueFrom:
+          secretKeyRef:
+            name: vote
+            key: token
+    env_from:
+      - configMapRef:
+          name: vote
+    ports:
+      - 8080:80
+    volumes:
+      - data:/data
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost"]
+    resources:
+      limits:
+        cpu: 500m
+    deploy:
+      labels:
+        app: vote
+      autoscaling:
+        min: 1
+        max: 3
+        behavior:
+          scale_down:
+            stabilization_window: 300
+endpoints:
+  vote:
+    - path: /
+      service: vote
+      port: 8080
+volumes:
+  data:
+    labels:
+      tier: db`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Manifest = manifest
+
+	result := s.DeepCopy()
+	if !reflect.DeepEqual(result, s) {
+		t.Fatalf("wrong copy: %+v", result)
+	}
+
+	vote := result.Services["vote"]
+	vote.Annotations["team"] = "other"
+	vote.Environment[0].Value = "Dogs"
+	vote.Environment[1].ValueFrom.SecretKeyRef.Key = "other"
+	vote.EnvFrom[0].ConfigMapRef.Name = "other"
+	vote.Command.Values[0] = "ruby"
+	vote.Ports[0].Port = 9090
+	vote.NamedVolumes[0].MountPath = "/other"
+	vote.Healthcheck.Test[0] = "NONE"
+	vote.Resources.Limits.CPU.Value.Add(resource.MustParse("1"))
+	vote.Deploy.Labels["app"] = "other"
+	*vote.Deploy.Autoscaling.Behavior.ScaleDown.StabilizationWindow = 60
+	result.Endpoints["vote"][0].Port = 9090
+	result.Volumes["data"].Labels["tier"] = "other"
+	result.Manifest[0] = 'N'
+
+	original := s.Services["vote"]
+	if original.Annotations["team"] != "vote" {
+		t.Errorf("annotations shared: %v", original.Annotations)
+	}
+	if original.Environment[0].Value != "Cats" {
+		t.Errorf("environment shared: %v", original.Environment)
+	}
+	if original.Environment[1].ValueFrom.SecretKeyRef.Key != "token" {
+		t.Errorf("environment sources shared: %v", original.Environment[1].ValueFrom.SecretKeyRef)
+	}
+	if original.EnvFrom[0].ConfigMapRef.Name != "vote" {
+		t.Errorf("env_from shared: %v", original.EnvFrom[0].ConfigMapRef)
+	}
+	if original.Command.Values[0] != "python" {
+		t.Errorf("command shared: %v", original.Command.Values)
+	}
+	if original.Ports[0].Port != 8080 {
+		t.Errorf("ports shared: %v", original.Ports)
+	}
+	if original.NamedVolumes[0].MountPath != "/data" {
+		t.Errorf("named volumes shared: %v", original.NamedVolumes)
+	}
+	if original.Healthcheck.Test[0] != "CMD" {
+		t.Errorf("healthcheck shared: %v", original.Healthcheck.Test)
+	}
+	if original.Resources.Limits.CPU.Value.Cmp(resource.MustParse("500m")) != 0 {
+		t.Errorf("resources shared: %s", original.Resources.Limits.CPU.Value.String())
+	}
+	if original.Deploy.Labels["app"] != "vote" {
+		t.Errorf("deploy labels shared: %v", original.Deploy.Labels)
+	}
+	if *original.Deploy.Autoscaling.Behavior.ScaleDown.StabilizationWindow != 300 {
+		t.Errorf("autoscaling behavior shared: %d", *original.Deploy.Autoscaling.Behavior.ScaleDown.StabilizationWindow)
+	}
+	if s.Endpoints["vote"][0].Port != 8080 {
+		t.Errorf("endpoints shared: %v", s.Endpoints["vote"])
+	}
+	if s.Volumes["data"].Labels["tier"] != "db" {
+		t.Errorf("volumes shared: %v", s.Volumes["data"].Labels)
+	}
+	if s.Manifest[0] != 'n' {
+		t.Errorf("manifest shared: %s", s.Manifest)
+	}
+
+	var empty *Stack
+	if empty.DeepCopy() != nil {
+		t.Errorf("wrong copy of a nil stack")
+	}
+}
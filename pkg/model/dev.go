@@ -176,6 +176,7 @@ type BuildInfo struct {
 	CacheFrom  []string `yaml:"cache_from,omitempty"`
 	Target     string   `yaml:"target,omitempty"`
 	Args       []EnvVar `yaml:"args,omitempty"`
+	Network    string   `yaml:"network,omitempty"`
 }
 
 // Volume represents a volume in the development container
@@ -235,8 +236,22 @@ type Capabilities struct {
 
 // EnvVar represents an environment value. When loaded, it will expand from the current env
 type EnvVar struct {
-	Name  string `yaml:"name,omitempty"`
-	Value string `yaml:"value,omitempty"`
+	Name      string        `yaml:"name,omitempty"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty"`
+}
+
+// EnvVarSource represents the key of a kubernetes secret or configmap used as the value of an environment variable in a stack
+type EnvVarSource struct {
+	SecretKeyRef    *EnvKeyRef `yaml:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *EnvKeyRef `yaml:"configMapKeyRef,omitempty"`
+}
+
+// EnvKeyRef represents a key of a kubernetes secret or configmap
+type EnvKeyRef struct {
+	Name     string `yaml:"name,omitempty"`
+	Key      string `yaml:"key,omitempty"`
+	Optional bool   `yaml:"optional,omitempty"`
 }
 
 // Secret represents a development secret
@@ -619,6 +634,24 @@ func (dev *Dev) validate() error {
 		return err
 	}
 
+	for _, e := range dev.Environment {
+		if e.ValueFrom != nil {
+			return fmt.Errorf("Invalid environment variable '%s': 'valueFrom' is only supported in stacks", e.Name)
+		}
+	}
+
+	if dev.Image != nil {
+		if err := validateBuildArgs(dev.Image.Args); err != nil {
+			return fmt.Errorf("Invalid 'image.args': %s", err)
+		}
+	}
+
+	if dev.Push != nil {
+		if err := validateBuildArgs(dev.Push.Args); err != nil {
+			return fmt.Errorf("Invalid 'push.args': %s", err)
+		}
+	}
+
 	if err := dev.validatePersistentVolume(); err != nil {
 		return err
 	}
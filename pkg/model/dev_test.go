@@ -761,6 +761,50 @@ func Test_validate(t *testing.T) {
         - docs:/docs`),
 			expectErr: false,
 		},
+		{
+			name: "image-args-value-from",
+			manifest: []byte(`
+      name: deployment
+      image:
+        name: okteto/dev
+        args:
+          - name: TOKEN
+            valueFrom:
+              secretKeyRef:
+                name: secret
+                key: token
+      sync:
+        - .:/app`),
+			expectErr: true,
+		},
+		{
+			name: "push-args-value-from",
+			manifest: []byte(`
+      name: deployment
+      push:
+        name: okteto/dev
+        args:
+          - name: TOKEN
+            valueFrom:
+              configMapKeyRef:
+                name: config
+                key: token
+      sync:
+        - .:/app`),
+			expectErr: true,
+		},
+		{
+			name: "image-args",
+			manifest: []byte(`
+      name: deployment
+      image:
+        name: okteto/dev
+        args:
+          - TOKEN=value
+      sync:
+        - .:/app`),
+			expectErr: false,
+		},
 		{
 			name: "external-volumes",
 			manifest: []byte(`
@@ -0,0 +1,171 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+//ImageDigestEnv is the variable replaced by the digest of the image built for a stack service
+const ImageDigestEnv = "OKTETO_IMAGE_DIGEST"
+
+//envLookup returns the value of an environment variable and whether it is set, like os.LookupEnv
+type envLookup func(name string) (string, bool)
+
+//ExpandStackEnv expands the environment variables of a stack value with the interpolation rules of docker compose.
+//Unlike ExpandEnv, used by development manifests, it supports "${VAR:?msg}" to require a variable and "$$" to escape "$"
+func ExpandStackEnv(value string) (string, error) {
+	result, err := interpolateEnv(value, os.LookupEnv)
+	if err != nil {
+		return "", fmt.Errorf("error expanding environment on '%s': %s", value, err.Error())
+	}
+	return result, nil
+}
+
+//expandStackEnvValue expands the value of an environment variable of a stack service like ExpandStackEnv,
+//keeping "${OKTETO_IMAGE_DIGEST}" to be replaced when the image of the service is built
+func expandStackEnvValue(value string) (string, error) {
+	result, err := interpolateEnv(value, func(name string) (string, bool) {
+		if name == ImageDigestEnv {
+			return "${" + ImageDigestEnv + "}", true
+		}
+		return os.LookupEnv(name)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error expanding environment on '%s': %s", value, err.Error())
+	}
+	return result, nil
+}
+
+//interpolateEnv expands the environment variables of a value in a single pass, following the interpolation rules of docker compose:
+//"$VAR" and "${VAR}" are replaced by the value of VAR, "${VAR:-default}" and "${VAR-default}" fall back to default when VAR is unset or empty,
+//"${VAR:?msg}" and "${VAR?msg}" fail with msg, "${VAR:+alt}" and "${VAR+alt}" are replaced by alt when VAR is set, and "$$" is a literal "$".
+//Defaults, messages and alternatives can contain other variables, which are only expanded when used
+func interpolateEnv(value string, lookup envLookup) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+		next := value[i+1]
+		switch {
+		case next == '$':
+			sb.WriteByte('$')
+			i++
+		case next == '{':
+			end := findClosingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("missing closing brace in '%s'", value[i:])
+			}
+			expanded, err := interpolateBracedEnv(value[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(expanded)
+			i = end
+		case isEnvNameStart(next):
+			end := i + 2
+			for end < len(value) && isEnvNameChar(value[end]) {
+				end++
+			}
+			v, _ := lookup(value[i+1 : end])
+			sb.WriteString(v)
+			i = end - 1
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String(), nil
+}
+
+//interpolateBracedEnv expands the expression between the braces of "${...}"
+func interpolateBracedEnv(expr string, lookup envLookup) (string, error) {
+	end := 0
+	for end < len(expr) && isEnvNameChar(expr[end]) {
+		end++
+	}
+	name := expr[:end]
+	if name == "" || !isEnvNameStart(name[0]) {
+		return "", fmt.Errorf("invalid variable name in '${%s}'", expr)
+	}
+	value, isSet := lookup(name)
+	modifier := expr[end:]
+	if modifier == "" {
+		return value, nil
+	}
+
+	checkEmpty := strings.HasPrefix(modifier, ":")
+	modifier = strings.TrimPrefix(modifier, ":")
+	if modifier == "" {
+		return "", fmt.Errorf("invalid variable expression '${%s}'", expr)
+	}
+	isMissing := !isSet || (checkEmpty && value == "")
+	word := modifier[1:]
+	switch modifier[0] {
+	case '-':
+		if isMissing {
+			return interpolateEnv(word, lookup)
+		}
+		return value, nil
+	case '?':
+		if !isMissing {
+			return value, nil
+		}
+		msg, err := interpolateEnv(word, lookup)
+		if err != nil {
+			return "", err
+		}
+		if msg == "" {
+			return "", fmt.Errorf("required variable '%s' is missing a value", name)
+		}
+		return "", fmt.Errorf("required variable '%s' is missing a value: %s", name, msg)
+	case '+':
+		if isMissing {
+			return "", nil
+		}
+		return interpolateEnv(word, lookup)
+	}
+	return "", fmt.Errorf("invalid variable expression '${%s}'", expr)
+}
+
+//findClosingBrace returns the index of the brace closing the expression starting at start, skipping nested expressions and escaped dollars
+func findClosingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '$':
+			i++
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || (c >= '0' && c <= '9')
+}
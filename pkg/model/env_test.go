@@ -0,0 +1,98 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+)
+
+func Test_interpolateEnv(t *testing.T) {
+	env := map[string]string{
+		"BAR":   "bar",
+		"EMPTY": "",
+		"NAME":  "BAR",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "no-var", value: "value", expected: "value"},
+		{name: "var", value: "value-${BAR}-value", expected: "value-bar-value"},
+		{name: "unbraced-var", value: "value-$BAR/value", expected: "value-bar/value"},
+		{name: "unset-var", value: "value-${FOO}-value", expected: "value--value"},
+		{name: "default-unset", value: "${FOO:-foo}", expected: "foo"},
+		{name: "default-empty", value: "${EMPTY:-foo}", expected: "foo"},
+		{name: "default-set", value: "${BAR:-foo}", expected: "bar"},
+		{name: "default-unset-only", value: "${EMPTY-foo}", expected: ""},
+		{name: "default-empty-word", value: "${FOO:-}", expected: ""},
+		{name: "nested-default", value: "${FOO:-${BAZ:-${BAR}}}-value", expected: "bar-value"},
+		{name: "nested-default-text", value: "${FOO:-prefix-${BAR}-suffix}", expected: "prefix-bar-suffix"},
+		{name: "required-set", value: "${BAR:?BAR is required}", expected: "bar"},
+		{name: "required-unset", value: "${FOO:?FOO is required}", wantErr: true},
+		{name: "required-empty", value: "${EMPTY:?EMPTY is required}", wantErr: true},
+		{name: "required-empty-allowed", value: "${EMPTY?EMPTY is required}", expected: ""},
+		{name: "required-without-message", value: "${FOO:?}", wantErr: true},
+		{name: "required-in-unused-default", value: "${BAR:-${FOO:?FOO is required}}", expected: "bar"},
+		{name: "required-in-used-default", value: "${BAZ:-${FOO:?FOO is required}}", wantErr: true},
+		{name: "alternative-set", value: "${BAR:+alt}", expected: "alt"},
+		{name: "alternative-unset", value: "${FOO:+alt}", expected: ""},
+		{name: "escaped", value: "$${BAR}-$$BAR", expected: "${BAR}-$BAR"},
+		{name: "escaped-in-default", value: "${FOO:-$${BAR}}", expected: "${BAR}"},
+		{name: "escaped-before-var", value: "$$$BAR", expected: "$bar"},
+		{name: "escaped-not-expanded-again", value: "$${NAME}", expected: "${NAME}"},
+		{name: "lone-dollar", value: "price: 5$ or $-1", expected: "price: 5$ or $-1"},
+		{name: "trailing-dollar", value: "value$", expected: "value$"},
+		{name: "missing-brace", value: "${BAR", wantErr: true},
+		{name: "invalid-name", value: "${1BAR}", wantErr: true},
+		{name: "empty-name", value: "${}", wantErr: true},
+		{name: "invalid-modifier", value: "${BAR:=foo}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := interpolateEnv(tt.value, lookup)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got '%s'", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("got '%s', expected '%s'", result, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_interpolateEnvRequiredMessage(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		return "", false
+	}
+	_, err := interpolateEnv("${TAG:?set TAG to the version to deploy}", lookup)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	expected := "required variable 'TAG' is missing a value: set TAG to the version to deploy"
+	if err.Error() != expected {
+		t.Errorf("got '%s', expected '%s'", err.Error(), expected)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	//LintSeverityWarning flags a configuration likely to cause problems in production
+	LintSeverityWarning = "warning"
+
+	//LintSeverityInfo flags a configuration that could be improved
+	LintSeverityInfo = "info"
+
+	//LintRuleResourceLimits flags services without cpu or memory limits
+	LintRuleResourceLimits = "resource-limits"
+
+	//LintRuleLatestImage flags services running an image without an explicit tag
+	LintRuleLatestImage = "latest-image"
+
+	//LintRulePublicHealthCheck flags public services without health checks
+	LintRulePublicHealthCheck = "public-healthcheck"
+
+	//LintRulePublicReplicas flags public services with a single replica
+	LintRulePublicReplicas = "public-replicas"
+
+	//LintRulePDB flags services with several replicas and no disruption budget
+	LintRulePDB = "pdb"
+
+	//LintRuleRootUser flags services that may run as root
+	LintRuleRootUser = "root-user"
+
+	//LintRuleRestart flags deployments with a restart policy that kubernetes doesn't support
+	LintRuleRestart = "restart"
+
+	//LintRuleReservedEnv flags environment variables shadowing the ones injected by kubernetes
+	LintRuleReservedEnv = "reserved-env"
+
+	//LintRulePrivileged flags services with full access to the host of their pods
+	LintRulePrivileged = "privileged"
+
+	//LintRuleDevices flags services mapping devices without being privileged
+	LintRuleDevices = "devices"
+
+	//LintRuleMemoryOptions flags compose memory options that kubernetes doesn't support
+	LintRuleMemoryOptions = "memory-options"
+
+	//LintRuleReload flags jobs and cronjobs with 'reload', which is ignored
+	LintRuleReload = "reload"
+
+	//LintRulePublicExpose flags public services with ports in 'expose', which are reachable through their load balancer
+	LintRulePublicExpose = "public-expose"
+
+	//LintRuleGlobalReplicas flags global services with 'replicas', which is ignored
+	LintRuleGlobalReplicas = "global-replicas"
+
+	//LintRulePDBReplicas flags services with a single replica and a disruption budget
+	LintRulePDBReplicas = "pdb-replicas"
+)
+
+//LintIssue represents a best-practice issue of an okteto stack service
+type LintIssue struct {
+	Service  string
+	Rule     string
+	Severity string
+	Message  string
+}
+
+//Lint returns the best-practice issues of the stack services, sorted by service and rule.
+//Unlike validation, the issues don't prevent the stack from being deployed
+func (s *Stack) Lint() []LintIssue {
+	result := []LintIssue{}
+	for name, svc := range s.Services {
+		result = append(result, svc.lint(name, s)...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Service != result[j].Service {
+			return result[i].Service < result[j].Service
+		}
+		return result[i].Rule < result[j].Rule
+	})
+	return result
+}
+
+func (svc *Service) lint(name string, s *Stack) []LintIssue {
+	result := []LintIssue{}
+	add := func(rule, severity, format string, args ...interface{}) {
+		result = append(result, LintIssue{Service: name, Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if svc.Resources.Limits.CPU.Value.IsZero() || svc.Resources.Limits.Memory.Value.IsZero() {
+		add(LintRuleResourceLimits, LintSeverityWarning, "set 'resources.limits.cpu' and 'resources.limits.memory' so the service can't starve the other pods of its nodes")
+	}
+	if svc.Build == nil && svc.Image != "" && !hasExplicitImageTag(svc.Image) {
+		add(LintRuleLatestImage, LintSeverityWarning, "the image '%s' has no explicit tag: pin a tag or a digest to make deployments reproducible", svc.Image)
+	}
+	if bool(svc.Public) && svc.Healthcheck == nil && !svc.Healthchecks {
+		add(LintRulePublicHealthCheck, LintSeverityWarning, "the service is public but has no 'healthcheck': traffic is sent to its pods before they are ready")
+	}
+	if bool(svc.Public) && !svc.IsGlobal() && svc.GetMinReplicas() <= 1 {
+		add(LintRulePublicReplicas, LintSeverityInfo, "the service is public but runs a single replica: it is unavailable while its pod is restarted or rescheduled")
+	}
+	if !svc.IsJob() && !svc.IsCronJob() && !svc.IsGlobal() && svc.GetMinReplicas() > 1 && (svc.Deploy == nil || svc.Deploy.PDB == nil) {
+		add(LintRulePDB, LintSeverityInfo, "set 'deploy.pdb' so node drains don't evict all its replicas at once")
+	}
+	uid, _, err := ParseUser(svc.User)
+	switch {
+	case err == nil && uid == nil:
+		add(LintRuleRootUser, LintSeverityInfo, "the service runs as the user of its image, which may be root: set 'user' to a non-root uid")
+	case err == nil && *uid == 0:
+		add(LintRuleRootUser, LintSeverityWarning, "the service runs as root: set 'user' to a non-root uid")
+	}
+	if !svc.IsJob() && !svc.IsCronJob() && (svc.Restart == RestartNo || svc.Restart == RestartOnFailure) {
+		add(LintRuleRestart, LintSeverityWarning, "the restart policy '%s' is not supported by kubernetes deployments: its containers will always be restarted", svc.Restart)
+	}
+	for _, e := range svc.Environment {
+		if s.isReservedEnvVar(e.Name) {
+			add(LintRuleReservedEnv, LintSeverityWarning, "the environment variable '%s' shadows a variable injected by kubernetes", e.Name)
+		}
+	}
+	if svc.Privileged {
+		add(LintRulePrivileged, LintSeverityWarning, "the service runs privileged: its containers have full access to the host of its pods")
+	}
+	if len(svc.Devices) > 0 && !svc.Privileged {
+		add(LintRuleDevices, LintSeverityWarning, "the service maps devices without 'privileged': kubernetes only allows privileged containers to access most devices")
+	}
+	if svc.MemSwappiness != nil {
+		add(LintRuleMemoryOptions, LintSeverityWarning, "'mem_swappiness' is not supported by kubernetes and is ignored")
+	}
+	if svc.OOMKillDisable {
+		if svc.HasGuaranteedResources() {
+			add(LintRuleMemoryOptions, LintSeverityInfo, "'oom_kill_disable' is not supported by kubernetes: the requests of the service are set to its limits to get the Guaranteed QoS class instead")
+		} else {
+			add(LintRuleMemoryOptions, LintSeverityWarning, "'oom_kill_disable' is not supported by kubernetes and is ignored: set 'resources.limits.cpu' and 'resources.limits.memory' to get the Guaranteed QoS class instead")
+		}
+	}
+	if svc.Reload && (svc.IsJob() || svc.IsCronJob()) {
+		add(LintRuleReload, LintSeverityWarning, "'reload' is ignored: only deployments, statefulsets and daemonsets are restarted when their configuration changes")
+	}
+	if svc.IsPublished() && len(svc.Expose) > 0 {
+		add(LintRulePublicExpose, LintSeverityInfo, "the ports in 'expose' are also reachable through the load balancer of the public service")
+	}
+	if svc.IsGlobal() && svc.Replicas > 1 {
+		add(LintRuleGlobalReplicas, LintSeverityWarning, "'replicas' is ignored: global services run one pod in every node")
+	}
+	if svc.Deploy != nil && svc.Deploy.PDB != nil && svc.Replicas <= 1 && svc.Deploy.Autoscaling == nil {
+		add(LintRulePDBReplicas, LintSeverityWarning, "the service has a single replica: its pdb may block the eviction of its pod when draining nodes")
+	}
+	return result
+}
+
+//GetMinReplicas returns the minimum number of replicas of the service, taking autoscaling into account
+func (svc *Service) GetMinReplicas() int32 {
+	if svc.Deploy != nil && svc.Deploy.Autoscaling != nil {
+		return svc.Deploy.Autoscaling.Min
+	}
+	return svc.Replicas
+}
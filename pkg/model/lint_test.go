@@ -0,0 +1,220 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+//newLintService returns a service without lint issues
+func newLintService() Service {
+	return Service{
+		Image:    "okteto/api:1.0",
+		User:     "1000",
+		Replicas: 1,
+		Resources: StackResources{
+			Limits: ServiceResources{
+				CPU:    Quantity{Value: resource.MustParse("500m")},
+				Memory: Quantity{Value: resource.MustParse("512Mi")},
+			},
+		},
+	}
+}
+
+func TestStack_Lint(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(svc *Service)
+		expected []string
+	}{
+		{
+			name:   "clean",
+			modify: func(svc *Service) {},
+		},
+		{
+			name:     "missing-limits",
+			modify:   func(svc *Service) { svc.Resources.Limits.Memory = Quantity{} },
+			expected: []string{LintRuleResourceLimits},
+		},
+		{
+			name:     "latest-image",
+			modify:   func(svc *Service) { svc.Image = "okteto/api:latest" },
+			expected: []string{LintRuleLatestImage},
+		},
+		{
+			name:     "untagged-image",
+			modify:   func(svc *Service) { svc.Image = "okteto/api" },
+			expected: []string{LintRuleLatestImage},
+		},
+		{
+			name: "untagged-built-image",
+			modify: func(svc *Service) {
+				svc.Image = "okteto/api"
+				svc.Build = &BuildInfo{Context: "."}
+			},
+		},
+		{
+			name: "public-without-healthcheck",
+			modify: func(svc *Service) {
+				svc.Public = true
+				svc.Replicas = 2
+				svc.Deploy = &DeployInfo{PDB: &PDBInfo{MinAvailable: "1"}}
+			},
+			expected: []string{LintRulePublicHealthCheck},
+		},
+		{
+			name: "public-single-replica",
+			modify: func(svc *Service) {
+				svc.Public = true
+				svc.Healthchecks = true
+			},
+			expected: []string{LintRulePublicReplicas},
+		},
+		{
+			name:     "missing-pdb",
+			modify:   func(svc *Service) { svc.Replicas = 3 },
+			expected: []string{LintRulePDB},
+		},
+		{
+			name:     "missing-pdb-autoscaling",
+			modify:   func(svc *Service) { svc.Deploy = &DeployInfo{Autoscaling: &AutoscalingInfo{Min: 2, Max: 5}} },
+			expected: []string{LintRulePDB},
+		},
+		{
+			name:     "default-user",
+			modify:   func(svc *Service) { svc.User = "" },
+			expected: []string{LintRuleRootUser},
+		},
+		{
+			name:     "root-user",
+			modify:   func(svc *Service) { svc.User = "0:0" },
+			expected: []string{LintRuleRootUser},
+		},
+		{
+			name:     "restart-deployment",
+			modify:   func(svc *Service) { svc.Restart = RestartOnFailure },
+			expected: []string{LintRuleRestart},
+		},
+		{
+			name:     "reserved-env",
+			modify:   func(svc *Service) { svc.Environment = Environment{{Name: "KUBERNETES_PORT", Value: "443"}} },
+			expected: []string{LintRuleReservedEnv},
+		},
+		{
+			name:     "privileged",
+			modify:   func(svc *Service) { svc.Privileged = true },
+			expected: []string{LintRulePrivileged},
+		},
+		{
+			name:     "devices",
+			modify:   func(svc *Service) { svc.Devices = []string{"/dev/fuse"} },
+			expected: []string{LintRuleDevices},
+		},
+		{
+			name:     "mem-swappiness",
+			modify:   func(svc *Service) { svc.MemSwappiness = pointer.Int64Ptr(0) },
+			expected: []string{LintRuleMemoryOptions},
+		},
+		{
+			name: "oom-kill-disable-without-limits",
+			modify: func(svc *Service) {
+				svc.OOMKillDisable = true
+				svc.Resources.Limits.CPU = Quantity{}
+			},
+			expected: []string{LintRuleMemoryOptions, LintRuleResourceLimits},
+		},
+		{
+			name: "reload-job",
+			modify: func(svc *Service) {
+				svc.Kind = JobServiceKind
+				svc.Reload = true
+			},
+			expected: []string{LintRuleReload},
+		},
+		{
+			name: "public-expose",
+			modify: func(svc *Service) {
+				svc.Public = true
+				svc.Healthchecks = true
+				svc.Replicas = 2
+				svc.Deploy = &DeployInfo{PDB: &PDBInfo{MinAvailable: "1"}}
+				svc.Ports = []Port{{Port: 80, ContainerPort: 80}}
+				svc.Expose = []Port{{Port: 8080, ContainerPort: 8080}}
+			},
+			expected: []string{LintRulePublicExpose},
+		},
+		{
+			name: "global-replicas",
+			modify: func(svc *Service) {
+				svc.Deploy = &DeployInfo{Mode: GlobalDeployMode}
+				svc.Replicas = 3
+			},
+			expected: []string{LintRuleGlobalReplicas},
+		},
+		{
+			name:     "pdb-single-replica",
+			modify:   func(svc *Service) { svc.Deploy = &DeployInfo{PDB: &PDBInfo{MinAvailable: "1"}} },
+			expected: []string{LintRulePDBReplicas},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newLintService()
+			tt.modify(&svc)
+			s := &Stack{Name: "name", Services: map[string]Service{"api": svc}}
+			rules := []string{}
+			for _, issue := range s.Lint() {
+				if issue.Service != "api" || issue.Message == "" {
+					t.Errorf("wrong issue: %+v", issue)
+				}
+				rules = append(rules, issue.Rule)
+			}
+			if len(tt.expected) == 0 {
+				tt.expected = []string{}
+			}
+			if !reflect.DeepEqual(rules, tt.expected) {
+				t.Errorf("wrong rules: %v, expected %v", rules, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStack_LintSeverity(t *testing.T) {
+	svc := newLintService()
+	svc.User = "0"
+	s := &Stack{Name: "name", Services: map[string]Service{"api": svc}}
+	issues := s.Lint()
+	if len(issues) != 1 || issues[0].Severity != LintSeverityWarning {
+		t.Errorf("wrong issues: %+v", issues)
+	}
+
+	svc.User = ""
+	s.Services["api"] = svc
+	issues = s.Lint()
+	if len(issues) != 1 || issues[0].Severity != LintSeverityInfo {
+		t.Errorf("wrong issues: %+v", issues)
+	}
+
+	svc.User = "1000"
+	svc.OOMKillDisable = true
+	s.Services["api"] = svc
+	issues = s.Lint()
+	if len(issues) != 1 || issues[0].Rule != LintRuleMemoryOptions || issues[0].Severity != LintSeverityInfo {
+		t.Errorf("wrong issues: %+v", issues)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+//StackOverrides represents the overrides applied to a stack manifest when it is deployed,
+//so a previous deployment can be reproduced from its manifest
+type StackOverrides struct {
+	Set      []string          `json:"set,omitempty"`
+	Commands []string          `json:"commands,omitempty"`
+	Images   map[string]string `json:"images,omitempty"`
+}
+
+//ApplyOverrides sets the stack fields defined by a list of 'path=value' overrides
+func (s *Stack) ApplyOverrides(overrides []string) error {
+	for _, override := range overrides {
+		if err := s.ApplyOverride(override); err != nil {
+			return err
+		}
+	}
+	if len(overrides) > 0 {
+		s.Normalize()
+		s.Overrides.Set = append(s.Overrides.Set, overrides...)
+	}
+	return nil
+}
+
+//ApplyOverride sets the stack field defined by an override of the form 'path=value'.
+//The path is a dotted list of yaml keys, map keys and list indexes (e.g. 'services.web.replicas').
+//Dots in map keys must be escaped with '\.'
+func (s *Stack) ApplyOverride(override string) error {
+	parts := strings.SplitN(override, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("Invalid override '%s': must be of the form 'path=value'", override)
+	}
+	path := splitOverridePath(parts[0])
+	for _, segment := range path {
+		if segment == "" {
+			return fmt.Errorf("Invalid override '%s': path '%s' contains an empty key", override, parts[0])
+		}
+	}
+	if err := setOverrideValue(reflect.ValueOf(s).Elem(), path, parts[1]); err != nil {
+		return fmt.Errorf("Invalid override '%s': %s", override, err)
+	}
+	return nil
+}
+
+func splitOverridePath(path string) []string {
+	result := []string{}
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			sb.WriteByte('.')
+			i++
+		case path[i] == '.':
+			result = append(result, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(path[i])
+		}
+	}
+	return append(result, sb.String())
+}
+
+func setOverrideValue(v reflect.Value, path []string, value string) error {
+	if len(path) == 0 {
+		return unmarshalOverrideValue(v, value)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setOverrideValue(v.Elem(), path, value)
+	case reflect.Struct:
+		field, ok := getFieldByYAMLKey(v, path[0])
+		if !ok {
+			return fmt.Errorf("unknown field '%s'", path[0])
+		}
+		return setOverrideValue(field, path[1:], value)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("key '%s' is not supported", path[0])
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if current := v.MapIndex(key); current.IsValid() {
+			elem.Set(current)
+		}
+		if err := setOverrideValue(elem, path[1:], value); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	case reflect.Slice:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 {
+			return fmt.Errorf("'%s' is not a valid list index", path[0])
+		}
+		if index > v.Len() {
+			return fmt.Errorf("list index %d is out of range, the list has %d elements", index, v.Len())
+		}
+		if index == v.Len() {
+			v.Set(reflect.Append(v, reflect.New(v.Type().Elem()).Elem()))
+		}
+		return setOverrideValue(v.Index(index), path[1:], value)
+	default:
+		return fmt.Errorf("'%s' cannot be set on a %s value", path[0], v.Kind())
+	}
+}
+
+func getFieldByYAMLKey(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if name == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func unmarshalOverrideValue(v reflect.Value, value string) error {
+	ptr := reflect.New(v.Type())
+	if err := yaml.UnmarshalStrict([]byte(value), ptr.Interface()); err != nil {
+		msg := strings.TrimPrefix(err.Error(), "yaml: unmarshal errors:\n")
+		return fmt.Errorf("invalid value '%s': %s", value, strings.TrimSpace(msg))
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
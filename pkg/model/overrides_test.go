@@ -0,0 +1,159 @@
+// Copyright 2020 The Okteto Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_ApplyOverrides(t *testing.T) {
+	manifest := []byte(`name: voting-app
+services:
+  web:
+    image: okteto/web
+    replicas: 2
+    ports:
+      - 8080
+    environment:
+      - FOO=bar
+    volumes:
+      - /data
+    resources:
+      limits:
+        cpu: 500m`)
+
+	tests := []struct {
+		name      string
+		overrides []string
+		check     func(*testing.T, *Stack)
+		wantErr   bool
+	}{
+		{
+			name:      "scalar",
+			overrides: []string{"services.web.replicas=3", "services.web.image=okteto/web:v2"},
+			check: func(t *testing.T, s *Stack) {
+				if s.Services["web"].Replicas != 3 {
+					t.Errorf("wrong replicas: %d", s.Services["web"].Replicas)
+				}
+				if s.Services["web"].Image != "okteto/web:v2" {
+					t.Errorf("wrong image: %s", s.Services["web"].Image)
+				}
+			},
+		},
+		{
+			name:      "nested",
+			overrides: []string{"services.web.resources.limits.cpu=1", "services.web.deploy.labels.app\\.kubernetes\\.io/name=web"},
+			check: func(t *testing.T, s *Stack) {
+				cpu := s.Services["web"].Resources.Limits.CPU.Value
+				if cpu.Cmp(resource.MustParse("1")) != 0 {
+					t.Errorf("wrong cpu limit: %s", cpu.String())
+				}
+				expected := map[string]string{"app.kubernetes.io/name": "web"}
+				if !reflect.DeepEqual(s.Services["web"].Deploy.Labels, expected) {
+					t.Errorf("wrong deploy labels: %+v", s.Services["web"].Deploy.Labels)
+				}
+			},
+		},
+		{
+			name:      "list",
+			overrides: []string{"services.web.environment.0=FOO=baz", "services.web.volumes.1=/cache", "services.web.ports.0=9090"},
+			check: func(t *testing.T, s *Stack) {
+				env := s.Services["web"].Environment
+				if len(env) != 1 || env[0].Name != "FOO" || env[0].Value != "baz" {
+					t.Errorf("wrong environment: %+v", env)
+				}
+				if !reflect.DeepEqual(s.Services["web"].Volumes, []string{"/data", "/cache"}) {
+					t.Errorf("wrong volumes: %+v", s.Services["web"].Volumes)
+				}
+				if s.Services["web"].Ports[0].Port != 9090 {
+					t.Errorf("wrong port: %+v", s.Services["web"].Ports)
+				}
+			},
+		},
+		{
+			name:      "new-service",
+			overrides: []string{"services.db.image=postgres"},
+			check: func(t *testing.T, s *Stack) {
+				if s.Services["db"].Image != "postgres" {
+					t.Errorf("wrong image: %s", s.Services["db"].Image)
+				}
+				if s.Services["db"].Replicas != 1 {
+					t.Errorf("overrides are not normalized: %d", s.Services["db"].Replicas)
+				}
+			},
+		},
+		{
+			name:      "missing-value",
+			overrides: []string{"services.web.replicas"},
+			wantErr:   true,
+		},
+		{
+			name:      "empty-key",
+			overrides: []string{"services..replicas=3"},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown-field",
+			overrides: []string{"services.web.foo=3"},
+			wantErr:   true,
+		},
+		{
+			name:      "wrong-type",
+			overrides: []string{"services.web.replicas=three"},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid-index",
+			overrides: []string{"services.web.volumes.a=/cache"},
+			wantErr:   true,
+		},
+		{
+			name:      "index-out-of-range",
+			overrides: []string{"services.web.volumes.5=/cache"},
+			wantErr:   true,
+		},
+		{
+			name:      "path-into-scalar",
+			overrides: []string{"services.web.image.name=nginx"},
+			wantErr:   true,
+		},
+		{
+			name:      "ignored-field",
+			overrides: []string{"manifest=foo"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ReadStack(manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = s.ApplyOverrides(tt.overrides)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, s)
+		})
+	}
+}
@@ -32,6 +32,7 @@ type buildInfoRaw struct {
 	CacheFrom  []string `yaml:"cache_from,omitempty"`
 	Target     string   `yaml:"target,omitempty"`
 	Args       []EnvVar `yaml:"args,omitempty"`
+	Network    string   `yaml:"network,omitempty"`
 }
 
 type syncRaw struct {
@@ -43,8 +44,28 @@ type syncRaw struct {
 }
 
 type storageResourceRaw struct {
-	Size  Quantity `json:"size,omitempty" yaml:"size,omitempty"`
-	Class string   `json:"class,omitempty" yaml:"class,omitempty"`
+	Size       Quantity                         `json:"size,omitempty" yaml:"size,omitempty"`
+	Class      string                           `json:"class,omitempty" yaml:"class,omitempty"`
+	AccessMode apiv1.PersistentVolumeAccessMode `json:"access_mode,omitempty" yaml:"access_mode,omitempty"`
+}
+
+// oktetoOptionsRaw represents the x-okteto block of a stack for serialization
+type oktetoOptionsRaw struct {
+	SkipRegistryCheck bool                   `yaml:"skipRegistryCheck,omitempty"`
+	AutoIngressClass  string                 `yaml:"autoIngressClass,omitempty"`
+	RequireImageTags  bool                   `yaml:"requireImageTags,omitempty"`
+	Domain            string                 `yaml:"domain,omitempty"`
+	TLSSecret         string                 `yaml:"tlsSecret,omitempty"`
+	TLSIssuer         string                 `yaml:"tlsIssuer,omitempty"`
+	Unknown           map[string]interface{} `yaml:",inline"`
+}
+
+// portRaw represents the long syntax of a port for serialization, needed to set its node port
+type portRaw struct {
+	Published int32  `yaml:"published,omitempty"`
+	Target    int32  `yaml:"target,omitempty"`
+	Protocol  string `yaml:"protocol,omitempty"`
+	NodePort  int32  `yaml:"node_port,omitempty"`
 }
 
 // healthCheckProbesRaw represents the healthchecks info for serialization
@@ -54,25 +75,44 @@ type healthCheckProbesRaw struct {
 	Startup   bool `json:"startup,omitempty" yaml:"startup,omitempty"`
 }
 
+// envVarRaw represents the long syntax of an environment variable for serialization, needed to set its value from a secret or configmap
+type envVarRaw struct {
+	Name      string        `yaml:"name,omitempty"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty"`
+}
+
 // UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
 func (e *EnvVar) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return e.unmarshal(unmarshal, ExpandEnv)
+}
+
+//unmarshal reads an environment variable, expanding its value with the given function
+func (e *EnvVar) unmarshal(unmarshal func(interface{}) error, expand func(string) (string, error)) error {
 	var raw string
 	err := unmarshal(&raw)
 	if err != nil {
+		var long envVarRaw
+		if err := unmarshal(&long); err != nil {
+			return err
+		}
+		e.Name = long.Name
+		e.ValueFrom = long.ValueFrom
+		e.Value, err = expand(long.Value)
 		return err
 	}
 
 	parts := strings.SplitN(raw, "=", 2)
 	e.Name = parts[0]
 	if len(parts) == 2 {
-		e.Value, err = ExpandEnv(parts[1])
+		e.Value, err = expand(parts[1])
 		if err != nil {
 			return err
 		}
 		return nil
 	}
 
-	e.Name, err = ExpandEnv(parts[0])
+	e.Name, err = expand(parts[0])
 	if err != nil {
 		return err
 	}
@@ -82,9 +122,34 @@ func (e *EnvVar) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // MarshalYAML Implements the marshaler interface of the yaml pkg.
 func (e EnvVar) MarshalYAML() (interface{}, error) {
+	if e.ValueFrom != nil {
+		return envVarRaw{Name: e.Name, Value: e.Value, ValueFrom: e.ValueFrom}, nil
+	}
 	return e.Name + "=" + e.Value, nil
 }
 
+//stackEnvVar is an environment variable of a stack service
+type stackEnvVar EnvVar
+
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (e *stackEnvVar) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return (*EnvVar)(e).unmarshal(unmarshal, expandStackEnvValue)
+}
+
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (env *Environment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw []stackEnvVar
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	result := make(Environment, 0, len(raw))
+	for _, e := range raw {
+		result = append(result, EnvVar(e))
+	}
+	*env = result
+	return nil
+}
+
 // UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
 func (e *Entrypoint) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var multi []string
@@ -143,6 +208,22 @@ func (c Command) MarshalYAML() (interface{}, error) {
 	return c.Values, nil
 }
 
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (t *HealthCheckTest) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var multi []string
+	err := unmarshal(&multi)
+	if err != nil {
+		var single string
+		if err := unmarshal(&single); err != nil {
+			return err
+		}
+		*t = HealthCheckTest{HealthCheckTestCmdShell, single}
+		return nil
+	}
+	*t = multi
+	return nil
+}
+
 // UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
 func (a *Args) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var multi []string
@@ -213,8 +294,10 @@ func (buildInfo *BuildInfo) UnmarshalYAML(unmarshal func(interface{}) error) err
 	buildInfo.Name = rawBuildInfo.Name
 	buildInfo.Context = rawBuildInfo.Context
 	buildInfo.Dockerfile = rawBuildInfo.Dockerfile
+	buildInfo.CacheFrom = rawBuildInfo.CacheFrom
 	buildInfo.Target = rawBuildInfo.Target
 	buildInfo.Args = rawBuildInfo.Args
+	buildInfo.Network = rawBuildInfo.Network
 	return nil
 }
 
@@ -226,12 +309,18 @@ func (buildInfo BuildInfo) MarshalYAML() (interface{}, error) {
 	if buildInfo.Dockerfile != "" && buildInfo.Dockerfile != "./Dockerfile" {
 		return buildInfoRaw(buildInfo), nil
 	}
+	if len(buildInfo.CacheFrom) != 0 {
+		return buildInfoRaw(buildInfo), nil
+	}
 	if buildInfo.Target != "" {
 		return buildInfoRaw(buildInfo), nil
 	}
 	if buildInfo.Args != nil && len(buildInfo.Args) != 0 {
 		return buildInfoRaw(buildInfo), nil
 	}
+	if buildInfo.Network != "" {
+		return buildInfoRaw(buildInfo), nil
+	}
 	return buildInfo.Name, nil
 }
 
@@ -252,6 +341,108 @@ func (s *StorageResource) UnmarshalYAML(unmarshal func(interface{}) error) error
 
 	s.Size = rawStorageResource.Size
 	s.Class = rawStorageResource.Class
+	s.AccessMode = rawStorageResource.AccessMode
+	return nil
+}
+
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (o *OktetoOptions) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw oktetoOptionsRaw
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	for k := range raw.Unknown {
+		log.Infof("ignoring unknown 'x-okteto' option '%s'", k)
+	}
+	o.SkipRegistryCheck = raw.SkipRegistryCheck
+	o.AutoIngressClass = raw.AutoIngressClass
+	o.RequireImageTags = raw.RequireImageTags
+	o.Domain = raw.Domain
+	o.TLSSecret = raw.TLSSecret
+	o.TLSIssuer = raw.TLSIssuer
+	return nil
+}
+
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (sa *SessionAffinity) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var rawType string
+	if err := unmarshal(&rawType); err == nil {
+		sa.Type = apiv1.ServiceAffinity(rawType)
+		return nil
+	}
+	type sessionAffinity SessionAffinity // prevent recursion
+	var raw sessionAffinity
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*sa = SessionAffinity(raw)
+	return nil
+}
+
+// MarshalYAML Implements the marshaler interface of the yaml pkg.
+func (sa SessionAffinity) MarshalYAML() (interface{}, error) {
+	if sa.Timeout == nil {
+		return string(sa.Type), nil
+	}
+	type sessionAffinity SessionAffinity // prevent recursion
+	return sessionAffinity(sa), nil
+}
+
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (b *StackBool) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	switch v := raw.(type) {
+	case bool:
+		*b = StackBool(v)
+		return nil
+	case int:
+		switch v {
+		case 0:
+			*b = false
+			return nil
+		case 1:
+			*b = true
+			return nil
+		}
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "yes", "on", "1":
+			*b = true
+			return nil
+		case "false", "no", "off", "0":
+			*b = false
+			return nil
+		}
+	}
+	return fmt.Errorf("'%v' is not a valid boolean: supported values are 'true', 'false', 'yes', 'no', 'on', 'off', '1' and '0'", raw)
+}
+
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (d *DependsOn) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	result := DependsOn{}
+	var rawList []string
+	if err := unmarshal(&rawList); err == nil {
+		for _, name := range rawList {
+			result[name] = DependsOnConditionSpec{Condition: DependsOnServiceStarted}
+		}
+		*d = result
+		return nil
+	}
+
+	var rawMap map[string]DependsOnConditionSpec
+	if err := unmarshal(&rawMap); err != nil {
+		return err
+	}
+	for name, spec := range rawMap {
+		if spec.Condition == "" {
+			spec.Condition = DependsOnServiceStarted
+		}
+		result[name] = spec
+	}
+	*d = result
 	return nil
 }
 
@@ -546,6 +737,106 @@ func isDefaultProbes(d *Dev) bool {
 	return true
 }
 
+// UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
+func (p *Port) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var rawPort int32
+	err := unmarshal(&rawPort)
+	if err == nil {
+		if err := validatePortNumber(rawPort); err != nil {
+			return err
+		}
+		p.Port = rawPort
+		p.ContainerPort = rawPort
+		return nil
+	}
+
+	var raw string
+	err = unmarshal(&raw)
+	if err != nil {
+		var long portRaw
+		if err := unmarshal(&long); err != nil {
+			return err
+		}
+		return p.fromRaw(long)
+	}
+
+	ports := raw
+	if i := strings.LastIndex(raw, "/"); i >= 0 {
+		if i == len(raw)-1 {
+			return fmt.Errorf("Invalid port '%s': the protocol is empty", raw)
+		}
+		ports = raw[:i]
+		p.Protocol = apiv1.Protocol(strings.ToUpper(raw[i+1:]))
+	}
+	parts := strings.Split(ports, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("Invalid port '%s': ports must follow the syntax 'PORT[/PROTOCOL]' or 'PUBLISHED_PORT:CONTAINER_PORT[/PROTOCOL]'", raw)
+	}
+	numbers := make([]int32, len(parts))
+	for i, part := range parts {
+		if strings.Contains(part, "-") {
+			return fmt.Errorf("Invalid port '%s': port ranges are not supported", raw)
+		}
+		port, err := strconv.ParseInt(part, 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid port '%s': '%s' is not a valid port number", raw, part)
+		}
+		if err := validatePortNumber(int32(port)); err != nil {
+			return err
+		}
+		numbers[i] = int32(port)
+	}
+
+	p.Port = numbers[0]
+	p.ContainerPort = numbers[len(numbers)-1]
+	return nil
+}
+
+//fromRaw sets a port from its long syntax, where the published port defaults to the target port
+func (p *Port) fromRaw(raw portRaw) error {
+	if raw.Target == 0 {
+		return fmt.Errorf("Invalid port: 'target' is required")
+	}
+	if raw.Published == 0 {
+		raw.Published = raw.Target
+	}
+	for _, port := range []int32{raw.Published, raw.Target} {
+		if err := validatePortNumber(port); err != nil {
+			return err
+		}
+	}
+	p.Port = raw.Published
+	p.ContainerPort = raw.Target
+	p.Protocol = apiv1.Protocol(strings.ToUpper(raw.Protocol))
+	p.NodePort = raw.NodePort
+	return nil
+}
+
+// MarshalYAML Implements the marshaler interface of the yaml pkg.
+func (p Port) MarshalYAML() (interface{}, error) {
+	if p.NodePort != 0 {
+		return portRaw{Published: p.Port, Target: p.ContainerPort, Protocol: strings.ToLower(string(p.Protocol)), NodePort: p.NodePort}, nil
+	}
+	result := fmt.Sprintf("%d:%d", p.Port, p.ContainerPort)
+	if p.Port == p.ContainerPort {
+		if p.Protocol == "" {
+			return p.Port, nil
+		}
+		result = strconv.Itoa(int(p.Port))
+	}
+	if p.Protocol != "" {
+		result = fmt.Sprintf("%s/%s", result, strings.ToLower(string(p.Protocol)))
+	}
+	return result, nil
+}
+
+func validatePortNumber(port int32) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("Invalid port '%d': must be a number between 1 and 65535", port)
+	}
+	return nil
+}
+
 // UnmarshalYAML Implements the Unmarshaler interface of the yaml pkg.
 func (s *StackResources) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type stackResources StackResources // prevent recursion
@@ -564,6 +855,7 @@ func (s *StackResources) UnmarshalYAML(unmarshal func(interface{}) error) error
 	}
 	s.Limits.CPU = resources.CPU
 	s.Limits.Memory = resources.Memory
+	s.Limits.EphemeralStorage = resources.EphemeralStorage
 	s.Requests.Storage = resources.Storage
 	return nil
 }
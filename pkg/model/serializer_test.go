@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	yaml "gopkg.in/yaml.v2"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -85,6 +86,119 @@ func TestReverseMashalling(t *testing.T) {
 	}
 }
 
+func TestPortMashalling(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expected  Port
+		expectErr bool
+	}{
+		{
+			name:     "single",
+			data:     "80",
+			expected: Port{Port: 80, ContainerPort: 80},
+		},
+		{
+			name:     "single-string",
+			data:     "\"80\"",
+			expected: Port{Port: 80, ContainerPort: 80},
+		},
+		{
+			name:     "published-and-container",
+			data:     "8080:80",
+			expected: Port{Port: 8080, ContainerPort: 80},
+		},
+		{
+			name:     "udp",
+			data:     "53/udp",
+			expected: Port{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP},
+		},
+		{
+			name:     "published-and-container-tcp",
+			data:     "8080:80/tcp",
+			expected: Port{Port: 8080, ContainerPort: 80, Protocol: apiv1.ProtocolTCP},
+		},
+		{
+			name:     "unknown-protocol",
+			data:     "8080:80/http",
+			expected: Port{Port: 8080, ContainerPort: 80, Protocol: apiv1.Protocol("HTTP")},
+		},
+		{
+			name:      "empty-protocol",
+			data:      "8080:80/",
+			expectErr: true,
+		},
+		{
+			name:      "range",
+			data:      "8080-8081:80-81",
+			expectErr: true,
+		},
+		{
+			name:      "out-of-range",
+			data:      "70000:80",
+			expectErr: true,
+		},
+		{
+			name:      "zero",
+			data:      "0",
+			expectErr: true,
+		},
+		{
+			name:      "non-integer",
+			data:      "8080:http",
+			expectErr: true,
+		},
+		{
+			name:      "too-many-parts",
+			data:      "127.0.0.1:8080:80",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result Port
+			if err := yaml.Unmarshal([]byte(tt.data), &result); err != nil {
+				if tt.expectErr {
+					return
+				}
+				t.Fatal(err)
+			}
+			if tt.expectErr {
+				t.Fatalf("expected error unmarshalling '%s'", tt.data)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("didn't unmarshal correctly. Actual '%+v', Expected '%+v'", result, tt.expected)
+			}
+
+			out, err := yaml.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var roundTrip Port
+			if err := yaml.Unmarshal(out, &roundTrip); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(roundTrip, tt.expected) {
+				t.Errorf("didn't marshal correctly. Actual '%+v', Expected '%+v'", roundTrip, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPortRangeError(t *testing.T) {
+	var result Port
+	err := yaml.Unmarshal([]byte("8080-8081:80-81"), &result)
+	if err == nil {
+		t.Fatal("expected error unmarshalling a port range")
+	}
+	if !strings.Contains(err.Error(), "port ranges are not supported") {
+		t.Errorf("wrong error for a port range: %s", err)
+	}
+}
+
 func TestEnvVarMashalling(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -171,6 +285,65 @@ func TestEnvVarMashalling(t *testing.T) {
 	}
 }
 
+func TestEnvironmentUnmarshalling(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected Environment
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			data:     []byte("- env=${OKTETO_TEST_UNDEFINED:-production}"),
+			expected: Environment{{Name: "env", Value: "production"}},
+		},
+		{
+			name:     "escaped",
+			data:     []byte("- price=$$5"),
+			expected: Environment{{Name: "price", Value: "$5"}},
+		},
+		{
+			name:     "required",
+			data:     []byte("- env=${OKTETO_TEST_ENV_MARSHALLING:?env is required}"),
+			expected: Environment{{Name: "env", Value: "true"}},
+		},
+		{
+			name:     "image-digest",
+			data:     []byte("- image=okteto/app@${OKTETO_IMAGE_DIGEST}"),
+			expected: Environment{{Name: "image", Value: "okteto/app@${OKTETO_IMAGE_DIGEST}"}},
+		},
+		{
+			name:    "required-undefined",
+			data:    []byte("- env=${OKTETO_TEST_UNDEFINED:?env is required}"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.Setenv("OKTETO_TEST_ENV_MARSHALLING", "true"); err != nil {
+				t.Fatal(err)
+			}
+
+			var result Environment
+			err := yaml.Unmarshal(tt.data, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error unmarshalling '%s'", tt.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("didn't unmarshal correctly. Actual %+v, Expected %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestCommandUnmashalling(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -262,6 +435,11 @@ func TestImageMashalling(t *testing.T) {
 			image:    BuildInfo{Name: "image-name", Context: "path"},
 			expected: "name: image-name\ncontext: path\n",
 		},
+		{
+			name:     "cache-from",
+			image:    BuildInfo{Name: "image-name", CacheFrom: []string{"image-name:cache"}},
+			expected: "name: image-name\ncache_from:\n- image-name:cache\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -278,6 +456,49 @@ func TestImageMashalling(t *testing.T) {
 	}
 }
 
+func TestBuildInfoUnmarshalling(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected BuildInfo
+	}{
+		{
+			name:     "single-name",
+			data:     []byte("image-name"),
+			expected: BuildInfo{Name: "image-name"},
+		},
+		{
+			name:     "cache-from",
+			data:     []byte("context: api\ncache_from:\n- image-name:cache\n- image-name:latest\n"),
+			expected: BuildInfo{Context: "api", CacheFrom: []string{"image-name:cache", "image-name:latest"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result BuildInfo
+			if err := yaml.UnmarshalStrict(tt.data, &result); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("didn't unmarshal correctly. Actual %+v, Expected %+v", result, tt.expected)
+			}
+
+			marshalled, err := yaml.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var again BuildInfo
+			if err := yaml.UnmarshalStrict(marshalled, &again); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again, tt.expected) {
+				t.Errorf("didn't marshal correctly. Actual %+v, Expected %+v", again, tt.expected)
+			}
+		})
+	}
+}
+
 func TestHealthcheckMashalling(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -514,6 +735,22 @@ func TestStackResourcesUnmarshalling(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "ephemeral-storage",
+			data: []byte("limits:\n  ephemeral_storage: 2Gi\nrequests:\n  ephemeral_storage: 1Gi\n"),
+			expected: StackResources{
+				Limits: ServiceResources{
+					EphemeralStorage: Quantity{
+						Value: resource.MustParse("2Gi"),
+					},
+				},
+				Requests: ServiceResources{
+					EphemeralStorage: Quantity{
+						Value: resource.MustParse("1Gi"),
+					},
+				},
+			},
+		},
 		{
 			name: "simple-resources",
 			data: []byte("cpu: 100m\nmemory: 100Gi\n"),
@@ -567,3 +804,98 @@ func TestStackResourcesUnmarshalling(t *testing.T) {
 		})
 	}
 }
+
+func TestStackBoolUnmarshalling(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected StackBool
+		wantErr  bool
+	}{
+		{name: "true", data: []byte("public: true"), expected: true},
+		{name: "false", data: []byte("public: false"), expected: false},
+		{name: "yes", data: []byte("public: yes"), expected: true},
+		{name: "quoted-yes", data: []byte("public: \"yes\""), expected: true},
+		{name: "quoted-no", data: []byte("public: \"no\""), expected: false},
+		{name: "on", data: []byte("public: \"On\""), expected: true},
+		{name: "off", data: []byte("public: \"off\""), expected: false},
+		{name: "one", data: []byte("public: 1"), expected: true},
+		{name: "zero", data: []byte("public: 0"), expected: false},
+		{name: "quoted-one", data: []byte("public: \"1\""), expected: true},
+		{name: "quoted-zero", data: []byte("public: \"0\""), expected: false},
+		{name: "invalid-string", data: []byte("public: maybe"), wantErr: true},
+		{name: "invalid-number", data: []byte("public: 2"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result struct {
+				Public StackBool `yaml:"public"`
+			}
+			err := yaml.UnmarshalStrict(tt.data, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Public != tt.expected {
+				t.Errorf("didn't unmarshal correctly. Actual %t, Expected %t", result.Public, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDependsOnUnmarshalling(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected DependsOn
+		wantErr  bool
+	}{
+		{
+			name:     "list",
+			data:     []byte("depends_on:\n  - db\n  - queue\n"),
+			expected: DependsOn{"db": {Condition: DependsOnServiceStarted}, "queue": {Condition: DependsOnServiceStarted}},
+		},
+		{
+			name:     "map",
+			data:     []byte("depends_on:\n  db:\n    condition: service_healthy\n  queue:\n    condition: service_started\n"),
+			expected: DependsOn{"db": {Condition: DependsOnServiceHealthy}, "queue": {Condition: DependsOnServiceStarted}},
+		},
+		{
+			name:     "map-without-condition",
+			data:     []byte("depends_on:\n  db: {}\n"),
+			expected: DependsOn{"db": {Condition: DependsOnServiceStarted}},
+		},
+		{
+			name:    "unknown-field",
+			data:    []byte("depends_on:\n  db:\n    restart: true\n"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result struct {
+				DependsOn DependsOn `yaml:"depends_on"`
+			}
+			err := yaml.UnmarshalStrict(tt.data, &result)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.DependsOn, tt.expected) {
+				t.Errorf("didn't unmarshal correctly. Actual %+v, Expected %+v", result.DependsOn, tt.expected)
+			}
+		})
+	}
+}
@@ -16,51 +16,375 @@ package model
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime"
+	"net/http"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/okteto/okteto/pkg/k8s/labels"
+	"github.com/subosito/gotenv"
 	yaml "gopkg.in/yaml.v2"
 	apiv1 "k8s.io/api/core/v1"
 	resource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	//VIPEndpointMode represents a service load balanced by a virtual IP
+	VIPEndpointMode = "vip"
+
+	//DNSRREndpointMode represents a headless service, load balanced by its clients using DNS round-robin
+	DNSRREndpointMode = "dnsrr"
+
+	//ReplicatedDeployMode runs the replicas of a service in any node
+	ReplicatedDeployMode = "replicated"
+
+	//GlobalDeployMode runs one pod of a service in every node, as a kubernetes daemonset
+	GlobalDeployMode = "global"
+
+	//PodsMetricType represents a metric describing each pod of a service
+	PodsMetricType = "pods"
+
+	//ExternalMetricType represents a metric not associated with any kubernetes object
+	ExternalMetricType = "external"
+
+	//PodsPolicyType limits the number of replicas changed in a period
+	PodsPolicyType = "pods"
+
+	//PercentPolicyType limits the percentage of replicas changed in a period
+	PercentPolicyType = "percent"
+
+	//JobServiceKind runs a service to completion as a kubernetes job
+	JobServiceKind = "job"
+
+	//RestartNo never restarts the containers of a service
+	RestartNo = "no"
+
+	//RestartAlways always restarts the containers of a service
+	RestartAlways = "always"
+
+	//RestartOnFailure restarts the containers of a service when they exit with an error
+	RestartOnFailure = "on-failure"
+
+	//RestartUnlessStopped always restarts the containers of a service, kubernetes has no notion of stopped containers
+	RestartUnlessStopped = "unless-stopped"
+
+	//DependsOnServiceStarted waits for the dependency to be created before deploying a service
+	DependsOnServiceStarted = "service_started"
+
+	//DependsOnServiceHealthy waits for the dependency to be ready before deploying a service
+	DependsOnServiceHealthy = "service_healthy"
+
+	//InternalLoadBalancer exposes a public service only inside the network of the cluster
+	InternalLoadBalancer = "internal"
+
+	//ExternalLoadBalancer exposes a public service to the internet
+	ExternalLoadBalancer = "external"
+
+	//minSessionAffinityTimeout is the minimum session sticky time allowed by kubernetes
+	minSessionAffinityTimeout = 1
+
+	//maxSessionAffinityTimeout is the maximum session sticky time allowed by kubernetes
+	maxSessionAffinityTimeout = 86400
+
+	//minNodePort is the first port of the default node port range of kubernetes
+	minNodePort = 30000
+
+	//maxNodePort is the last port of the default node port range of kubernetes
+	maxNodePort = 32767
+
+	//ProviderGKE is the cloud provider of Google Kubernetes Engine clusters
+	ProviderGKE = "gke"
+
+	//ProviderEKS is the cloud provider of Amazon Elastic Kubernetes Service clusters
+	ProviderEKS = "eks"
+
+	//ProviderAKS is the cloud provider of Azure Kubernetes Service clusters
+	ProviderAKS = "aks"
+
+	//HealthCheckTestNone disables the health check of a service
+	HealthCheckTestNone = "NONE"
+
+	//HealthCheckTestCmd runs the arguments of a health check
+	HealthCheckTestCmd = "CMD"
+
+	//HealthCheckTestCmdShell runs the command of a health check with the shell
+	HealthCheckTestCmdShell = "CMD-SHELL"
+
+	//PrefixPathType matches the endpoint paths by prefix, split by '/'
+	PrefixPathType = "Prefix"
+
+	//ExactPathType matches the endpoint paths exactly
+	ExactPathType = "Exact"
+
+	//ImplementationSpecificPathType matches the endpoint paths as defined by the ingress controller
+	ImplementationSpecificPathType = "ImplementationSpecific"
+
+	//SharedGPUProfile requests time-sliced gpus, shared with other pods
+	SharedGPUProfile = "shared"
+
+	//GPUResourceName is the extended resource of full gpus
+	GPUResourceName apiv1.ResourceName = "nvidia.com/gpu"
+
+	//SharedGPUResourceName is the extended resource of time-sliced gpus
+	SharedGPUResourceName apiv1.ResourceName = "nvidia.com/gpu.shared"
+
+	//MIGResourcePrefix is the prefix of the extended resources of MIG partitions, followed by the profile
+	MIGResourcePrefix = "nvidia.com/mig-"
+
+	//GPUSharingStrategyLabel is the node label with the gpu sharing strategy of a node
+	GPUSharingStrategyLabel = "nvidia.com/gpu.sharing-strategy"
+
+	//GPUTimeSlicingStrategy is the sharing strategy of nodes providing time-sliced gpus
+	GPUTimeSlicingStrategy = "time-slicing"
+
+	//MIGStrategyLabel is the node label with the MIG strategy of a node
+	MIGStrategyLabel = "nvidia.com/mig.strategy"
+
+	//MIGMixedStrategy is the MIG strategy of nodes exposing each MIG profile as its own resource
+	MIGMixedStrategy = "mixed"
 )
 
 var (
 	errBadStackName = "must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character"
+
+	migProfileRegex = regexp.MustCompile(`^[1-7]g\.[0-9]+gb(\+me)?$`)
+
+	stackHTTPTimeout = 30 * time.Second
+)
+
+const (
+	maxStackManifestSize = 1024 * 1024
+
+	readOnlyVolumeMode  = "ro"
+	readWriteVolumeMode = "rw"
 )
 
 //Stack represents an okteto stack
 type Stack struct {
-	Name      string                `yaml:"name"`
-	Namespace string                `yaml:"namespace,omitempty"`
-	Services  map[string]Service    `yaml:"services,omitempty"`
-	Endpoints map[string][]Endpoint `yaml:"endpoints,omitempty"`
-	Manifest  []byte                `yaml:"-"`
+	Name        string                `yaml:"name"`
+	Namespace   string                `yaml:"namespace,omitempty"`
+	Labels      map[string]string     `yaml:"labels,omitempty"`
+	Annotations map[string]string     `yaml:"annotations,omitempty"`
+	Services    map[string]Service    `yaml:"services,omitempty"`
+	Endpoints   map[string][]Endpoint `yaml:"endpoints,omitempty"`
+	Volumes     map[string]VolumeSpec `yaml:"volumes,omitempty"`
+	Okteto      OktetoOptions         `yaml:"x-okteto,omitempty"`
+	Manifest    []byte                `yaml:"-"`
+	Dir         string                `yaml:"-"`
+	Overrides   StackOverrides        `yaml:"-"`
+	Provider    string                `yaml:"-"`
+	IngressV1   bool                  `yaml:"-"`
+}
+
+//OktetoOptions represents the okteto specific toggles of an okteto stack
+type OktetoOptions struct {
+	SkipRegistryCheck bool   `yaml:"skipRegistryCheck,omitempty"`
+	AutoIngressClass  string `yaml:"autoIngressClass,omitempty"`
+	RequireImageTags  bool   `yaml:"requireImageTags,omitempty"`
+	Domain            string `yaml:"domain,omitempty"`
+	TLSSecret         string `yaml:"tlsSecret,omitempty"`
+	TLSIssuer         string `yaml:"tlsIssuer,omitempty"`
 }
 
 //Service represents an okteto stack service
 type Service struct {
-	Labels          map[string]string  `json:"labels,omitempty" yaml:"labels,omitempty"`
-	Annotations     map[string]string  `json:"annotations,omitempty" yaml:"annotations,omitempty"`
-	Public          bool               `yaml:"public,omitempty"`
-	Image           string             `yaml:"image"`
-	Build           *BuildInfo         `yaml:"build,omitempty"`
-	Replicas        int32              `yaml:"replicas"`
-	Entrypoint      Entrypoint         `yaml:"entrypoint,omitempty"`
-	Command         Command            `yaml:"command,omitempty"`
-	Args            Args               `yaml:"args,omitempty"`
-	Environment     []EnvVar           `yaml:"environment,omitempty"`
-	EnvFiles        []string           `yaml:"env_file,omitempty"`
-	CapAdd          []apiv1.Capability `yaml:"cap_add,omitempty"`
-	CapDrop         []apiv1.Capability `yaml:"cap_drop,omitempty"`
-	Healthchecks    bool               `yaml:"healthchecks,omitempty"`
-	Ports           []int32            `yaml:"ports,omitempty"`
-	Expose          []int32            `yaml:"expose,omitempty"`
-	Volumes         []string           `yaml:"volumes,omitempty"`
-	StopGracePeriod int64              `yaml:"stop_grace_period,omitempty"`
-	Resources       StackResources     `yaml:"resources,omitempty"`
+	Labels          map[string]string           `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations     map[string]string           `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Reload          bool                        `yaml:"reload,omitempty"`
+	Public          StackBool                   `yaml:"public,omitempty"`
+	LoadBalancer    string                      `yaml:"load_balancer,omitempty"`
+	NLB             bool                        `yaml:"nlb,omitempty"`
+	ServiceType     apiv1.ServiceType           `yaml:"service_type,omitempty"`
+	SessionAffinity *SessionAffinity            `yaml:"session_affinity,omitempty"`
+	Image           string                      `yaml:"image"`
+	Kind            string                      `yaml:"kind,omitempty"`
+	Schedule        string                      `yaml:"schedule,omitempty"`
+	Build           *BuildInfo                  `yaml:"build,omitempty"`
+	PullPolicy      string                      `yaml:"pull_policy,omitempty"`
+	Replicas        int32                       `yaml:"replicas"`
+	Entrypoint      Entrypoint                  `yaml:"entrypoint,omitempty"`
+	Command         Command                     `yaml:"command,omitempty"`
+	Args            Args                        `yaml:"args,omitempty"`
+	Platforms       map[string]PlatformOverride `yaml:"platforms,omitempty"`
+	Environment     Environment                 `yaml:"environment,omitempty"`
+	EnvFiles        []string                    `yaml:"env_file,omitempty"`
+	EnvFrom         []EnvFromSource             `yaml:"env_from,omitempty"`
+	User            string                      `yaml:"user,omitempty"`
+	CapAdd          []apiv1.Capability          `yaml:"cap_add,omitempty"`
+	CapDrop         []apiv1.Capability          `yaml:"cap_drop,omitempty"`
+	Privileged      bool                        `yaml:"privileged,omitempty"`
+	Devices         []string                    `yaml:"devices,omitempty"`
+	GPU             *GPUInfo                    `yaml:"gpu,omitempty"`
+	Healthchecks    bool                        `yaml:"healthchecks,omitempty"`
+	Healthcheck     *HealthCheck                `yaml:"healthcheck,omitempty"`
+	Ports           []Port                      `yaml:"ports,omitempty"`
+	Expose          []Port                      `yaml:"expose,omitempty"`
+	Endpoints       []ServiceEndpoint           `yaml:"endpoints,omitempty"`
+	Volumes         []string                    `yaml:"volumes,omitempty"`
+	NamedVolumes    []NamedVolumeMount          `yaml:"-"`
+	SourcesChecksum string                      `yaml:"-"`
+	Tmpfs           []string                    `yaml:"tmpfs,omitempty"`
+	MountManifest   string                      `yaml:"mount_manifest,omitempty"`
+	VolumePopulator *VolumePopulator            `yaml:"volume_populator,omitempty"`
+	VolumeInitImage string                      `yaml:"volume_init_image,omitempty"`
+	SkipVolumeChmod bool                        `yaml:"skip_volume_chmod,omitempty"`
+	StopGracePeriod *int64                      `yaml:"stop_grace_period,omitempty"`
+	Resources       StackResources              `yaml:"resources,omitempty"`
+	MemSwappiness   *int64                      `yaml:"mem_swappiness,omitempty"`
+	OOMKillDisable  bool                        `yaml:"oom_kill_disable,omitempty"`
+	StdinOpen       bool                        `yaml:"stdin_open,omitempty"`
+	TTY             bool                        `yaml:"tty,omitempty"`
+	Deploy          *DeployInfo                 `yaml:"deploy,omitempty"`
+	DependsOn       DependsOn                   `yaml:"depends_on,omitempty"`
+	Restart         string                      `yaml:"restart,omitempty"`
+}
+
+//Environment represents the environment variables of a stack service, expanded like ExpandStackEnv
+type Environment []EnvVar
+
+//EnvFromSource represents an existing kubernetes secret or configmap whose keys are loaded as environment variables of a service
+type EnvFromSource struct {
+	SecretRef    *EnvSourceRef `yaml:"secretRef,omitempty"`
+	ConfigMapRef *EnvSourceRef `yaml:"configMapRef,omitempty"`
+}
+
+//EnvSourceRef represents a kubernetes secret or configmap
+type EnvSourceRef struct {
+	Name     string `yaml:"name,omitempty"`
+	Optional bool   `yaml:"optional,omitempty"`
+}
+
+//GPUInfo represents the gpus requested by an okteto stack service.
+//The profile selects a MIG partition, like '1g.5gb', or 'shared' for time-sliced gpus. Without profile, full gpus are requested
+type GPUInfo struct {
+	Count   int64  `yaml:"count,omitempty"`
+	Profile string `yaml:"profile,omitempty"`
+}
+
+//SessionAffinity represents the client ip stickiness of the kubernetes service of an okteto stack service.
+//The timeout is the maximum session sticky time in seconds
+type SessionAffinity struct {
+	Type    apiv1.ServiceAffinity `yaml:"type,omitempty"`
+	Timeout *int32                `yaml:"timeout,omitempty"`
+}
+
+//PlatformOverride represents the command and args of an okteto stack service for a specific platform
+type PlatformOverride struct {
+	Command Command `yaml:"command,omitempty"`
+	Args    Args    `yaml:"args,omitempty"`
+}
+
+//DependsOn represents the services an okteto stack service depends on, and the condition to wait for each of them
+type DependsOn map[string]DependsOnConditionSpec
+
+//DependsOnConditionSpec represents the condition of a dependency of an okteto stack service
+type DependsOnConditionSpec struct {
+	Condition string `yaml:"condition,omitempty"`
+}
+
+//HealthCheck represents the health check of an okteto stack service, translated into its liveness and readiness probes
+type HealthCheck struct {
+	HTTP        *HTTPHealthCheck `yaml:"http_get,omitempty"`
+	Test        HealthCheckTest  `yaml:"test,omitempty"`
+	Interval    time.Duration    `yaml:"interval,omitempty"`
+	Timeout     time.Duration    `yaml:"timeout,omitempty"`
+	Retries     int32            `yaml:"retries,omitempty"`
+	StartPeriod time.Duration    `yaml:"start_period,omitempty"`
+}
+
+//HealthCheckTest represents the command of a health check in any of the compose forms:
+//["CMD", args...] runs the arguments, ["CMD-SHELL", command] or a string run the command with 'sh -c', and ["NONE"] disables the health check
+type HealthCheckTest []string
+
+//HTTPHealthCheck represents the http request of a health check
+type HTTPHealthCheck struct {
+	Path string `yaml:"path,omitempty"`
+	Port int32  `yaml:"port,omitempty"`
+}
+
+//StackBool represents a boolean accepting the 'yes', 'no', 'on', 'off', '1' and '0' forms
+type StackBool bool
+
+//VolumePopulator represents the image used to seed a volume of an okteto stack service
+type VolumePopulator struct {
+	Image  string `yaml:"image,omitempty"`
+	Source string `yaml:"source,omitempty"`
+	Target string `yaml:"target,omitempty"`
+}
+
+//DeployInfo represents the deploy configuration of an okteto stack service
+type DeployInfo struct {
+	Labels       map[string]string `yaml:"labels,omitempty"`
+	EndpointMode string            `yaml:"endpoint_mode,omitempty"`
+	Mode         string            `yaml:"mode,omitempty"`
+	Autoscaling  *AutoscalingInfo  `yaml:"autoscaling,omitempty"`
+	PDB          *PDBInfo          `yaml:"pdb,omitempty"`
+	Resources    *DeployResources  `yaml:"resources,omitempty"`
+}
+
+//DeployResources represents the compose resources of an okteto stack service: 'reservations' are translated into requests and 'limits' into limits
+type DeployResources struct {
+	Limits       DeployResourceList `yaml:"limits,omitempty"`
+	Reservations DeployResourceList `yaml:"reservations,omitempty"`
+}
+
+//DeployResourceList represents the cpus and memory of the compose resources of an okteto stack service
+type DeployResourceList struct {
+	CPUs   Quantity `yaml:"cpus,omitempty"`
+	Memory Quantity `yaml:"memory,omitempty"`
+}
+
+//PDBInfo represents the disruption budget of an okteto stack service: the pods kept alive during voluntary evictions, like node drains.
+//Values are a number of pods or a percentage, like '50%'
+type PDBInfo struct {
+	MinAvailable   string `yaml:"min_available,omitempty"`
+	MaxUnavailable string `yaml:"max_unavailable,omitempty"`
+}
+
+//AutoscalingInfo represents the autoscaling configuration of an okteto stack service
+type AutoscalingInfo struct {
+	Min        int32                `yaml:"min,omitempty"`
+	Max        int32                `yaml:"max,omitempty"`
+	CPUPercent int32                `yaml:"cpu_percent,omitempty"`
+	Metrics    []AutoscalingMetric  `yaml:"metrics,omitempty"`
+	Behavior   *AutoscalingBehavior `yaml:"behavior,omitempty"`
+}
+
+//AutoscalingBehavior represents the scale up and scale down rules of an okteto stack service
+type AutoscalingBehavior struct {
+	ScaleUp   *AutoscalingRules `yaml:"scale_up,omitempty"`
+	ScaleDown *AutoscalingRules `yaml:"scale_down,omitempty"`
+}
+
+//AutoscalingRules represents the rules to scale an okteto stack service in one direction
+type AutoscalingRules struct {
+	StabilizationWindow *int32              `yaml:"stabilization_window,omitempty"`
+	SelectPolicy        string              `yaml:"select_policy,omitempty"`
+	Policies            []AutoscalingPolicy `yaml:"policies,omitempty"`
+}
+
+//AutoscalingPolicy represents the maximum change of replicas allowed in a period of seconds
+type AutoscalingPolicy struct {
+	Type   string `yaml:"type,omitempty"`
+	Value  int32  `yaml:"value,omitempty"`
+	Period int32  `yaml:"period,omitempty"`
+}
+
+//AutoscalingMetric represents a custom or external metric used to autoscale an okteto stack service
+type AutoscalingMetric struct {
+	Type         string            `yaml:"type,omitempty"`
+	Name         string            `yaml:"name,omitempty"`
+	Selector     map[string]string `yaml:"selector,omitempty"`
+	Value        Quantity          `yaml:"value,omitempty"`
+	AverageValue Quantity          `yaml:"average_value,omitempty"`
 }
 
 //StackResources represents an okteto stack resources
@@ -71,15 +395,45 @@ type StackResources struct {
 
 //ServiceResources represents an okteto stack service resources
 type ServiceResources struct {
-	CPU     Quantity        `json:"cpu,omitempty" yaml:"cpu,omitempty"`
-	Memory  Quantity        `json:"memory,omitempty" yaml:"memory,omitempty"`
-	Storage StorageResource `json:"storage,omitempty" yaml:"storage,omitempty"`
+	CPU              Quantity        `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory           Quantity        `json:"memory,omitempty" yaml:"memory,omitempty"`
+	EphemeralStorage Quantity        `json:"ephemeral_storage,omitempty" yaml:"ephemeral_storage,omitempty"`
+	Storage          StorageResource `json:"storage,omitempty" yaml:"storage,omitempty"`
 }
 
 //StorageResource represents an okteto stack service storage resource
 type StorageResource struct {
-	Size  Quantity `json:"size,omitempty" yaml:"size,omitempty"`
-	Class string   `json:"class,omitempty" yaml:"class,omitempty"`
+	Size       Quantity                         `json:"size,omitempty" yaml:"size,omitempty"`
+	Class      string                           `json:"class,omitempty" yaml:"class,omitempty"`
+	AccessMode apiv1.PersistentVolumeAccessMode `json:"access_mode,omitempty" yaml:"access_mode,omitempty"`
+}
+
+//VolumeSpec represents a named volume of an okteto stack, translated into a single volume claim shared by the services mounting it
+type VolumeSpec struct {
+	Size       Quantity                         `yaml:"size,omitempty"`
+	Class      string                           `yaml:"class,omitempty"`
+	AccessMode apiv1.PersistentVolumeAccessMode `yaml:"access_mode,omitempty"`
+	Labels     map[string]string                `yaml:"labels,omitempty"`
+	Persist    *bool                            `yaml:"persist,omitempty"`
+}
+
+//IsPersistent returns true if the volume is kept when the stack is destroyed, the default for named volumes
+func (v *VolumeSpec) IsPersistent() bool {
+	return v.Persist == nil || *v.Persist
+}
+
+//NamedVolumeMount represents a named volume mounted by an okteto stack service, with the format 'NAME:PATH[:ro|rw]'
+type NamedVolumeMount struct {
+	Name      string
+	MountPath string
+	ReadOnly  bool
+}
+
+//StackVolume represents a private volume of an okteto stack service, with the format 'PATH[:SIZE][:ro|rw]'
+type StackVolume struct {
+	MountPath string
+	Size      Quantity
+	ReadOnly  bool
 }
 
 //Quantity represents an okteto stack service storage resource
@@ -87,15 +441,28 @@ type Quantity struct {
 	Value resource.Quantity
 }
 
+//Port represents a port published by an okteto stack service
+type Port struct {
+	Port          int32
+	ContainerPort int32
+	Protocol      apiv1.Protocol
+	NodePort      int32
+}
+
 //Endpoints represents an okteto stack ingress
 type Endpoint struct {
-	Path    string `yaml:"path,omitempty"`
-	Service string `yaml:"service,omitempty"`
-	Port    int32  `yaml:"port,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+	PathType string `yaml:"path_type,omitempty"`
+	Service  string `yaml:"service,omitempty"`
+	Port     int32  `yaml:"port,omitempty"`
 }
 
-//GetStack returns an okteto stack object from a given file
-func GetStack(name, stackPath string) (*Stack, error) {
+//GetStack returns an okteto stack object from a given file or url, with the given overrides applied
+func GetStack(name, stackPath string, overrides []string) (*Stack, error) {
+	if IsStackURL(stackPath) {
+		return getStackFromURL(name, stackPath, overrides)
+	}
+
 	b, err := ioutil.ReadFile(stackPath)
 	if err != nil {
 		return nil, err
@@ -115,6 +482,9 @@ func GetStack(name, stackPath string) (*Stack, error) {
 			return nil, err
 		}
 	}
+	if err := s.ApplyOverrides(overrides); err != nil {
+		return nil, err
+	}
 	if err := s.validate(); err != nil {
 		return nil, err
 	}
@@ -123,7 +493,46 @@ func GetStack(name, stackPath string) (*Stack, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.loadAbsPaths(stackDir)
+	return s, nil
+}
+
+//GetStackFromBytes returns a validated okteto stack object from a manifest, like the ones stored by previous deployments,
+//with the overrides of that deployment applied again and its build paths resolved against 'dir', the folder of the original manifest
+func GetStackFromBytes(name, namespace, dir string, b []byte, overrides StackOverrides) (*Stack, error) {
+	s, err := ReadStack(b)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		s.Name = name
+	}
+	if namespace != "" {
+		s.Namespace = namespace
+	}
+	if err := s.ApplyOverrides(overrides.Set); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		s.loadAbsPaths(dir)
+	}
+	for _, command := range overrides.Commands {
+		if err := s.OverrideCommand(command); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.OverrideImages(overrides.Images); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
 
+//loadAbsPaths resolves the build context and dockerfile of every service against the folder of the stack manifest
+func (s *Stack) loadAbsPaths(stackDir string) {
+	s.Dir = stackDir
 	for name, svc := range s.Services {
 		if svc.Build == nil {
 			continue
@@ -132,9 +541,89 @@ func GetStack(name, stackPath string) (*Stack, error) {
 		svc.Build.Dockerfile = loadAbsPath(stackDir, svc.Build.Dockerfile)
 		s.Services[name] = svc
 	}
+}
+
+//IsStackURL returns true if the stack manifest path is an http(s) url
+func IsStackURL(stackPath string) bool {
+	return strings.HasPrefix(stackPath, "http://") || strings.HasPrefix(stackPath, "https://")
+}
+
+func getStackFromURL(name, stackURL string, overrides []string) (*Stack, error) {
+	b, err := downloadStackManifest(stackURL)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := ReadStack(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		s.Name = name
+	}
+	if s.Name == "" {
+		return nil, fmt.Errorf("Invalid stack name: 'name' is required when the stack manifest is loaded from a url")
+	}
+	if err := s.ApplyOverrides(overrides); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	for name, svc := range s.Services {
+		if svc.Build != nil {
+			return nil, fmt.Errorf("Invalid service '%s': 'build' is not supported when the stack manifest is loaded from a url", name)
+		}
+		if len(svc.EnvFiles) > 0 {
+			return nil, fmt.Errorf("Invalid service '%s': 'env_file' is not supported when the stack manifest is loaded from a url", name)
+		}
+	}
 	return s, nil
 }
 
+func downloadStackManifest(stackURL string) ([]byte, error) {
+	c := &http.Client{Timeout: stackHTTPTimeout}
+	resp, err := c.Get(stackURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download stack manifest '%s': %s", stackURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download stack manifest '%s': %s", stackURL, resp.Status)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !isStackMediaType(mediaType) {
+			return nil, fmt.Errorf("failed to download stack manifest '%s': unsupported content type '%s'", stackURL, contentType)
+		}
+	}
+
+	if resp.ContentLength > maxStackManifestSize {
+		return nil, fmt.Errorf("failed to download stack manifest '%s': manifest is bigger than %d bytes", stackURL, maxStackManifestSize)
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxStackManifestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download stack manifest '%s': %s", stackURL, err)
+	}
+	if len(b) > maxStackManifestSize {
+		return nil, fmt.Errorf("failed to download stack manifest '%s': manifest is bigger than %d bytes", stackURL, maxStackManifestSize)
+	}
+	return b, nil
+}
+
+func isStackMediaType(mediaType string) bool {
+	switch mediaType {
+	case "text/plain", "text/yaml", "text/x-yaml", "application/yaml", "application/x-yaml", "application/octet-stream":
+		return true
+	}
+	return false
+}
+
 //ReadStack reads an okteto stack
 func ReadStack(bytes []byte) (*Stack, error) {
 	s := &Stack{
@@ -159,6 +648,88 @@ func ReadStack(bytes []byte) (*Stack, error) {
 		msg = strings.TrimSuffix(msg, "in type model.Stack")
 		return nil, errors.New(msg)
 	}
+	if err := s.expandEnv(); err != nil {
+		return nil, err
+	}
+	s.Normalize()
+	if err := s.expandServiceEndpoints(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+//expandEnv expands the environment variables of the images and volumes of the services, so required variables fail the stack load.
+//Environment values are expanded when they are unmarshalled, and the images of built services are only checked here,
+//because translateBuildImage needs the templated image to know if it resolves to an external registry
+func (s *Stack) expandEnv() error {
+	for name, svc := range s.Services {
+		image, err := ExpandStackEnv(svc.Image)
+		if err != nil {
+			return fmt.Errorf("Invalid image in service '%s': %s", name, err)
+		}
+		if svc.Build == nil {
+			svc.Image = image
+		}
+		for i := range svc.Volumes {
+			svc.Volumes[i], err = ExpandStackEnv(svc.Volumes[i])
+			if err != nil {
+				return fmt.Errorf("Invalid volume in service '%s': %s", name, err)
+			}
+		}
+		s.Services[name] = svc
+	}
+	return nil
+}
+
+//expandServiceEndpoints adds the 'endpoints' of the services to the endpoints of the stack, so each port is routed by its own ingress
+func (s *Stack) expandServiceEndpoints() error {
+	names := make([]string, 0, len(s.Services))
+	for name := range s.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	stackEndpoints := map[string]bool{}
+	for name := range s.Endpoints {
+		stackEndpoints[name] = true
+	}
+	for _, svcName := range names {
+		svc := s.Services[svcName]
+		for _, e := range svc.Endpoints {
+			name := e.Name
+			if name == "" {
+				name = fmt.Sprintf("%s-%d", svcName, e.Port)
+			}
+			if stackEndpoints[name] {
+				return fmt.Errorf("Invalid endpoint '%s' in service '%s': it is already defined in 'endpoints'", name, svcName)
+			}
+			if !isTCPPortInService(e.Port, svc.Ports) {
+				return fmt.Errorf("Invalid endpoint '%s' in service '%s': port '%d' is not a tcp port published in 'ports'", name, svcName, e.Port)
+			}
+			path := e.Path
+			if path == "" {
+				path = "/"
+			}
+			if s.Endpoints == nil {
+				s.Endpoints = map[string][]Endpoint{}
+			}
+			s.Endpoints[name] = append(s.Endpoints[name], Endpoint{Path: path, PathType: e.PathType, Service: svcName, Port: e.Port})
+		}
+	}
+	return nil
+}
+
+func isTCPPortInService(port int32, ports []Port) bool {
+	for _, p := range ports {
+		if p.Port == port && p.GetProtocol() == apiv1.ProtocolTCP {
+			return true
+		}
+	}
+	return false
+}
+
+//Normalize applies the default values of an okteto stack, and can be safely re-applied
+func (s *Stack) Normalize() {
 	for i, svc := range s.Services {
 		if svc.Build != nil {
 			if svc.Build.Name != "" {
@@ -171,21 +742,68 @@ func ReadStack(bytes []byte) (*Stack, error) {
 			svc.Replicas = 1
 		}
 		if len(svc.Entrypoint.Values) > 0 {
-			svc.Args.Values = nil
 			svc.Args.Values = svc.Command.Values
 			svc.Command.Values = svc.Entrypoint.Values
+			svc.Entrypoint.Values = nil
+		}
+		if svc.Deploy != nil && svc.Deploy.Resources != nil {
+			normalizeDeployResources(svc.Deploy.Resources, &svc.Resources)
+		}
+		if svc.Deploy != nil && svc.Deploy.Autoscaling != nil && svc.Deploy.Autoscaling.Min == 0 {
+			svc.Deploy.Autoscaling.Min = 1
 		}
 		if len(svc.Expose) > 0 && len(svc.Ports) == 0 {
 			svc.Public = false
 		}
-
-		if len(svc.Expose) > 0 {
-			svc.Ports = append(svc.Ports, svc.Expose...)
+		svc.Volumes, svc.NamedVolumes = splitNamedVolumes(svc.Volumes, svc.NamedVolumes)
+		if len(svc.Volumes) == 1 && svc.Resources.Requests.Storage.Size.Value.IsZero() {
+			if v, err := ParseVolume(svc.Volumes[0]); err == nil && !v.Size.Value.IsZero() {
+				svc.Resources.Requests.Storage.Size = v.Size
+				v.Size = Quantity{}
+				svc.Volumes = []string{v.String()}
+			}
 		}
 
 		s.Services[i] = svc
 	}
-	return s, nil
+}
+
+//normalizeDeployResources sets the requests and limits of a service from its compose resources, which take precedence over 'resources'
+func normalizeDeployResources(deploy *DeployResources, resources *StackResources) {
+	if !deploy.Reservations.CPUs.Value.IsZero() {
+		resources.Requests.CPU = deploy.Reservations.CPUs
+	}
+	if !deploy.Reservations.Memory.Value.IsZero() {
+		resources.Requests.Memory = deploy.Reservations.Memory
+	}
+	if !deploy.Limits.CPUs.Value.IsZero() {
+		resources.Limits.CPU = deploy.Limits.CPUs
+	}
+	if !deploy.Limits.Memory.Value.IsZero() {
+		resources.Limits.Memory = deploy.Limits.Memory
+	}
+}
+
+//splitNamedVolumes moves the volumes with the format 'NAME:PATH' to the named volumes of a service,
+//so 'volumes' only keeps the private volumes of the service
+func splitNamedVolumes(volumes []string, named []NamedVolumeMount) ([]string, []NamedVolumeMount) {
+	var private []string
+	for _, v := range volumes {
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) == 2 && parts[0] != "" && !strings.ContainsAny(parts[0], "/.~") {
+			mount := NamedVolumeMount{Name: parts[0], MountPath: parts[1]}
+			if i := strings.LastIndex(mount.MountPath, ":"); i >= 0 {
+				if readOnly, ok := parseVolumeMode(mount.MountPath[i+1:]); ok {
+					mount.MountPath = mount.MountPath[:i]
+					mount.ReadOnly = readOnly
+				}
+			}
+			named = append(named, mount)
+			continue
+		}
+		private = append(private, v)
+	}
+	return private, named
 }
 
 func (s *Stack) validate() error {
@@ -196,16 +814,49 @@ func (s *Stack) validate() error {
 		return fmt.Errorf("Invalid stack: 'services' cannot be empty")
 	}
 
+	if err := validateOktetoOptions(&s.Okteto); err != nil {
+		return err
+	}
+
 	for endpointName, endpoints := range s.Endpoints {
 		for _, endpoint := range endpoints {
-			if service, ok := s.Services[endpoint.Service]; !ok {
+			service, ok := s.Services[endpoint.Service]
+			if !ok {
 				return fmt.Errorf("Invalid endpoint '%s': service '%s' does not exist.", endpointName, endpoint.Service)
-			} else if IsPortInService(endpoint.Port, service.Ports) {
+			}
+			if !IsPortInService(endpoint.Port, service.GetPorts()) {
+				if owners := s.GetServiceByPort(endpoint.Port); len(owners) > 0 {
+					return fmt.Errorf("Invalid endpoint '%s': service '%s' does not have port '%d'. Port '%d' is exposed by '%s', did you mean that?", endpointName, endpoint.Service, endpoint.Port, endpoint.Port, strings.Join(owners, "', '"))
+				}
 				return fmt.Errorf("Invalid endpoint '%s': service '%s' does not have port '%d'.", endpointName, endpoint.Service, endpoint.Port)
 			}
+			if !isTCPPortInService(endpoint.Port, service.GetPorts()) {
+				return fmt.Errorf("Invalid endpoint '%s': port '%d' of service '%s' is not a tcp port", endpointName, endpoint.Port, endpoint.Service)
+			}
+			if !isTCPPortInService(endpoint.Port, service.Ports) {
+				return fmt.Errorf("Invalid endpoint '%s': port '%d' of service '%s' is only exposed to other services: endpoints must route to ports in 'ports'", endpointName, endpoint.Port, endpoint.Service)
+			}
+			if endpoint.Path != "" && !strings.HasPrefix(endpoint.Path, "/") {
+				return fmt.Errorf("Invalid endpoint '%s': path '%s' must start with '/'", endpointName, endpoint.Path)
+			}
+			switch endpoint.GetPathType() {
+			case PrefixPathType, ExactPathType, ImplementationSpecificPathType:
+			default:
+				return fmt.Errorf("Invalid endpoint '%s': path_type '%s' is not supported: supported values are '%s', '%s' and '%s'", endpointName, endpoint.PathType, PrefixPathType, ExactPathType, ImplementationSpecificPathType)
+			}
+		}
+	}
+
+	for name, volume := range s.Volumes {
+		if err := validateStackName(name); err != nil {
+			return fmt.Errorf("Invalid volume name '%s': %s", name, err)
+		}
+		if err := validateAccessMode(volume.AccessMode); err != nil {
+			return fmt.Errorf("Invalid access_mode in volume '%s': %s", name, err)
 		}
 	}
 
+	nodePorts := map[Port]string{}
 	for name, svc := range s.Services {
 		if err := validateStackName(name); err != nil {
 			return fmt.Errorf("Invalid service name '%s': %s", name, err)
@@ -213,31 +864,1459 @@ func (s *Stack) validate() error {
 		if svc.Image == "" && svc.Build == nil {
 			return fmt.Errorf(fmt.Sprintf("Invalid service '%s': image cannot be empty", name))
 		}
-		for _, v := range svc.Volumes {
-			if !strings.HasPrefix(v, "/") {
-				return fmt.Errorf(fmt.Sprintf("Invalid volume '%s' in service '%s': must be an absolute path", v, name))
+		if s.Okteto.RequireImageTags && svc.Image != "" && !hasExplicitImageTag(svc.Image) {
+			return fmt.Errorf("Invalid image '%s' in service '%s': 'requireImageTags' is enabled and the image must have a tag other than 'latest' or a digest", svc.Image, name)
+		}
+		if err := validateExecCommand(svc.Command.Values); err != nil {
+			return fmt.Errorf("Invalid command in service '%s': %s", name, err)
+		}
+		for platform, override := range svc.Platforms {
+			if err := validatePlatform(platform); err != nil {
+				return fmt.Errorf("Invalid platform '%s' in service '%s': %s", platform, name, err)
 			}
-			if strings.Contains(v, ":") {
-				return fmt.Errorf(fmt.Sprintf("Invalid volume '%s' in service '%s': volume bind mounts are not supported", v, name))
+			if err := validateExecCommand(override.Command.Values); err != nil {
+				return fmt.Errorf("Invalid command of platform '%s' in service '%s': %s", platform, name, err)
 			}
 		}
-	}
-
-	return nil
-}
-
-func IsPortInService(port int32, portList []int32) bool {
-	for _, p := range portList {
-		if p == port {
-			return true
+		if err := validateStackPullPolicy(svc.PullPolicy); err != nil {
+			return fmt.Errorf("Invalid pull_policy in service '%s': %s", name, err)
 		}
-	}
-	return false
-}
-
-func validateStackName(name string) error {
-	if name == "" {
-		return fmt.Errorf("name cannot be empty")
+		if err := validateRestart(svc.Restart); err != nil {
+			return fmt.Errorf("Invalid restart in service '%s': %s", name, err)
+		}
+		if err := validateServiceKind(&svc); err != nil {
+			return fmt.Errorf("Invalid kind in service '%s': %s", name, err)
+		}
+		if err := validateSchedule(&svc); err != nil {
+			return fmt.Errorf("Invalid schedule in service '%s': %s", name, err)
+		}
+		if svc.Build != nil {
+			if err := validateBuildNetwork(svc.Build.Network); err != nil {
+				return fmt.Errorf("Invalid build network in service '%s': %s", name, err)
+			}
+			if err := validateBuildArgs(svc.Build.Args); err != nil {
+				return fmt.Errorf("Invalid build args in service '%s': %s", name, err)
+			}
+		}
+		for _, v := range svc.Volumes {
+			volume, err := ParseVolume(v)
+			if err != nil {
+				return fmt.Errorf("Invalid volume '%s' in service '%s': %s", v, name, err)
+			}
+			if volume.Size.Value.IsZero() {
+				continue
+			}
+			if len(svc.Volumes) > 1 {
+				return fmt.Errorf("Invalid volume '%s' in service '%s': the size can only be set inline in services with a single volume", v, name)
+			}
+			if !svc.Resources.Requests.Storage.Size.Value.IsZero() {
+				return fmt.Errorf("Invalid volume '%s' in service '%s': the size conflicts with 'resources.requests.storage.size'", v, name)
+			}
+		}
+		for _, v := range svc.NamedVolumes {
+			if _, ok := s.Volumes[v.Name]; !ok {
+				return fmt.Errorf("Invalid volume '%s:%s' in service '%s': volume '%s' is not defined in 'volumes'", v.Name, v.MountPath, name, v.Name)
+			}
+			if !strings.HasPrefix(v.MountPath, "/") {
+				return fmt.Errorf("Invalid volume '%s:%s' in service '%s': the mount path must be an absolute path", v.Name, v.MountPath, name)
+			}
+		}
+		if err := validateStorageAccessMode(&svc); err != nil {
+			return fmt.Errorf("Invalid access_mode in service '%s': %s", name, err)
+		}
+		for _, e := range svc.Environment {
+			if errs := validation.IsEnvVarName(e.Name); len(errs) > 0 {
+				return fmt.Errorf("Invalid environment variable '%s' in service '%s': %s", e.Name, name, strings.Join(errs, ", "))
+			}
+			if e.ValueFrom != nil {
+				if e.Value != "" {
+					return fmt.Errorf("Invalid environment variable '%s' in service '%s': 'value' and 'valueFrom' can't be used together", e.Name, name)
+				}
+				if err := validateEnvVarSource(e.ValueFrom); err != nil {
+					return fmt.Errorf("Invalid valueFrom of environment variable '%s' in service '%s': %s", e.Name, name, err)
+				}
+			}
+		}
+		for _, source := range svc.EnvFrom {
+			if err := validateEnvFromSource(source); err != nil {
+				return fmt.Errorf("Invalid env_from in service '%s': %s", name, err)
+			}
+		}
+		if svc.StdinOpen != svc.TTY {
+			return fmt.Errorf("Invalid service '%s': 'stdin_open' and 'tty' must be used together to attach to its container", name)
+		}
+		if _, _, err := ParseUser(svc.User); err != nil {
+			return fmt.Errorf("Invalid user '%s' in service '%s': %s", svc.User, name, err)
+		}
+		if capability := getConflictingCapability(svc.CapAdd, svc.CapDrop); capability != "" {
+			return fmt.Errorf("Invalid capabilities in service '%s': '%s' can't be both in 'cap_add' and 'cap_drop'", name, capability)
+		}
+		if svc.Privileged {
+			for _, c := range svc.CapDrop {
+				if c == "ALL" {
+					return fmt.Errorf("Invalid service '%s': 'privileged' grants all capabilities and contradicts 'cap_drop: [ALL]'", name)
+				}
+			}
+		}
+		for _, d := range svc.Devices {
+			if _, _, err := ParseDevice(d); err != nil {
+				return fmt.Errorf("Invalid device '%s' in service '%s': %s", d, name, err)
+			}
+		}
+		if svc.GPU != nil {
+			if err := validateGPU(svc.GPU); err != nil {
+				return fmt.Errorf("Invalid gpu in service '%s': %s", name, err)
+			}
+		}
+		if err := validatePorts(svc.Ports, svc.Expose); err != nil {
+			return fmt.Errorf("Invalid ports in service '%s': %s", name, err)
+		}
+		if err := validateServiceType(&svc); err != nil {
+			return fmt.Errorf("Invalid service_type in service '%s': %s", name, err)
+		}
+		if svc.SessionAffinity != nil {
+			if err := validateSessionAffinity(svc.SessionAffinity); err != nil {
+				return fmt.Errorf("Invalid session_affinity in service '%s': %s", name, err)
+			}
+		}
+		for _, p := range svc.Ports {
+			if p.NodePort == 0 {
+				continue
+			}
+			key := Port{Port: p.NodePort, Protocol: p.GetProtocol()}
+			if other, ok := nodePorts[key]; ok {
+				return fmt.Errorf("Invalid ports in service '%s': node port '%s' is already used by service '%s'", name, key.String(), other)
+			}
+			nodePorts[key] = name
+		}
+		if svc.Public && len(svc.GetPorts()) == 0 {
+			return fmt.Errorf("Invalid service '%s': public services must publish at least one port in 'ports'", name)
+		}
+		for _, t := range svc.Tmpfs {
+			if _, _, err := ParseTmpfs(t); err != nil {
+				return fmt.Errorf("Invalid tmpfs '%s' in service '%s': %s", t, name, err)
+			}
+		}
+		if svc.Healthcheck != nil {
+			if svc.Healthchecks {
+				return fmt.Errorf("Invalid healthcheck in service '%s': 'healthcheck' and 'healthchecks' can't be used together", name)
+			}
+			if err := validateHealthCheck(svc.Healthcheck); err != nil {
+				return fmt.Errorf("Invalid healthcheck in service '%s': %s", name, err)
+			}
+		}
+		if svc.StopGracePeriod != nil && *svc.StopGracePeriod < 0 {
+			return fmt.Errorf("Invalid stop_grace_period in service '%s': must be greater than or equal to 0", name)
+		}
+		if svc.VolumePopulator != nil {
+			if err := validateVolumePopulator(svc.VolumePopulator, svc.Volumes); err != nil {
+				return fmt.Errorf("Invalid volume_populator in service '%s': %s", name, err)
+			}
+		}
+		if svc.MountManifest != "" && (!filepath.IsAbs(svc.MountManifest) || svc.MountManifest == "/") {
+			return fmt.Errorf("Invalid mount_manifest '%s' in service '%s': must be an absolute file path", svc.MountManifest, name)
+		}
+		if svc.Deploy != nil {
+			if err := validateEndpointMode(svc.Deploy.EndpointMode, bool(svc.Public)); err != nil {
+				return fmt.Errorf("Invalid endpoint_mode in service '%s': %s", name, err)
+			}
+			if err := validateDeployMode(&svc); err != nil {
+				return fmt.Errorf("Invalid mode in service '%s': %s", name, err)
+			}
+		}
+		if svc.Deploy != nil && svc.Deploy.Autoscaling != nil {
+			if err := validateAutoscaling(svc.Deploy.Autoscaling); err != nil {
+				return fmt.Errorf("Invalid autoscaling in service '%s': %s", name, err)
+			}
+			if svc.Deploy.Autoscaling.CPUPercent > 0 && svc.Resources.Requests.CPU.Value.IsZero() && svc.Resources.Limits.CPU.Value.IsZero() {
+				return fmt.Errorf("Invalid autoscaling in service '%s': 'cpu_percent' is relative to the cpu requested by the service, define 'resources.requests.cpu'", name)
+			}
+		}
+		if err := validateLoadBalancer(&svc); err != nil {
+			return fmt.Errorf("Invalid load_balancer in service '%s': %s", name, err)
+		}
+		if svc.Deploy != nil && svc.Deploy.PDB != nil {
+			if err := validatePDB(svc.Deploy.PDB); err != nil {
+				return fmt.Errorf("Invalid pdb in service '%s': %s", name, err)
+			}
+		}
+		for dependency, spec := range svc.DependsOn {
+			if dependency == name {
+				return fmt.Errorf("Invalid depends_on in service '%s': a service cannot depend on itself", name)
+			}
+			if _, ok := s.Services[dependency]; !ok {
+				return fmt.Errorf("Invalid depends_on in service '%s': service '%s' does not exist", name, dependency)
+			}
+			if err := validateDependsOnCondition(spec.Condition); err != nil {
+				return fmt.Errorf("Invalid depends_on '%s' in service '%s': %s", dependency, name, err)
+			}
+			if spec.Condition == DependsOnServiceHealthy {
+				if dependencySvc := s.Services[dependency]; dependencySvc.IsCronJob() {
+					return fmt.Errorf("Invalid depends_on '%s' in service '%s': condition '%s' is not supported by scheduled services", dependency, name, DependsOnServiceHealthy)
+				}
+			}
+		}
+	}
+
+	if cycle := s.getDependsOnCycle(); len(cycle) > 0 {
+		return fmt.Errorf("Invalid depends_on: there is a dependency cycle between services '%s'", strings.Join(cycle, "' -> '"))
+	}
+
+	return nil
+}
+
+func validateDependsOnCondition(condition string) error {
+	switch condition {
+	case DependsOnServiceStarted, DependsOnServiceHealthy:
+		return nil
+	}
+	return fmt.Errorf("condition '%s' is not supported: supported values are '%s' and '%s'", condition, DependsOnServiceStarted, DependsOnServiceHealthy)
+}
+
+//getDependsOnCycle returns the services of a depends_on cycle, or nil if there are no cycles
+func (s *Stack) getDependsOnCycle() []string {
+	names := []string{}
+	for name := range s.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	visited := map[string]bool{}
+	for _, name := range names {
+		if cycle := s.visitDependsOn(name, []string{}, visited); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+func (s *Stack) visitDependsOn(name string, path []string, visited map[string]bool) []string {
+	for i, p := range path {
+		if p == name {
+			return append(append([]string{}, path[i:]...), name)
+		}
+	}
+	if visited[name] {
+		return nil
+	}
+	visited[name] = true
+
+	path = append(path, name)
+	dependencies := []string{}
+	for dependency := range s.Services[name].DependsOn {
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Strings(dependencies)
+	for _, dependency := range dependencies {
+		if cycle := s.visitDependsOn(dependency, path, visited); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+//isReservedEnvVar returns true if the name matches one of the service environment variables injected by Kubernetes
+func (s *Stack) isReservedEnvVar(name string) bool {
+	svcPrefixes := []string{"KUBERNETES"}
+	for svcName, svc := range s.Services {
+		if len(svc.GetPorts()) > 0 {
+			svcPrefixes = append(svcPrefixes, strings.ToUpper(strings.ReplaceAll(svcName, "-", "_")))
+		}
+	}
+	for _, prefix := range svcPrefixes {
+		if name == fmt.Sprintf("%s_PORT", prefix) {
+			return true
+		}
+		if strings.HasPrefix(name, fmt.Sprintf("%s_PORT_", prefix)) || strings.HasPrefix(name, fmt.Sprintf("%s_SERVICE_", prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateHealthCheck(h *HealthCheck) error {
+	if h.HTTP != nil && len(h.Test) > 0 {
+		return fmt.Errorf("'test' and 'http_get' can't be used together")
+	}
+	if h.HTTP == nil && len(h.Test) == 0 {
+		return fmt.Errorf("either 'test' or 'http_get' must be defined")
+	}
+	if len(h.Test) > 0 && !h.Test.IsDisabled() {
+		if len(h.Test.GetCommand()) == 0 {
+			return fmt.Errorf("'test' must define a command after '%s'", h.Test[0])
+		}
+		if err := validateExecCommand(h.Test.GetCommand()); err != nil {
+			return fmt.Errorf("'test' is not valid: %s", err)
+		}
+	}
+	if h.HTTP != nil {
+		if h.HTTP.Port < 1 || h.HTTP.Port > 65535 {
+			return fmt.Errorf("'http_get.port' must be a number between 1 and 65535")
+		}
+		if !strings.HasPrefix(h.HTTP.Path, "/") {
+			return fmt.Errorf("'http_get.path' must start with '/'")
+		}
+	}
+	if h.Interval < 0 || h.Timeout < 0 || h.StartPeriod < 0 {
+		return fmt.Errorf("'interval', 'timeout' and 'start_period' cannot be negative")
+	}
+	if h.Retries < 0 {
+		return fmt.Errorf("'retries' cannot be negative")
+	}
+	return nil
+}
+
+//validateExecCommand checks the executable of a command isn't empty, as left by environment variables expanded to empty strings.
+//An empty command is valid: the command of the image is run then
+func validateExecCommand(command []string) error {
+	if len(command) > 0 && strings.TrimSpace(command[0]) == "" {
+		return fmt.Errorf("the executable of the command '%s' is empty", strings.Join(command, " "))
+	}
+	return nil
+}
+
+//IsDisabled returns true if the health check is disabled with ["NONE"]
+func (t HealthCheckTest) IsDisabled() bool {
+	return len(t) > 0 && t[0] == HealthCheckTestNone
+}
+
+//GetCommand returns the command run by the health check
+func (t HealthCheckTest) GetCommand() []string {
+	if len(t) == 0 {
+		return nil
+	}
+	switch t[0] {
+	case HealthCheckTestCmd:
+		return t[1:]
+	case HealthCheckTestCmdShell:
+		command := strings.TrimSpace(strings.Join(t[1:], " "))
+		if command == "" {
+			return nil
+		}
+		return []string{"sh", "-c", command}
+	}
+	return t
+}
+
+//HasGuaranteedResources returns true if the service sets 'oom_kill_disable' and its requests can be set to its limits to get the Guaranteed QoS class
+func (svc *Service) HasGuaranteedResources() bool {
+	return svc.OOMKillDisable && !svc.Resources.Limits.CPU.Value.IsZero() && !svc.Resources.Limits.Memory.Value.IsZero()
+}
+
+func validateVolumePopulator(p *VolumePopulator, volumes []string) error {
+	if p.Image == "" {
+		return fmt.Errorf("'image' cannot be empty")
+	}
+	if !filepath.IsAbs(p.Source) {
+		return fmt.Errorf("'source' must be an absolute path")
+	}
+	for _, v := range volumes {
+		if volume, _ := ParseVolume(v); volume.MountPath == p.Target {
+			return nil
+		}
+	}
+	return fmt.Errorf("'target' must be one of the service volumes")
+}
+
+func validateBuildNetwork(network string) error {
+	switch network {
+	case "", "default", "host", "none":
+		return nil
+	}
+	return fmt.Errorf("'%s' is not supported: supported values are 'default', 'host' and 'none'", network)
+}
+
+//validateBuildArgs rejects 'valueFrom' in build args: they are resolved when the image is built, outside of the cluster
+func validateBuildArgs(args []EnvVar) error {
+	for _, arg := range args {
+		if arg.ValueFrom != nil {
+			return fmt.Errorf("'valueFrom' is not supported in build arg '%s'", arg.Name)
+		}
+	}
+	return nil
+}
+
+func validatePlatform(platform string) error {
+	parts := strings.Split(platform, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("must have the format 'os/arch'")
+	}
+	if parts[0] != "linux" {
+		return fmt.Errorf("'%s' is not supported: the only supported os is 'linux'", parts[0])
+	}
+	switch parts[1] {
+	case "amd64", "arm64", "arm", "386", "ppc64le", "s390x":
+		return nil
+	}
+	return fmt.Errorf("'%s' is not supported: supported archs are 'amd64', 'arm64', 'arm', '386', 'ppc64le' and 's390x'", parts[1])
+}
+
+func validateStackPullPolicy(pullPolicy string) error {
+	switch pullPolicy {
+	case "", "always", "never", "missing", "if_not_present":
+		return nil
+	}
+	return fmt.Errorf("'%s' is not supported: supported values are 'always', 'never', 'missing' and 'if_not_present'", pullPolicy)
+}
+
+func validateServiceKind(svc *Service) error {
+	switch svc.Kind {
+	case "":
+		return nil
+	case JobServiceKind:
+		if len(svc.GetPorts()) > 0 {
+			return fmt.Errorf("jobs can't publish or expose ports")
+		}
+		if len(svc.Volumes) > 0 {
+			return fmt.Errorf("jobs don't support volumes")
+		}
+		if svc.Deploy != nil && svc.Deploy.Autoscaling != nil {
+			return fmt.Errorf("jobs don't support autoscaling")
+		}
+		return nil
+	}
+	return fmt.Errorf("'%s' is not supported: the only supported value is '%s'", svc.Kind, JobServiceKind)
+}
+
+//validateSchedule checks the cron expression of a service run as a kubernetes cronjob
+func validateSchedule(svc *Service) error {
+	if svc.Schedule == "" {
+		return nil
+	}
+	if len(svc.GetPorts()) > 0 {
+		return fmt.Errorf("scheduled services can't publish or expose ports")
+	}
+	if len(svc.Volumes) > 0 {
+		return fmt.Errorf("scheduled services don't support volumes")
+	}
+	if svc.Deploy != nil && svc.Deploy.Autoscaling != nil {
+		return fmt.Errorf("scheduled services don't support autoscaling")
+	}
+	return validateCronExpression(svc.Schedule)
+}
+
+//validateCronExpression accepts the cron expressions supported by kubernetes cronjobs:
+//five space separated fields, or one of the predefined schedules like '@daily'
+func validateCronExpression(expression string) error {
+	if strings.HasPrefix(expression, "@") {
+		switch expression {
+		case "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly":
+			return nil
+		}
+		return fmt.Errorf("'%s' is not a predefined schedule", expression)
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("'%s' must have %d fields: minute, hour, day of month, month and day of week", expression, len(cronFields))
+	}
+	for i, f := range fields {
+		if err := cronFields[i].validate(f); err != nil {
+			return fmt.Errorf("'%s' has an invalid %s: %s", expression, cronFields[i].name, err)
+		}
+	}
+	return nil
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 6, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+func (f cronField) validate(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		rangeValue := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangeValue = part[:i]
+			step, err := strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return fmt.Errorf("'%s' is not a valid step", part[i+1:])
+			}
+		}
+		if rangeValue == "*" || rangeValue == "?" {
+			continue
+		}
+		bounds := strings.SplitN(rangeValue, "-", 2)
+		start, err := f.parse(bounds[0])
+		if err != nil {
+			return err
+		}
+		if len(bounds) == 2 {
+			end, err := f.parse(bounds[1])
+			if err != nil {
+				return err
+			}
+			if end < start {
+				return fmt.Errorf("'%s' is not a valid range", rangeValue)
+			}
+		}
+	}
+	return nil
+}
+
+func (f cronField) parse(value string) (int, error) {
+	for i, n := range f.names {
+		if strings.EqualFold(value, n) {
+			return f.min + i, nil
+		}
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil || result < f.min || result > f.max {
+		return 0, fmt.Errorf("'%s' must be between %d and %d", value, f.min, f.max)
+	}
+	return result, nil
+}
+
+func validateRestart(restart string) error {
+	switch restart {
+	case "", RestartNo, RestartAlways, RestartOnFailure, RestartUnlessStopped:
+		return nil
+	}
+	return fmt.Errorf("'%s' is not supported: supported values are '%s', '%s', '%s' and '%s'", restart, RestartNo, RestartAlways, RestartOnFailure, RestartUnlessStopped)
+}
+
+func validateEndpointMode(endpointMode string, public bool) error {
+	switch endpointMode {
+	case "", VIPEndpointMode:
+		return nil
+	case DNSRREndpointMode:
+		if public {
+			return fmt.Errorf("'%s' is not supported for public services", endpointMode)
+		}
+		return nil
+	}
+	return fmt.Errorf("'%s' is not supported: supported values are '%s' and '%s'", endpointMode, VIPEndpointMode, DNSRREndpointMode)
+}
+
+func validateDeployMode(svc *Service) error {
+	switch svc.Deploy.Mode {
+	case "", ReplicatedDeployMode:
+		return nil
+	case GlobalDeployMode:
+	default:
+		return fmt.Errorf("'%s' is not supported: supported values are '%s' and '%s'", svc.Deploy.Mode, ReplicatedDeployMode, GlobalDeployMode)
+	}
+	if svc.IsJob() || svc.IsCronJob() {
+		return fmt.Errorf("'%s' is not supported by jobs and cronjobs", GlobalDeployMode)
+	}
+	if len(svc.Volumes) > 0 || len(svc.NamedVolumes) > 0 {
+		return fmt.Errorf("'%s' is not supported by services with volumes: the pods of every node can't share their volume claims", GlobalDeployMode)
+	}
+	if svc.Deploy.Autoscaling != nil {
+		return fmt.Errorf("'%s' is not supported by services with 'autoscaling'", GlobalDeployMode)
+	}
+	if svc.Deploy.PDB != nil {
+		return fmt.Errorf("'%s' is not supported by services with 'pdb'", GlobalDeployMode)
+	}
+	return nil
+}
+
+//validateEnvVarSource checks that the value of an environment variable comes from exactly one key of a secret or configmap
+func validateEnvVarSource(source *EnvVarSource) error {
+	var ref *EnvKeyRef
+	switch {
+	case source.SecretKeyRef != nil && source.ConfigMapKeyRef != nil:
+		return fmt.Errorf("only one of 'secretKeyRef' and 'configMapKeyRef' can be set")
+	case source.SecretKeyRef != nil:
+		ref = source.SecretKeyRef
+	case source.ConfigMapKeyRef != nil:
+		ref = source.ConfigMapKeyRef
+	default:
+		return fmt.Errorf("one of 'secretKeyRef' and 'configMapKeyRef' must be set")
+	}
+	if ref.Name == "" {
+		return fmt.Errorf("'name' is required")
+	}
+	if ref.Key == "" {
+		return fmt.Errorf("'key' is required")
+	}
+	return nil
+}
+
+//validateEnvFromSource checks that an env_from entry references exactly one secret or configmap
+func validateEnvFromSource(source EnvFromSource) error {
+	var ref *EnvSourceRef
+	switch {
+	case source.SecretRef != nil && source.ConfigMapRef != nil:
+		return fmt.Errorf("only one of 'secretRef' and 'configMapRef' can be set in each entry")
+	case source.SecretRef != nil:
+		ref = source.SecretRef
+	case source.ConfigMapRef != nil:
+		ref = source.ConfigMapRef
+	default:
+		return fmt.Errorf("one of 'secretRef' and 'configMapRef' must be set in each entry")
+	}
+	if ref.Name == "" {
+		return fmt.Errorf("'name' is required")
+	}
+	return nil
+}
+
+func validateAutoscaling(a *AutoscalingInfo) error {
+	if a.Max < 1 {
+		return fmt.Errorf("'max' must be greater than 0")
+	}
+	if a.Min < 0 {
+		return fmt.Errorf("'min' cannot be negative")
+	}
+	if a.Min > a.Max {
+		return fmt.Errorf("'min' cannot be greater than 'max'")
+	}
+	if a.CPUPercent < 0 {
+		return fmt.Errorf("'cpu_percent' cannot be negative")
+	}
+	for _, m := range a.Metrics {
+		if m.Name == "" {
+			return fmt.Errorf("metric 'name' cannot be empty")
+		}
+		hasValue := !m.Value.Value.IsZero()
+		hasAverageValue := !m.AverageValue.Value.IsZero()
+		switch m.Type {
+		case PodsMetricType:
+			if hasValue || !hasAverageValue {
+				return fmt.Errorf("metric '%s' of type '%s' must define 'average_value'", m.Name, m.Type)
+			}
+		case ExternalMetricType:
+			if hasValue == hasAverageValue {
+				return fmt.Errorf("metric '%s' of type '%s' must define either 'value' or 'average_value'", m.Name, m.Type)
+			}
+		default:
+			return fmt.Errorf("metric '%s' has an invalid type '%s': supported types are '%s' and '%s'", m.Name, m.Type, PodsMetricType, ExternalMetricType)
+		}
+	}
+	if a.Behavior != nil {
+		if err := validateAutoscalingRules(a.Behavior.ScaleUp); err != nil {
+			return fmt.Errorf("invalid 'scale_up': %s", err)
+		}
+		if err := validateAutoscalingRules(a.Behavior.ScaleDown); err != nil {
+			return fmt.Errorf("invalid 'scale_down': %s", err)
+		}
+	}
+	return nil
+}
+
+//validateStorageAccessMode checks the access mode of the volume claims of a service.
+//Every replica gets its own claim: 'ReadWriteMany' is needed to mount them from other pods, like shared NFS volumes
+func validateStorageAccessMode(svc *Service) error {
+	if err := validateAccessMode(svc.Resources.Requests.Storage.AccessMode); err != nil {
+		return err
+	}
+	if svc.Resources.Requests.Storage.AccessMode == apiv1.ReadOnlyMany && svc.VolumePopulator != nil {
+		return fmt.Errorf("'%s' volumes can't be seeded by 'volume_populator', use '%s' to share a volume written by the service", apiv1.ReadOnlyMany, apiv1.ReadWriteMany)
+	}
+	return nil
+}
+
+func validateAccessMode(mode apiv1.PersistentVolumeAccessMode) error {
+	switch mode {
+	case "", apiv1.ReadWriteOnce, apiv1.ReadWriteMany, apiv1.ReadOnlyMany:
+		return nil
+	}
+	return fmt.Errorf("'%s' is not supported: supported values are '%s', '%s' and '%s'. Use '%s' for volumes shared by several replicas or services, like NFS volumes", mode, apiv1.ReadWriteOnce, apiv1.ReadWriteMany, apiv1.ReadOnlyMany, apiv1.ReadWriteMany)
+}
+
+func validateLoadBalancer(svc *Service) error {
+	switch svc.LoadBalancer {
+	case "", InternalLoadBalancer, ExternalLoadBalancer:
+	default:
+		return fmt.Errorf("'%s' is not supported: supported values are '%s' and '%s'", svc.LoadBalancer, InternalLoadBalancer, ExternalLoadBalancer)
+	}
+	if (svc.LoadBalancer != "" || svc.NLB) && !bool(svc.Public) {
+		return fmt.Errorf("'load_balancer' and 'nlb' are only supported by public services")
+	}
+	return nil
+}
+
+func validateServiceType(svc *Service) error {
+	switch svc.ServiceType {
+	case "", apiv1.ServiceTypeClusterIP, apiv1.ServiceTypeNodePort, apiv1.ServiceTypeLoadBalancer:
+	default:
+		return fmt.Errorf("'%s' is not supported: supported values are '%s', '%s' and '%s'", svc.ServiceType, apiv1.ServiceTypeClusterIP, apiv1.ServiceTypeNodePort, apiv1.ServiceTypeLoadBalancer)
+	}
+	if svc.ServiceType != "" && len(svc.GetPorts()) == 0 {
+		return fmt.Errorf("'service_type' requires at least one port in 'ports' or 'expose'")
+	}
+	if svc.Public && svc.ServiceType == apiv1.ServiceTypeClusterIP {
+		return fmt.Errorf("'public' services can't be of type '%s'", apiv1.ServiceTypeClusterIP)
+	}
+	if (svc.LoadBalancer != "" || svc.NLB) && svc.ServiceType != "" && svc.ServiceType != apiv1.ServiceTypeLoadBalancer {
+		return fmt.Errorf("'load_balancer' and 'nlb' are only supported by services of type '%s'", apiv1.ServiceTypeLoadBalancer)
+	}
+	for _, p := range svc.Ports {
+		if p.NodePort == 0 {
+			continue
+		}
+		if p.NodePort < minNodePort || p.NodePort > maxNodePort {
+			return fmt.Errorf("node port '%d' of port '%s' must be a number between %d and %d", p.NodePort, p.String(), minNodePort, maxNodePort)
+		}
+		if svc.GetServiceType() == apiv1.ServiceTypeClusterIP {
+			return fmt.Errorf("node port '%d' of port '%s' requires 'service_type: %s'", p.NodePort, p.String(), apiv1.ServiceTypeNodePort)
+		}
+	}
+	return nil
+}
+
+func validateSessionAffinity(sa *SessionAffinity) error {
+	switch sa.Type {
+	case "", apiv1.ServiceAffinityNone, apiv1.ServiceAffinityClientIP:
+	default:
+		return fmt.Errorf("'%s' is not supported: supported values are '%s' and '%s'", sa.Type, apiv1.ServiceAffinityNone, apiv1.ServiceAffinityClientIP)
+	}
+	if sa.Timeout == nil {
+		return nil
+	}
+	if sa.GetType() != apiv1.ServiceAffinityClientIP {
+		return fmt.Errorf("'timeout' requires 'type: %s'", apiv1.ServiceAffinityClientIP)
+	}
+	if *sa.Timeout < minSessionAffinityTimeout || *sa.Timeout > maxSessionAffinityTimeout {
+		return fmt.Errorf("'timeout' must be a number of seconds between %d and %d", minSessionAffinityTimeout, maxSessionAffinityTimeout)
+	}
+	return nil
+}
+
+//GetType returns the session affinity type, 'None' if not set
+func (sa *SessionAffinity) GetType() apiv1.ServiceAffinity {
+	if sa == nil || sa.Type == "" {
+		return apiv1.ServiceAffinityNone
+	}
+	return sa.Type
+}
+
+//GetServiceType returns the type of the kubernetes service of a service: its 'service_type' if set,
+//'LoadBalancer' for public services publishing ports and 'ClusterIP' otherwise
+func (svc *Service) GetServiceType() apiv1.ServiceType {
+	if svc.ServiceType != "" {
+		return svc.ServiceType
+	}
+	if svc.IsPublished() {
+		return apiv1.ServiceTypeLoadBalancer
+	}
+	return apiv1.ServiceTypeClusterIP
+}
+
+//HasLoadBalancerOptions returns true if the load balancer of a public service depends on the cloud provider of the cluster
+func (svc *Service) HasLoadBalancerOptions() bool {
+	return bool(svc.Public) && (svc.LoadBalancer != "" || svc.NLB)
+}
+
+func validatePDB(pdb *PDBInfo) error {
+	if (pdb.MinAvailable == "") == (pdb.MaxUnavailable == "") {
+		return fmt.Errorf("either 'min_available' or 'max_unavailable' must be defined")
+	}
+	if pdb.MinAvailable != "" {
+		if err := validatePDBValue(pdb.MinAvailable); err != nil {
+			return fmt.Errorf("invalid 'min_available': %s", err)
+		}
+	}
+	if pdb.MaxUnavailable != "" {
+		if err := validatePDBValue(pdb.MaxUnavailable); err != nil {
+			return fmt.Errorf("invalid 'max_unavailable': %s", err)
+		}
+	}
+	return nil
+}
+
+func validatePDBValue(value string) error {
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || percent < 0 || percent > 100 {
+			return fmt.Errorf("'%s' must be a percentage between 0%% and 100%%", value)
+		}
+		return nil
+	}
+	pods, err := strconv.Atoi(value)
+	if err != nil || pods < 0 {
+		return fmt.Errorf("'%s' must be a number of pods or a percentage", value)
+	}
+	return nil
+}
+
+func validateAutoscalingRules(r *AutoscalingRules) error {
+	if r == nil {
+		return nil
+	}
+	if r.StabilizationWindow != nil && (*r.StabilizationWindow < 0 || *r.StabilizationWindow > 3600) {
+		return fmt.Errorf("'stabilization_window' must be between 0 and 3600 seconds")
+	}
+	switch r.SelectPolicy {
+	case "", "max", "min", "disabled":
+	default:
+		return fmt.Errorf("'select_policy' must be 'max', 'min' or 'disabled'")
+	}
+	for _, p := range r.Policies {
+		if p.Type != PodsPolicyType && p.Type != PercentPolicyType {
+			return fmt.Errorf("policy has an invalid type '%s': supported types are '%s' and '%s'", p.Type, PodsPolicyType, PercentPolicyType)
+		}
+		if p.Value < 1 {
+			return fmt.Errorf("policy 'value' must be greater than 0")
+		}
+		if p.Period < 1 || p.Period > 1800 {
+			return fmt.Errorf("policy 'period' must be between 1 and 1800 seconds")
+		}
+	}
+	return nil
+}
+
+//ParseVolume returns the mount path, the optional size and the access mode of a volume with the format 'PATH[:SIZE][:ro|rw]'
+func ParseVolume(volume string) (StackVolume, error) {
+	parts := strings.Split(volume, ":")
+	result := StackVolume{MountPath: parts[0]}
+	if !strings.HasPrefix(result.MountPath, "/") {
+		return StackVolume{}, fmt.Errorf("must be an absolute path or a named volume defined in 'volumes'")
+	}
+	parts = parts[1:]
+	if len(parts) > 0 {
+		if readOnly, ok := parseVolumeMode(parts[len(parts)-1]); ok {
+			result.ReadOnly = readOnly
+			parts = parts[:len(parts)-1]
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return result, nil
+	case 1:
+		size, err := resource.ParseQuantity(parts[0])
+		if err != nil {
+			return StackVolume{}, fmt.Errorf("volume bind mounts are not supported")
+		}
+		result.Size = Quantity{Value: size}
+		return result, nil
+	}
+	return StackVolume{}, fmt.Errorf("the format must be 'PATH[:SIZE][:ro|rw]'")
+}
+
+//String returns the volume with the format 'PATH[:SIZE][:ro]'
+func (v StackVolume) String() string {
+	result := v.MountPath
+	if !v.Size.Value.IsZero() {
+		result = fmt.Sprintf("%s:%s", result, v.Size.Value.String())
+	}
+	if v.ReadOnly {
+		result = fmt.Sprintf("%s:%s", result, readOnlyVolumeMode)
+	}
+	return result
+}
+
+//parseVolumeMode returns if a volume mode is read-only, and false if it is not a volume mode
+func parseVolumeMode(mode string) (bool, bool) {
+	switch mode {
+	case readOnlyVolumeMode:
+		return true, true
+	case readWriteVolumeMode:
+		return false, true
+	}
+	return false, false
+}
+
+func validateOktetoOptions(o *OktetoOptions) error {
+	if o.Domain != "" {
+		if errs := validation.IsDNS1123Subdomain(o.Domain); len(errs) > 0 {
+			return fmt.Errorf("Invalid 'x-okteto.domain' '%s': %s", o.Domain, strings.Join(errs, ", "))
+		}
+	}
+	if o.TLSSecret != "" {
+		if errs := validation.IsDNS1123Subdomain(o.TLSSecret); len(errs) > 0 {
+			return fmt.Errorf("Invalid 'x-okteto.tlsSecret' '%s': %s", o.TLSSecret, strings.Join(errs, ", "))
+		}
+	}
+	if o.TLSIssuer != "" {
+		if errs := validation.IsDNS1123Subdomain(o.TLSIssuer); len(errs) > 0 {
+			return fmt.Errorf("Invalid 'x-okteto.tlsIssuer' '%s': %s", o.TLSIssuer, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+//GetEndpointHost returns the host of an endpoint, '<ENDPOINT>-<NAMESPACE>.<DOMAIN>', or an empty string if 'x-okteto.domain' is not set
+func (s *Stack) GetEndpointHost(endpointName string) string {
+	if s.Okteto.Domain == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s.%s", endpointName, s.Namespace, s.Okteto.Domain)
+}
+
+//GetEndpointTLSSecret returns the secret with the certificate of an endpoint, or an empty string if the endpoint is http only.
+//'x-okteto.tlsSecret' is shared by all the endpoints, while the certificates requested to 'x-okteto.tlsIssuer' are stored in '<ENDPOINT>-tls'
+func (s *Stack) GetEndpointTLSSecret(endpointName string) string {
+	if s.Okteto.TLSSecret != "" {
+		return s.Okteto.TLSSecret
+	}
+	if s.Okteto.TLSIssuer != "" {
+		return fmt.Sprintf("%s-tls", endpointName)
+	}
+	return ""
+}
+
+func validateGPU(gpu *GPUInfo) error {
+	if gpu.Count < 0 {
+		return fmt.Errorf("'count' must be a positive number")
+	}
+	if gpu.Profile != "" && gpu.Profile != SharedGPUProfile && !migProfileRegex.MatchString(gpu.Profile) {
+		return fmt.Errorf("'%s' is not a valid profile: use a MIG profile like '1g.5gb' or '%s'", gpu.Profile, SharedGPUProfile)
+	}
+	return nil
+}
+
+//GetCount returns the number of gpus requested, 1 if not set
+func (gpu *GPUInfo) GetCount() int64 {
+	if gpu.Count == 0 {
+		return 1
+	}
+	return gpu.Count
+}
+
+//GetResourceName returns the extended resource requested for the gpus of the profile
+func (gpu *GPUInfo) GetResourceName() apiv1.ResourceName {
+	switch gpu.Profile {
+	case "":
+		return GPUResourceName
+	case SharedGPUProfile:
+		return SharedGPUResourceName
+	default:
+		return apiv1.ResourceName(fmt.Sprintf("%s%s", MIGResourcePrefix, gpu.Profile))
+	}
+}
+
+//GetNodeSelector returns the node labels set by the gpu feature discovery on the nodes providing the gpus of the profile
+func (gpu *GPUInfo) GetNodeSelector() map[string]string {
+	switch gpu.Profile {
+	case "":
+		return nil
+	case SharedGPUProfile:
+		return map[string]string{GPUSharingStrategyLabel: GPUTimeSlicingStrategy}
+	default:
+		return map[string]string{MIGStrategyLabel: MIGMixedStrategy}
+	}
+}
+
+//ParseDevice returns the host and the container paths of a device with the format 'HOST[:CONTAINER[:PERMISSIONS]]'.
+//Kubernetes has no device permissions, so they are ignored
+func ParseDevice(device string) (string, string, error) {
+	parts := strings.SplitN(device, ":", 3)
+	host := parts[0]
+	container := host
+	if len(parts) > 1 {
+		container = parts[1]
+	}
+	if !strings.HasPrefix(host, "/dev/") || !strings.HasPrefix(container, "/") {
+		return "", "", fmt.Errorf("the host path must be in '/dev' and the container path must be absolute")
+	}
+	return host, container, nil
+}
+
+//ParseUser returns the user and the optional group of a user with the format 'UID[:GID]', or nil if the user is empty
+func ParseUser(user string) (*int64, *int64, error) {
+	if user == "" {
+		return nil, nil, nil
+	}
+	parts := strings.SplitN(user, ":", 2)
+	uid, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || uid < 0 {
+		return nil, nil, fmt.Errorf("kubernetes requires numeric ids, the format must be 'UID[:GID]'")
+	}
+	if len(parts) == 1 {
+		return &uid, nil, nil
+	}
+	gid, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || gid < 0 {
+		return nil, nil, fmt.Errorf("kubernetes requires numeric ids, the format must be 'UID[:GID]'")
+	}
+	return &uid, &gid, nil
+}
+
+//ParseTmpfs returns the mount path and the optional size of a tmpfs entry with the format 'PATH[:size=SIZE]'
+func ParseTmpfs(tmpfs string) (string, Quantity, error) {
+	parts := strings.SplitN(tmpfs, ":", 2)
+	path := parts[0]
+	if !strings.HasPrefix(path, "/") {
+		return "", Quantity{}, fmt.Errorf("must be an absolute path")
+	}
+	if len(parts) == 1 {
+		return path, Quantity{}, nil
+	}
+	if !strings.HasPrefix(parts[1], "size=") {
+		return "", Quantity{}, fmt.Errorf("the only supported option is 'size'")
+	}
+	size, err := resource.ParseQuantity(strings.TrimPrefix(parts[1], "size="))
+	if err != nil {
+		return "", Quantity{}, fmt.Errorf("invalid size: %s", err)
+	}
+	return path, Quantity{Value: size}, nil
+}
+
+//GetServiceByPort returns the sorted names of the services that publish or expose a port
+func (s *Stack) GetServiceByPort(port int32) []string {
+	result := []string{}
+	for name, svc := range s.Services {
+		if IsPortInService(port, svc.GetPorts()) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+//EndpointRoute represents a path of an endpoint routing to a service
+type EndpointRoute struct {
+	Name string
+	Path string
+	Port int32
+}
+
+//ResolveEndpointsForService returns the paths of the endpoints routing to a service, sorted by endpoint name and path
+func (s *Stack) ResolveEndpointsForService(name string) []EndpointRoute {
+	result := []EndpointRoute{}
+	for endpointName, endpoints := range s.Endpoints {
+		for _, e := range endpoints {
+			if e.Service == name {
+				result = append(result, EndpointRoute{Name: endpointName, Path: e.Path, Port: e.Port})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Port < result[j].Port
+	})
+	return result
+}
+
+//OverrideCommand replaces the command and args of a service with an override of the form 'service=command'
+func (s *Stack) OverrideCommand(override string) error {
+	parts := strings.SplitN(override, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("Invalid command override '%s': must be of the form 'service=command'", override)
+	}
+	svc, ok := s.Services[parts[0]]
+	if !ok {
+		return fmt.Errorf("Invalid command override '%s': service '%s' is not defined in stack '%s'", override, parts[0], s.Name)
+	}
+	command, err := ParseCommand(parts[1])
+	if err != nil {
+		return fmt.Errorf("Invalid command override '%s': %s", override, err)
+	}
+	if err := validateExecCommand(command); err != nil {
+		return fmt.Errorf("Invalid command override '%s': %s", override, err)
+	}
+	svc.Command.Values = command
+	svc.Args.Values = nil
+	//the platform commands would replace the override when the stack is deployed
+	for platform, platformOverride := range svc.Platforms {
+		platformOverride.Command.Values = nil
+		platformOverride.Args.Values = nil
+		svc.Platforms[platform] = platformOverride
+	}
+	s.Services[parts[0]] = svc
+	s.Overrides.Commands = append(s.Overrides.Commands, override)
+	return nil
+}
+
+//OverrideImage sets the image of a service from an override of the form 'service=image' (e.g. 'web=okteto/web@sha256:...')
+func (s *Stack) OverrideImage(override string) error {
+	parts := strings.SplitN(override, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("Invalid image override '%s': must be of the form 'service=image'", override)
+	}
+	return s.OverrideImages(map[string]string{parts[0]: parts[1]})
+}
+
+//OverrideImages sets the images of the services of a map of service names to images, so a pipeline can pin the images deployed without editing the manifest.
+//The services are deployed with those images as they are, so they are never built
+func (s *Stack) OverrideImages(images map[string]string) error {
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		image := images[name]
+		svc, ok := s.Services[name]
+		if !ok {
+			return fmt.Errorf("Invalid image override for service '%s': it is not defined in stack '%s'", name, s.Name)
+		}
+		if image == "" {
+			return fmt.Errorf("Invalid image override for service '%s': the image is empty", name)
+		}
+		if s.Okteto.RequireImageTags && !hasExplicitImageTag(image) {
+			return fmt.Errorf("Invalid image override for service '%s': 'requireImageTags' is enabled and image '%s' must have a tag other than 'latest' or a digest", name, image)
+		}
+		svc.Image = image
+		svc.Build = nil
+		s.Services[name] = svc
+		if s.Overrides.Images == nil {
+			s.Overrides.Images = map[string]string{}
+		}
+		s.Overrides.Images[name] = image
+	}
+	return nil
+}
+
+//ReadImageOverrides returns the map of service names to images of a yaml file
+func ReadImageOverrides(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]string{}
+	if err := yaml.UnmarshalStrict(b, &result); err != nil {
+		return nil, fmt.Errorf("Invalid images file '%s': %s", path, err)
+	}
+	return result, nil
+}
+
+//ParseCommand splits a command line into its arguments, respecting single quotes, double quotes and backslash escapes
+func ParseCommand(command string) ([]string, error) {
+	result := []string{}
+	var sb strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				result = append(result, sb.String())
+				sb.Reset()
+				inArg = false
+			}
+		default:
+			sb.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape")
+	}
+	if inArg {
+		result = append(result, sb.String())
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("command cannot be empty")
+	}
+	return result, nil
+}
+
+//Filter returns a copy of the stack with the given services and the endpoints that route to them.
+//The stack must be validated before, so cross-service references are checked against every service
+func (s *Stack) Filter(services []string) (*Stack, error) {
+	result := *s
+	result.Services = map[string]Service{}
+	for _, name := range services {
+		svc, ok := s.Services[name]
+		if !ok {
+			return nil, fmt.Errorf("Invalid service '%s': it is not defined in stack '%s'", name, s.Name)
+		}
+		result.Services[name] = svc
+	}
+	result.Endpoints = map[string][]Endpoint{}
+	for name, endpoints := range s.Endpoints {
+		for _, endpoint := range endpoints {
+			if _, ok := result.Services[endpoint.Service]; ok {
+				result.Endpoints[name] = endpoints
+				break
+			}
+		}
+	}
+	return &result, nil
+}
+
+//GetVolumeNames returns the sorted names of the named volumes mounted by the services of the stack
+func (s *Stack) GetVolumeNames() []string {
+	result := []string{}
+	for name := range s.Volumes {
+		for _, svc := range s.Services {
+			if svc.MountsVolume(name) {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+//MountsVolume returns true if the service mounts the named volume
+func (svc *Service) MountsVolume(name string) bool {
+	for _, v := range svc.NamedVolumes {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+//ServicesUsingImage returns the sorted names of the services using an image, built or external.
+//An image with a tag or digest matches exactly, otherwise it matches any tag of the repository
+func (s *Stack) ServicesUsingImage(image string) []string {
+	result := []string{}
+	repository, hasTag := splitImageRepository(image)
+	for name, svc := range s.Services {
+		if svc.Image == "" {
+			continue
+		}
+		if hasTag {
+			if svc.Image == image {
+				result = append(result, name)
+			}
+			continue
+		}
+		if svcRepository, _ := splitImageRepository(svc.Image); svcRepository == repository {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+//splitImageRepository returns the repository of an image and if it has a tag or digest
+func splitImageRepository(image string) (string, bool) {
+	if i := strings.IndexRune(image, '@'); i != -1 {
+		return image[:i], true
+	}
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i+1:], "/") {
+		return image, false
+	}
+	return image[:i], true
+}
+
+func hasExplicitImageTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	_, hasTag := splitImageRepository(image)
+	return hasTag && !strings.HasSuffix(image, ":latest")
+}
+
+//getConflictingCapability returns the first capability both added and dropped, or an empty string if there are none
+func getConflictingCapability(capAdd, capDrop []apiv1.Capability) apiv1.Capability {
+	dropped := map[apiv1.Capability]bool{}
+	for _, c := range capDrop {
+		dropped[c] = true
+	}
+	for _, c := range capAdd {
+		if dropped[c] {
+			return c
+		}
+	}
+	return ""
+}
+
+func validatePorts(ports []Port, expose []Port) error {
+	published := map[Port]bool{}
+	for _, p := range ports {
+		if err := validatePortProtocol(p); err != nil {
+			return err
+		}
+		key := Port{Port: p.Port, Protocol: p.GetProtocol()}
+		if published[key] {
+			return fmt.Errorf("port '%s' is published more than once", key.String())
+		}
+		published[key] = true
+	}
+	exposed := map[Port]bool{}
+	for _, p := range expose {
+		if err := validatePortProtocol(p); err != nil {
+			return err
+		}
+		if p.Port != p.ContainerPort || p.NodePort != 0 {
+			return fmt.Errorf("port '%d' in 'expose' must follow the syntax 'PORT[/PROTOCOL]'", p.Port)
+		}
+		key := Port{Port: p.Port, Protocol: p.GetProtocol()}
+		if published[key] {
+			return fmt.Errorf("port '%s' can't be both in 'ports' and 'expose'", key.String())
+		}
+		if exposed[key] {
+			return fmt.Errorf("port '%s' is exposed more than once", key.String())
+		}
+		exposed[key] = true
+	}
+	return nil
+}
+
+func validatePortProtocol(p Port) error {
+	switch p.GetProtocol() {
+	case apiv1.ProtocolTCP, apiv1.ProtocolUDP, apiv1.ProtocolSCTP:
+		return nil
+	}
+	return fmt.Errorf("port '%d' has an unsupported protocol '%s': supported protocols are 'tcp', 'udp' and 'sctp'", p.Port, strings.ToLower(string(p.Protocol)))
+}
+
+//GetServiceEnv returns the resolved environment of a service, as deployed by okteto:
+//its 'env_file' files are loaded in order, each one overriding the variables of the previous ones, inline variables override all of them,
+//duplicated names keep their last value and the result is sorted by name
+func (s *Stack) GetServiceEnv(name string) ([]EnvVar, error) {
+	svc, ok := s.Services[name]
+	if !ok {
+		return nil, fmt.Errorf("Invalid service '%s': it is not defined in stack '%s'", name, s.Name)
+	}
+
+	result := []EnvVar{}
+	index := map[string]int{}
+	setEnv := func(e EnvVar) {
+		if i, ok := index[e.Name]; ok {
+			result[i] = e
+			return
+		}
+		index[e.Name] = len(result)
+		result = append(result, e)
+	}
+
+	for _, envFilepath := range svc.EnvFiles {
+		envMap, err := readEnvFile(envFilepath)
+		if err != nil {
+			return nil, err
+		}
+		for envName, value := range envMap {
+			setEnv(EnvVar{Name: envName, Value: value})
+		}
+	}
+	for _, e := range svc.Environment {
+		setEnv(e)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return strings.Compare(result[i].Name, result[j].Name) < 0
+	})
+	return result, nil
+}
+
+func readEnvFile(filename string) (map[string]string, error) {
+	var err error
+	filename, err = ExpandStackEnv(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	//escape the image digest variable so it is kept until the image of the service is built
+	imageDigest := "${" + ImageDigestEnv + "}"
+	content := strings.ReplaceAll(string(b), imageDigest, `\`+imageDigest)
+	envMap, err := gotenv.StrictParse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing env_file %s: %s", filename, err.Error())
+	}
+	return envMap, nil
+}
+
+//IsCronJob returns true if the service runs periodically as a kubernetes cronjob
+func (svc *Service) IsCronJob() bool {
+	return svc.Schedule != ""
+}
+
+//IsJob returns true if the service runs to completion as a kubernetes job: its 'kind' is 'job',
+//or it is never restarted and has no ports or volumes, like migrations or seeders
+func (svc *Service) IsJob() bool {
+	if svc.IsCronJob() {
+		return false
+	}
+	if svc.Kind == JobServiceKind {
+		return true
+	}
+	if svc.IsGlobal() {
+		return false
+	}
+	return svc.Restart == RestartNo && len(svc.GetPorts()) == 0 && len(svc.Volumes) == 0
+}
+
+//IsGlobal returns true if the service runs one pod in every node as a kubernetes daemonset, like log collectors or node agents
+func (svc *Service) IsGlobal() bool {
+	return svc.Deploy != nil && svc.Deploy.Mode == GlobalDeployMode
+}
+
+//IsPublished returns true if the service is public and publishes ports in 'ports'.
+//Ports in 'expose' are only reachable by other services, so a service only exposing ports is never published
+func (svc *Service) IsPublished() bool {
+	return bool(svc.Public) && len(svc.Ports) > 0
+}
+
+//GetPorts returns the ports published by the service followed by the ports only exposed to other services
+func (svc *Service) GetPorts() []Port {
+	result := append([]Port{}, svc.Ports...)
+	for _, p := range svc.Expose {
+		result = append(result, Port{Port: p.Port, ContainerPort: p.Port, Protocol: p.Protocol})
+	}
+	return result
+}
+
+//GetProtocol returns the protocol of the port, TCP if not set
+func (p *Port) GetProtocol() apiv1.Protocol {
+	if p.Protocol == "" {
+		return apiv1.ProtocolTCP
+	}
+	return p.Protocol
+}
+
+//String returns the published port followed by its protocol when it isn't TCP, like '53/udp'
+func (p *Port) String() string {
+	if p.GetProtocol() == apiv1.ProtocolTCP {
+		return strconv.Itoa(int(p.Port))
+	}
+	return fmt.Sprintf("%d/%s", p.Port, strings.ToLower(string(p.Protocol)))
+}
+
+//ServiceEndpoint represents the ingress path of a port of an okteto stack service.
+//Each port gets its own ingress '<SERVICE>-<PORT>', unless several ports share the same name
+type ServiceEndpoint struct {
+	Name     string `yaml:"name,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+	PathType string `yaml:"path_type,omitempty"`
+	Port     int32  `yaml:"port,omitempty"`
+}
+
+//GetPathType returns the path type of the endpoint, 'Prefix' if not set
+func (e *Endpoint) GetPathType() string {
+	if e.PathType == "" {
+		return PrefixPathType
+	}
+	return e.PathType
+}
+
+//IsPortInService returns true if the port is published by the service
+func IsPortInService(port int32, portList []Port) bool {
+	for _, p := range portList {
+		if p.Port == port {
+			return true
+		}
+	}
+	return false
+}
+
+func validateStackName(name string) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
 	}
 	if ValidKubeNameRegex.MatchString(name) {
 		return fmt.Errorf(errBadStackName)
@@ -270,6 +2349,234 @@ func (s *Stack) GetConfigMapName() string {
 	return fmt.Sprintf("okteto-%s", s.Name)
 }
 
+//Equal returns true if both stacks are semantically equal, ignoring the manifest bytes and volatile annotations
+func (s *Stack) Equal(other *Stack) bool {
+	return len(s.Diff(other)) == 0
+}
+
+//Diff returns the list of fields that are semantically different between two stacks
+func (s *Stack) Diff(other *Stack) []string {
+	result := []string{}
+	if s.Name != other.Name {
+		result = append(result, "name")
+	}
+	if s.Namespace != other.Namespace {
+		result = append(result, "namespace")
+	}
+
+	if !equalStringMaps(s.Labels, other.Labels) {
+		result = append(result, "labels")
+	}
+	if !equalStringMaps(s.Annotations, other.Annotations) {
+		result = append(result, "annotations")
+	}
+
+	a := reflect.ValueOf(s.Okteto)
+	b := reflect.ValueOf(other.Okteto)
+	for i := 0; i < a.NumField(); i++ {
+		if a.Field(i).Interface() != b.Field(i).Interface() {
+			name := strings.Split(a.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			result = append(result, fmt.Sprintf("x-okteto.%s", name))
+		}
+	}
+
+	for name, svc := range s.Services {
+		otherSvc, ok := other.Services[name]
+		if !ok {
+			result = append(result, fmt.Sprintf("services.%s", name))
+			continue
+		}
+		for _, field := range svc.diff(&otherSvc) {
+			result = append(result, fmt.Sprintf("services.%s.%s", name, field))
+		}
+	}
+	for name := range other.Services {
+		if _, ok := s.Services[name]; !ok {
+			result = append(result, fmt.Sprintf("services.%s", name))
+		}
+	}
+
+	for name, endpoints := range s.Endpoints {
+		otherEndpoints, ok := other.Endpoints[name]
+		if !ok || !reflect.DeepEqual(endpoints, otherEndpoints) {
+			result = append(result, fmt.Sprintf("endpoints.%s", name))
+		}
+	}
+	for name := range other.Endpoints {
+		if _, ok := s.Endpoints[name]; !ok {
+			result = append(result, fmt.Sprintf("endpoints.%s", name))
+		}
+	}
+
+	for name, volume := range s.Volumes {
+		otherVolume, ok := other.Volumes[name]
+		if !ok || !reflect.DeepEqual(volume.normalize(), otherVolume.normalize()) {
+			result = append(result, fmt.Sprintf("volumes.%s", name))
+		}
+	}
+	for name := range other.Volumes {
+		if _, ok := s.Volumes[name]; !ok {
+			result = append(result, fmt.Sprintf("volumes.%s", name))
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+//equalStringMaps returns true if both maps have the same entries, considering nil and empty maps equal
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func (svc *Service) diff(other *Service) []string {
+	result := []string{}
+	a := reflect.ValueOf(svc.normalize())
+	b := reflect.ValueOf(other.normalize())
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			name = t.Field(i).Name
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+//normalize returns a copy of the service suitable for semantic comparison
+func (svc *Service) normalize() Service {
+	result := *svc
+
+	result.Labels = nil
+	for k, v := range svc.Labels {
+		if result.Labels == nil {
+			result.Labels = map[string]string{}
+		}
+		result.Labels[k] = v
+	}
+
+	result.Annotations = nil
+	for k, v := range svc.Annotations {
+		if k == labels.LastBuiltAnnotation || k == labels.StackRestartedAtAnnotation {
+			continue
+		}
+		if result.Annotations == nil {
+			result.Annotations = map[string]string{}
+		}
+		result.Annotations[k] = v
+	}
+
+	result.Environment = nil
+	if len(svc.Environment) > 0 {
+		result.Environment = append([]EnvVar{}, svc.Environment...)
+		sort.SliceStable(result.Environment, func(i, j int) bool {
+			return result.Environment[i].Name < result.Environment[j].Name
+		})
+	}
+
+	if len(result.EnvFiles) == 0 {
+		result.EnvFiles = nil
+	}
+	if len(result.EnvFrom) == 0 {
+		result.EnvFrom = nil
+	}
+	if len(result.DependsOn) == 0 {
+		result.DependsOn = nil
+	}
+	if len(result.CapAdd) == 0 {
+		result.CapAdd = nil
+	}
+	if len(result.CapDrop) == 0 {
+		result.CapDrop = nil
+	}
+	if len(result.Ports) == 0 {
+		result.Ports = nil
+	}
+	if len(result.Expose) == 0 {
+		result.Expose = nil
+	}
+	if len(result.Volumes) == 0 {
+		result.Volumes = nil
+	}
+	if len(result.NamedVolumes) == 0 {
+		result.NamedVolumes = nil
+	}
+	if len(result.Tmpfs) == 0 {
+		result.Tmpfs = nil
+	}
+	if len(result.Entrypoint.Values) == 0 {
+		result.Entrypoint.Values = nil
+	}
+	if len(result.Command.Values) == 0 {
+		result.Command.Values = nil
+	}
+	if len(result.Args.Values) == 0 {
+		result.Args.Values = nil
+	}
+
+	result.Resources.Limits.CPU = normalizeQuantity(result.Resources.Limits.CPU)
+	result.Resources.Limits.Memory = normalizeQuantity(result.Resources.Limits.Memory)
+	result.Resources.Limits.EphemeralStorage = normalizeQuantity(result.Resources.Limits.EphemeralStorage)
+	result.Resources.Limits.Storage.Size = normalizeQuantity(result.Resources.Limits.Storage.Size)
+	result.Resources.Requests.CPU = normalizeQuantity(result.Resources.Requests.CPU)
+	result.Resources.Requests.Memory = normalizeQuantity(result.Resources.Requests.Memory)
+	result.Resources.Requests.EphemeralStorage = normalizeQuantity(result.Resources.Requests.EphemeralStorage)
+	result.Resources.Requests.Storage.Size = normalizeQuantity(result.Resources.Requests.Storage.Size)
+
+	if svc.Deploy != nil {
+		deploy := *svc.Deploy
+		if deploy.Resources != nil {
+			resources := *deploy.Resources
+			resources.Limits = resources.Limits.normalize()
+			resources.Reservations = resources.Reservations.normalize()
+			deploy.Resources = &resources
+		}
+		if deploy.Autoscaling != nil {
+			autoscaling := *deploy.Autoscaling
+			autoscaling.Metrics = nil
+			for _, m := range deploy.Autoscaling.Metrics {
+				m.Value = normalizeQuantity(m.Value)
+				m.AverageValue = normalizeQuantity(m.AverageValue)
+				autoscaling.Metrics = append(autoscaling.Metrics, m)
+			}
+			deploy.Autoscaling = &autoscaling
+		}
+		result.Deploy = &deploy
+	}
+	return result
+}
+
+//normalize returns a copy of the compose resources suitable for semantic comparison
+func (r DeployResourceList) normalize() DeployResourceList {
+	return DeployResourceList{CPUs: normalizeQuantity(r.CPUs), Memory: normalizeQuantity(r.Memory)}
+}
+
+//normalize returns a copy of the volume suitable for semantic comparison
+func (v *VolumeSpec) normalize() VolumeSpec {
+	result := *v
+	result.Size = normalizeQuantity(v.Size)
+	if len(result.Labels) == 0 {
+		result.Labels = nil
+	}
+	persist := v.IsPersistent()
+	result.Persist = &persist
+	return result
+}
+
+func normalizeQuantity(q Quantity) Quantity {
+	if q.Value.IsZero() {
+		return Quantity{}
+	}
+	return Quantity{Value: resource.MustParse(q.Value.String())}
+}
+
 //SetLastBuiltAnnotation sets the dev timestamp
 func (svc *Service) SetLastBuiltAnnotation() {
 	if svc.Annotations == nil {
@@ -277,3 +2584,11 @@ func (svc *Service) SetLastBuiltAnnotation() {
 	}
 	svc.Annotations[labels.LastBuiltAnnotation] = time.Now().UTC().Format(labels.TimeFormat)
 }
+
+//SetRestartedAtAnnotation sets the annotation that forces the service pods to be recreated
+func (svc *Service) SetRestartedAtAnnotation() {
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+	svc.Annotations[labels.StackRestartedAtAnnotation] = time.Now().UTC().Format(labels.TimeFormat)
+}
@@ -14,9 +14,21 @@
 package model
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	yaml "gopkg.in/yaml.v2"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
 )
 
 func Test_ReadStack(t *testing.T) {
@@ -27,12 +39,18 @@ services:
     image: okteto/vote:1
     build: vote
     command: python app.py
+    platforms:
+      linux/arm64:
+        command: qemu-x86_64 python app.py
     environment:
       - OPTION_A=Cats
       - OPTION_B=Dogs
     ports:
       - 80
     replicas: 2
+    deploy:
+      labels:
+        tier: frontend
     stop_grace_period: 5
     resources:
       cpu: 100m
@@ -79,6 +97,9 @@ services:
 	if s.Services["vote"].Command.Values[0] != "sh" || s.Services["vote"].Command.Values[1] != "-c" || s.Services["vote"].Command.Values[2] != "python app.py" {
 		t.Errorf("'vote.command' was not parsed: %+v", s)
 	}
+	if !reflect.DeepEqual(s.Services["vote"].Platforms["linux/arm64"].Command.Values, []string{"sh", "-c", "qemu-x86_64 python app.py"}) {
+		t.Errorf("'vote.platforms' was not parsed: %+v", s.Services["vote"].Platforms)
+	}
 	if s.Services["vote"].Replicas != 2 {
 		t.Errorf("'vote.replicas' was not parsed: %+v", s)
 	}
@@ -94,10 +115,16 @@ services:
 	if len(s.Services["vote"].Ports) != 1 {
 		t.Errorf("'vote.ports' was not parsed: %+v", s)
 	}
-	if s.Services["vote"].Ports[0] != 80 {
+	if s.Services["vote"].Ports[0].Port != 80 || s.Services["vote"].Ports[0].ContainerPort != 80 {
 		t.Errorf("'vote.ports[0]' was not parsed: %+v", s)
 	}
-	if s.Services["vote"].StopGracePeriod != 5 {
+	if s.Services["vote"].Deploy == nil || s.Services["vote"].Deploy.Labels["tier"] != "frontend" {
+		t.Errorf("'vote.deploy.labels' was not parsed: %+v", s.Services["vote"].Deploy)
+	}
+	if len(s.Services["vote"].Labels) != 0 {
+		t.Errorf("'vote.labels' should not include 'deploy.labels': %+v", s.Services["vote"].Labels)
+	}
+	if s.Services["vote"].StopGracePeriod == nil || *s.Services["vote"].StopGracePeriod != 5 {
 		t.Errorf("'vote.stop_grace_period' was not parsed: %+v", s)
 	}
 	cpu := s.Services["vote"].Resources.Limits.CPU.Value
@@ -153,6 +180,145 @@ services:
 	}
 }
 
+func TestStack_Normalize(t *testing.T) {
+	newStack := func() *Stack {
+		return &Stack{
+			Name: "name",
+			Services: map[string]Service{
+				"api": {
+					Public:     true,
+					Build:      &BuildInfo{Name: "api"},
+					Entrypoint: Entrypoint{Values: []string{"/entrypoint.sh"}},
+					Command:    Command{Values: []string{"serve"}},
+					Expose:     []Port{{Port: 8080, ContainerPort: 8080}},
+					Deploy:     &DeployInfo{Autoscaling: &AutoscalingInfo{Max: 3}},
+				},
+				"db": {
+					Image:  "postgres",
+					Ports:  []Port{{Port: 5432, ContainerPort: 5432}},
+					Expose: []Port{{Port: 9187, ContainerPort: 9187}},
+				},
+			},
+		}
+	}
+	s := newStack()
+	s.Normalize()
+
+	api := s.Services["api"]
+	if api.Replicas != 1 {
+		t.Errorf("wrong 'api.replicas': %d", api.Replicas)
+	}
+	if api.Build.Context != "api" || api.Build.Dockerfile != filepath.Join("api", "Dockerfile") || api.Build.Name != "" {
+		t.Errorf("wrong 'api.build': %+v", api.Build)
+	}
+	if !reflect.DeepEqual(api.Command.Values, []string{"/entrypoint.sh"}) || !reflect.DeepEqual(api.Args.Values, []string{"serve"}) {
+		t.Errorf("wrong 'api.command' and 'api.args': %v %v", api.Command.Values, api.Args.Values)
+	}
+	if api.Public {
+		t.Errorf("'api.public' should be false when only 'expose' is defined")
+	}
+	if len(api.Ports) != 0 {
+		t.Errorf("'api.expose' should not be merged into 'api.ports': %v", api.Ports)
+	}
+	if api.Deploy.Autoscaling.Min != 1 {
+		t.Errorf("wrong 'api.deploy.autoscaling.min': %d", api.Deploy.Autoscaling.Min)
+	}
+	db := s.Services["db"]
+	if !reflect.DeepEqual(db.GetPorts(), []Port{{Port: 5432, ContainerPort: 5432}, {Port: 9187, ContainerPort: 9187}}) {
+		t.Errorf("wrong 'db' ports: %v", db.GetPorts())
+	}
+
+	twice := newStack()
+	twice.Normalize()
+	twice.Normalize()
+	if !reflect.DeepEqual(s, twice) {
+		t.Errorf("Normalize() is not idempotent: %+v", twice.Services)
+	}
+}
+
+func Test_ReadStackOktetoOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest []byte
+		expected OktetoOptions
+		wantErr  bool
+	}{
+		{
+			name: "recognized",
+			manifest: []byte(`name: voting-app
+x-okteto:
+  skipRegistryCheck: true
+  autoIngressClass: nginx
+  requireImageTags: true
+  domain: example.com
+  tlsSecret: wildcard-tls
+  tlsIssuer: letsencrypt
+services:
+  vote:
+    image: okteto/vote:1`),
+			expected: OktetoOptions{SkipRegistryCheck: true, AutoIngressClass: "nginx", RequireImageTags: true, Domain: "example.com", TLSSecret: "wildcard-tls", TLSIssuer: "letsencrypt"},
+		},
+		{
+			name: "unrecognized",
+			manifest: []byte(`name: voting-app
+x-okteto:
+  autoIngressClass: nginx
+  unknownOption: value
+services:
+  vote:
+    image: okteto/vote:1`),
+			expected: OktetoOptions{AutoIngressClass: "nginx"},
+		},
+		{
+			name: "wrong-type",
+			manifest: []byte(`name: voting-app
+x-okteto:
+  skipRegistryCheck: sometimes
+services:
+  vote:
+    image: okteto/vote:1`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ReadStack(tt.manifest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadStack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && s.Okteto != tt.expected {
+				t.Errorf("wrong 'x-okteto' options: %+v", s.Okteto)
+			}
+		})
+	}
+}
+
+func TestStack_validateOktetoOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		okteto  OktetoOptions
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "tls", okteto: OktetoOptions{Domain: "example.com", TLSSecret: "wildcard-tls", TLSIssuer: "letsencrypt"}},
+		{name: "wrong-domain", okteto: OktetoOptions{Domain: "https://example.com"}, wantErr: true},
+		{name: "wrong-secret", okteto: OktetoOptions{TLSSecret: "Wildcard_TLS"}, wantErr: true},
+		{name: "wrong-issuer", okteto: OktetoOptions{TLSIssuer: "lets encrypt"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": {Image: "image"}},
+				Okteto:   tt.okteto,
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestStack_validate(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -224,63 +390,2910 @@ func TestStack_validate(t *testing.T) {
 			},
 		},
 		{
-			name: "endpoint-of-undefined-service",
+			name: "relative-tmpfs-path",
 			stack: &Stack{
 				Name: "name",
-				Endpoints: map[string][]Endpoint{
-					"endpoint1": {
-						{Service: "app"},
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Tmpfs: []string{"/run", "tmp"},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid-tmpfs-size",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Tmpfs: []string{"/run:size=big"},
 					},
 				},
+			},
+		},
+		{
+			name: "invalid-env-var-name",
+			stack: &Stack{
+				Name: "name",
 				Services: map[string]Service{
-					"name": {},
+					"name": {
+						Image:       "image",
+						Environment: []EnvVar{{Name: "1VAR", Value: "value"}},
+					},
 				},
 			},
 		},
 		{
-			name: "endpoint-of-unexported-port",
+			name: "env-var-name-with-spaces",
 			stack: &Stack{
 				Name: "name",
-				Endpoints: map[string][]Endpoint{
-					"endpoint1": {
-						{Service: "name",
-							Port: 80},
+				Services: map[string]Service{
+					"name": {
+						Image:       "image",
+						Environment: []EnvVar{{Name: "MY VAR", Value: "value"}},
 					},
 				},
+			},
+		},
+		{
+			name: "relative-mount-manifest",
+			stack: &Stack{
+				Name: "name",
 				Services: map[string]Service{
-					"name": {Ports: []int32{
-						8080,
-					}},
+					"name": {
+						Image:         "image",
+						MountManifest: "stack.yml",
+					},
 				},
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.stack.validate(); err == nil {
-				t.Errorf("Stack.validate() not failed for test '%s'", tt.name)
-			}
-		})
-	}
-}
-
-func Test_validateStackName(t *testing.T) {
-	tests := []struct {
-		name      string
-		stackName string
-		wantErr   bool
-	}{
-		{name: "empty", stackName: "", wantErr: true},
-		{name: "starts-with-dash", stackName: "-bad-name", wantErr: true},
-		{name: "ends-with-dash", stackName: "bad-name-", wantErr: true},
-		{name: "symbols", stackName: "1$good-2", wantErr: true},
-		{name: "alphanumeric", stackName: "good-2", wantErr: false},
-		{name: "good", stackName: "good-name", wantErr: false},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := validateStackName(tt.stackName); (err != nil) != tt.wantErr {
-				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+		{
+			name: "negative-stop-grace-period",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:           "image",
+						StopGracePeriod: pointer.Int64Ptr(-1),
+					},
+				},
+			},
+		},
+		{
+			name: "volume-populator-without-image",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:           "image",
+						Volumes:         []string{"/data"},
+						VolumePopulator: &VolumePopulator{Source: "/seed", Target: "/data"},
+					},
+				},
+			},
+		},
+		{
+			name: "volume-populator-relative-source",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:           "image",
+						Volumes:         []string{"/data"},
+						VolumePopulator: &VolumePopulator{Image: "seed", Source: "seed", Target: "/data"},
+					},
+				},
+			},
+		},
+		{
+			name: "volume-populator-unknown-target",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:           "image",
+						Volumes:         []string{"/data"},
+						VolumePopulator: &VolumePopulator{Image: "seed", Source: "/seed", Target: "/cache"},
+					},
+				},
+			},
+		},
+		{
+			name: "volume-bind-mount",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:   "image",
+						Volumes: []string{"/data:/src"},
+					},
+				},
+			},
+		},
+		{
+			name: "inline-volume-size-with-several-volumes",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:   "image",
+						Volumes: []string{"/data:10Gi", "/cache"},
+					},
+				},
+			},
+		},
+		{
+			name: "inline-volume-size-conflict",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:   "image",
+						Volumes: []string{"/data:10Gi"},
+						Resources: StackResources{
+							Requests: ServiceResources{
+								Storage: StorageResource{Size: Quantity{Value: resource.MustParse("5Gi")}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported-endpoint-mode",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Deploy: &DeployInfo{EndpointMode: "round-robin"},
+					},
+				},
+			},
+		},
+		{
+			name: "public-dnsrr-endpoint-mode",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Public: true,
+						Ports:  []Port{{Port: 80, ContainerPort: 80}},
+						Deploy: &DeployInfo{EndpointMode: DNSRREndpointMode},
+					},
+				},
+			},
+		},
+		{
+			name: "port-published-and-exposed",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Ports:  []Port{{Port: 8080, ContainerPort: 80}},
+						Expose: []Port{{Port: 8080, ContainerPort: 8080}},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicated-published-port",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Ports: []Port{{Port: 8080, ContainerPort: 80}, {Port: 8080, ContainerPort: 8080}},
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported-port-protocol",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Ports: []Port{{Port: 8080, ContainerPort: 80, Protocol: apiv1.Protocol("HTTP")}},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicated-udp-port",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Ports: []Port{{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP}, {Port: 53, ContainerPort: 5353, Protocol: apiv1.ProtocolUDP}},
+					},
+				},
+			},
+		},
+		{
+			name: "exposed-port-with-published-port",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Expose: []Port{{Port: 8080, ContainerPort: 80}},
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported-exposed-port-protocol",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Expose: []Port{{Port: 8080, ContainerPort: 8080, Protocol: apiv1.Protocol("HTTP")}},
+					},
+				},
+			},
+		},
+		{
+			name: "udp-port-published-and-exposed",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Ports:  []Port{{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP}},
+						Expose: []Port{{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP}},
+					},
+				},
+			},
+		},
+		{
+			name: "endpoint-to-exposed-port",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Public: true,
+						Ports:  []Port{{Port: 80, ContainerPort: 80}},
+						Expose: []Port{{Port: 8080, ContainerPort: 8080}},
+					},
+				},
+				Endpoints: map[string][]Endpoint{
+					"admin": {{Path: "/", Service: "name", Port: 8080}},
+				},
+			},
+		},
+		{
+			name: "env-value-and-value-from",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:       "image",
+						Environment: []EnvVar{{Name: "TOKEN", Value: "token", ValueFrom: &EnvVarSource{SecretKeyRef: &EnvKeyRef{Name: "api", Key: "token"}}}},
+					},
+				},
+			},
+		},
+		{
+			name: "env-value-from-two-sources",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:       "image",
+						Environment: []EnvVar{{Name: "TOKEN", ValueFrom: &EnvVarSource{SecretKeyRef: &EnvKeyRef{Name: "api", Key: "token"}, ConfigMapKeyRef: &EnvKeyRef{Name: "api", Key: "token"}}}},
+					},
+				},
+			},
+		},
+		{
+			name: "env-value-from-without-source",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:       "image",
+						Environment: []EnvVar{{Name: "TOKEN", ValueFrom: &EnvVarSource{}}},
+					},
+				},
+			},
+		},
+		{
+			name: "env-value-from-without-key",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:       "image",
+						Environment: []EnvVar{{Name: "TOKEN", ValueFrom: &EnvVarSource{ConfigMapKeyRef: &EnvKeyRef{Name: "api"}}}},
+					},
+				},
+			},
+		},
+		{
+			name: "env-from-two-sources",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:   "image",
+						EnvFrom: []EnvFromSource{{SecretRef: &EnvSourceRef{Name: "api"}, ConfigMapRef: &EnvSourceRef{Name: "api"}}},
+					},
+				},
+			},
+		},
+		{
+			name: "env-from-without-name",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:   "image",
+						EnvFrom: []EnvFromSource{{SecretRef: &EnvSourceRef{}}},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicated-exposed-port",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:  "image",
+						Expose: []Port{{Port: 9090, ContainerPort: 9090}, {Port: 9090, ContainerPort: 9090}},
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported-platform",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Platforms: map[string]PlatformOverride{
+							"windows/amd64": {Command: Command{Values: []string{"app.exe"}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "bad-platform-format",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Platforms: map[string]PlatformOverride{
+							"arm64": {Command: Command{Values: []string{"app"}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported-pull-policy",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image:      "image",
+						PullPolicy: "sometimes",
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported-build-network",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Build: &BuildInfo{Context: ".", Network: "my-network"},
+					},
+				},
+			},
+		},
+		{
+			name: "build-arg-value-from",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Build: &BuildInfo{
+							Context: ".",
+							Args: []EnvVar{
+								{Name: "TOKEN", ValueFrom: &EnvVarSource{SecretKeyRef: &EnvKeyRef{Name: "secret", Key: "token"}}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autoscaling-without-max",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Deploy: &DeployInfo{
+							Autoscaling: &AutoscalingInfo{Min: 1},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autoscaling-min-greater-than-max",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Deploy: &DeployInfo{
+							Autoscaling: &AutoscalingInfo{Min: 5, Max: 3},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autoscaling-cpu-percent-without-cpu-requests",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Deploy: &DeployInfo{
+							Autoscaling: &AutoscalingInfo{Min: 1, Max: 3, CPUPercent: 80},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autoscaling-unknown-metric-type",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Deploy: &DeployInfo{
+							Autoscaling: &AutoscalingInfo{
+								Min: 1,
+								Max: 3,
+								Metrics: []AutoscalingMetric{
+									{Type: "object", Name: "requests", AverageValue: Quantity{Value: resource.MustParse("10")}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autoscaling-pods-metric-without-average-value",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Deploy: &DeployInfo{
+							Autoscaling: &AutoscalingInfo{
+								Min: 1,
+								Max: 3,
+								Metrics: []AutoscalingMetric{
+									{Type: PodsMetricType, Name: "requests", Value: Quantity{Value: resource.MustParse("10")}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autoscaling-negative-stabilization-window",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Deploy: &DeployInfo{
+							Autoscaling: &AutoscalingInfo{
+								Max: 3,
+								Behavior: &AutoscalingBehavior{
+									ScaleDown: &AutoscalingRules{StabilizationWindow: pointer.Int32Ptr(-1)},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autoscaling-unknown-policy-type",
+			stack: &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"name": {
+						Image: "image",
+						Deploy: &DeployInfo{
+							Autoscaling: &AutoscalingInfo{
+								Max: 3,
+								Behavior: &AutoscalingBehavior{
+									ScaleUp: &AutoscalingRules{
+										Policies: []AutoscalingPolicy{{Type: "replicas", Value: 1, Period: 60}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "endpoint-of-undefined-service",
+			stack: &Stack{
+				Name: "name",
+				Endpoints: map[string][]Endpoint{
+					"endpoint1": {
+						{Service: "app"},
+					},
+				},
+				Services: map[string]Service{
+					"name": {},
+				},
+			},
+		},
+		{
+			name: "endpoint-of-unexported-port",
+			stack: &Stack{
+				Name: "name",
+				Endpoints: map[string][]Endpoint{
+					"endpoint1": {
+						{Service: "name",
+							Port: 80},
+					},
+				},
+				Services: map[string]Service{
+					"name": {Ports: []Port{
+						{Port: 8080, ContainerPort: 8080},
+					}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.stack.validate(); err == nil {
+				t.Errorf("Stack.validate() not failed for test '%s'", tt.name)
+			}
+		})
+	}
+}
+
+func TestStack_ResolveEndpointsForService(t *testing.T) {
+	s := &Stack{
+		Name: "name",
+		Services: map[string]Service{
+			"api":    {Ports: []Port{{Port: 8080, ContainerPort: 8080}, {Port: 9090, ContainerPort: 9090}}},
+			"web":    {Ports: []Port{{Port: 80, ContainerPort: 80}}},
+			"worker": {},
+		},
+		Endpoints: map[string][]Endpoint{
+			"web": {
+				{Path: "/", Service: "web", Port: 80},
+				{Path: "/api", Service: "api", Port: 8080},
+			},
+			"admin": {
+				{Path: "/admin", Service: "api", Port: 9090},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		service  string
+		expected []EndpointRoute
+	}{
+		{
+			name:     "none",
+			service:  "worker",
+			expected: []EndpointRoute{},
+		},
+		{
+			name:     "one",
+			service:  "web",
+			expected: []EndpointRoute{{Name: "web", Path: "/", Port: 80}},
+		},
+		{
+			name:    "multiple",
+			service: "api",
+			expected: []EndpointRoute{
+				{Name: "admin", Path: "/admin", Port: 9090},
+				{Name: "web", Path: "/api", Port: 8080},
+			},
+		},
+		{
+			name:     "not-defined",
+			service:  "db",
+			expected: []EndpointRoute{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := s.ResolveEndpointsForService(tt.service); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Stack.ResolveEndpointsForService() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStack_GetServiceByPort(t *testing.T) {
+	s := &Stack{
+		Name: "name",
+		Services: map[string]Service{
+			"api":    {Ports: []Port{{Port: 8080, ContainerPort: 8080}}},
+			"worker": {Ports: []Port{{Port: 9090, ContainerPort: 80}}},
+			"admin":  {Ports: []Port{{Port: 9090, ContainerPort: 9090}}},
+			"db":     {},
+		},
+	}
+	tests := []struct {
+		name     string
+		port     int32
+		expected []string
+	}{
+		{
+			name:     "unique",
+			port:     8080,
+			expected: []string{"api"},
+		},
+		{
+			name:     "ambiguous",
+			port:     9090,
+			expected: []string{"admin", "worker"},
+		},
+		{
+			name:     "container-port",
+			port:     80,
+			expected: []string{},
+		},
+		{
+			name:     "not-published",
+			port:     5432,
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := s.GetServiceByPort(tt.port); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Stack.GetServiceByPort() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStack_OverrideCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "simple",
+			override: "web=sleep infinity",
+			expected: []string{"sleep", "infinity"},
+		},
+		{
+			name:     "double-quotes",
+			override: `web=sh -c "echo hello && sleep 10"`,
+			expected: []string{"sh", "-c", "echo hello && sleep 10"},
+		},
+		{
+			name:     "single-quotes",
+			override: `web=echo 'a "quoted" value'`,
+			expected: []string{"echo", `a "quoted" value`},
+		},
+		{
+			name:     "escapes",
+			override: `web=echo a\ b ""`,
+			expected: []string{"echo", "a b", ""},
+		},
+		{
+			name:     "unknown-service",
+			override: "db=sleep infinity",
+			wantErr:  true,
+		},
+		{
+			name:     "missing-command",
+			override: "web",
+			wantErr:  true,
+		},
+		{
+			name:     "empty-command",
+			override: "web= ",
+			wantErr:  true,
+		},
+		{
+			name:     "unterminated-quote",
+			override: `web=echo "hello`,
+			wantErr:  true,
+		},
+		{
+			name:     "empty-executable",
+			override: `web="" infinity`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"web": {
+						Image:     "web",
+						Command:   Command{Values: []string{"python"}},
+						Args:      Args{Values: []string{"app.py"}},
+						Platforms: map[string]PlatformOverride{"linux/arm64": {Command: Command{Values: []string{"python3"}}, Args: Args{Values: []string{"arm.py"}}}},
+					},
+					"api": {Image: "api", Command: Command{Values: []string{"node"}}},
+				},
+			}
+			err := s.OverrideCommand(tt.override)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Stack.OverrideCommand() didn't fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s.Services["web"].Command.Values, tt.expected) {
+				t.Errorf("wrong command: %v, expected %v", s.Services["web"].Command.Values, tt.expected)
+			}
+			if len(s.Services["web"].Args.Values) != 0 {
+				t.Errorf("args were not cleared: %v", s.Services["web"].Args.Values)
+			}
+			platform := s.Services["web"].Platforms["linux/arm64"]
+			if len(platform.Command.Values) != 0 || len(platform.Args.Values) != 0 {
+				t.Errorf("platform command was not cleared: %+v", platform)
+			}
+			if !reflect.DeepEqual(s.Services["api"].Command.Values, []string{"node"}) {
+				t.Errorf("wrong service was modified: %v", s.Services["api"].Command.Values)
+			}
+		})
+	}
+}
+
+func TestStack_OverrideImage(t *testing.T) {
+	tests := []struct {
+		name             string
+		override         string
+		requireImageTags bool
+		expected         string
+		wantErr          bool
+	}{
+		{
+			name:     "digest",
+			override: "web=okteto/web@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+			expected: "okteto/web@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+		},
+		{
+			name:     "tag",
+			override: "web=registry.example.com:5000/web:1.2.3",
+			expected: "registry.example.com:5000/web:1.2.3",
+		},
+		{
+			name:             "require-image-tags",
+			override:         "web=okteto/web:1.2.3",
+			requireImageTags: true,
+			expected:         "okteto/web:1.2.3",
+		},
+		{
+			name:             "require-image-tags-latest",
+			override:         "web=okteto/web:latest",
+			requireImageTags: true,
+			wantErr:          true,
+		},
+		{
+			name:     "unknown-service",
+			override: "db=postgres:13",
+			wantErr:  true,
+		},
+		{
+			name:     "missing-image",
+			override: "web",
+			wantErr:  true,
+		},
+		{
+			name:     "empty-image",
+			override: "web=",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:   "name",
+				Okteto: OktetoOptions{RequireImageTags: tt.requireImageTags},
+				Services: map[string]Service{
+					"web": {Image: "okteto.dev/web:okteto", Build: &BuildInfo{Context: "web"}},
+					"api": {Image: "api:1.0", Build: &BuildInfo{Context: "api"}},
+				},
+			}
+			err := s.OverrideImage(tt.override)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Stack.OverrideImage() didn't fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := s.Services["web"]
+			if web.Image != tt.expected {
+				t.Errorf("wrong image: '%s', expected '%s'", web.Image, tt.expected)
+			}
+			if web.Build != nil {
+				t.Errorf("the build of an overridden image was not skipped: %+v", web.Build)
+			}
+			if api := s.Services["api"]; api.Image != "api:1.0" || api.Build == nil {
+				t.Errorf("wrong service was modified: %+v", api)
+			}
+		})
+	}
+}
+
+func Test_ReadImageOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "images.yml")
+	if err := ioutil.WriteFile(path, []byte("web: okteto/web@sha256:123\napi: okteto/api:1.0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ReadImageOverrides(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"web": "okteto/web@sha256:123", "api": "okteto/api:1.0"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("wrong images: %v", result)
+	}
+
+	s := &Stack{
+		Name: "name",
+		Services: map[string]Service{
+			"web": {Build: &BuildInfo{Context: "web"}},
+			"api": {Image: "okteto/api:0.9"},
+		},
+	}
+	if err := s.OverrideImages(result); err != nil {
+		t.Fatal(err)
+	}
+	if s.Services["web"].Image != "okteto/web@sha256:123" || s.Services["web"].Build != nil {
+		t.Errorf("wrong service 'web': %+v", s.Services["web"])
+	}
+	if s.Services["api"].Image != "okteto/api:1.0" {
+		t.Errorf("wrong service 'api': %+v", s.Services["api"])
+	}
+
+	if err := ioutil.WriteFile(path, []byte("- web\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadImageOverrides(path); err == nil {
+		t.Errorf("ReadImageOverrides() didn't fail for an invalid file")
+	}
+}
+
+func Test_ReadStackInlineVolumeSize(t *testing.T) {
+	manifest := []byte(`services:
+  db:
+    image: postgres
+    volumes:
+      - /var/lib/postgresql/data:10Gi`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := s.Services["db"]
+	if !reflect.DeepEqual(db.Volumes, []string{"/var/lib/postgresql/data"}) {
+		t.Errorf("wrong volumes: %v", db.Volumes)
+	}
+	size := db.Resources.Requests.Storage.Size.Value
+	if size.Cmp(resource.MustParse("10Gi")) != 0 {
+		t.Errorf("wrong storage size: %s", size.String())
+	}
+}
+
+func TestParseVolume(t *testing.T) {
+	tests := []struct {
+		name     string
+		volume   string
+		expected StackVolume
+		wantErr  bool
+	}{
+		{name: "path", volume: "/data", expected: StackVolume{MountPath: "/data"}},
+		{name: "size", volume: "/data:10Gi", expected: StackVolume{MountPath: "/data", Size: Quantity{Value: resource.MustParse("10Gi")}}},
+		{name: "read-only", volume: "/data:ro", expected: StackVolume{MountPath: "/data", ReadOnly: true}},
+		{name: "read-write", volume: "/data:rw", expected: StackVolume{MountPath: "/data"}},
+		{name: "size-read-only", volume: "/data:10Gi:ro", expected: StackVolume{MountPath: "/data", Size: Quantity{Value: resource.MustParse("10Gi")}, ReadOnly: true}},
+		{name: "relative", volume: "data", wantErr: true},
+		{name: "bind-mount", volume: "/src:/app", wantErr: true},
+		{name: "too-many-parts", volume: "/data:10Gi:20Gi:ro", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseVolume(tt.volume)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVolume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if result.MountPath != tt.expected.MountPath || result.ReadOnly != tt.expected.ReadOnly || result.Size.Value.Cmp(tt.expected.Size.Value) != 0 {
+				t.Errorf("wrong volume: %+v", result)
+			}
+		})
+	}
+}
+
+func Test_ReadStackDeployResources(t *testing.T) {
+	manifest := []byte(`services:
+  api:
+    image: okteto/api
+    resources:
+      limits:
+        cpu: 2
+      requests:
+        memory: 64Mi
+    deploy:
+      resources:
+        limits:
+          cpus: '0.5'
+          memory: 512M
+        reservations:
+          cpus: 0.25
+          memory: 128M`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := s.Services["api"].Resources
+	for name, tt := range map[string]struct {
+		value    resource.Quantity
+		expected string
+	}{
+		"limits.cpu":      {value: resources.Limits.CPU.Value, expected: "500m"},
+		"limits.memory":   {value: resources.Limits.Memory.Value, expected: "512M"},
+		"requests.cpu":    {value: resources.Requests.CPU.Value, expected: "250m"},
+		"requests.memory": {value: resources.Requests.Memory.Value, expected: "128M"},
+	} {
+		if tt.value.Cmp(resource.MustParse(tt.expected)) != 0 {
+			t.Errorf("wrong %s: %s", name, tt.value.String())
+		}
+	}
+}
+
+func TestParseUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		uid     *int64
+		gid     *int64
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "uid", user: "1000", uid: pointer.Int64Ptr(1000)},
+		{name: "uid-gid", user: "1000:2000", uid: pointer.Int64Ptr(1000), gid: pointer.Int64Ptr(2000)},
+		{name: "root", user: "0:0", uid: pointer.Int64Ptr(0), gid: pointer.Int64Ptr(0)},
+		{name: "name", user: "postgres", wantErr: true},
+		{name: "name-group", user: "1000:staff", wantErr: true},
+		{name: "negative", user: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, gid, err := ParseUser(tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(uid, tt.uid) || !reflect.DeepEqual(gid, tt.gid) {
+				t.Errorf("wrong user: %v:%v", uid, gid)
+			}
+		})
+	}
+}
+
+func Test_ReadStackReadOnlyVolumes(t *testing.T) {
+	manifest := []byte(`services:
+  web:
+    image: nginx
+    volumes:
+      - /usr/share/nginx/html:1Gi:ro
+      - assets:/assets:ro
+      - uploads:/uploads:rw
+volumes:
+  assets:
+  uploads:`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := s.Services["web"]
+	if !reflect.DeepEqual(web.Volumes, []string{"/usr/share/nginx/html:ro"}) {
+		t.Errorf("wrong volumes: %v", web.Volumes)
+	}
+	namedVolumes := []NamedVolumeMount{
+		{Name: "assets", MountPath: "/assets", ReadOnly: true},
+		{Name: "uploads", MountPath: "/uploads"},
+	}
+	if !reflect.DeepEqual(web.NamedVolumes, namedVolumes) {
+		t.Errorf("wrong named volumes: %v", web.NamedVolumes)
+	}
+	if err := s.validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func Test_ReadStackProtocolPorts(t *testing.T) {
+	manifest := []byte(`name: dns
+services:
+  dns:
+    image: coredns/coredns
+    ports:
+      - 53/udp
+      - 53:53/tcp
+      - 9153`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Port{
+		{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP},
+		{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolTCP},
+		{Port: 9153, ContainerPort: 9153},
+	}
+	dns := s.Services["dns"]
+	if !reflect.DeepEqual(dns.Ports, expected) {
+		t.Errorf("wrong ports: %v", dns.Ports)
+	}
+	if err := s.validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func Test_ReadStackNodePorts(t *testing.T) {
+	manifest := []byte(`services:
+  api:
+    image: okteto/api
+    service_type: NodePort
+    ports:
+      - published: 80
+        target: 8080
+        node_port: 30080
+      - target: 9090
+      - 53/udp`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := s.Services["api"]
+	if api.ServiceType != apiv1.ServiceTypeNodePort {
+		t.Errorf("wrong service type: %s", api.ServiceType)
+	}
+	expected := []Port{
+		{Port: 80, ContainerPort: 8080, NodePort: 30080},
+		{Port: 9090, ContainerPort: 9090},
+		{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP},
+	}
+	if !reflect.DeepEqual(api.Ports, expected) {
+		t.Errorf("wrong ports: %v", api.Ports)
+	}
+	if err := s.validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	b, err := yaml.Marshal(api.Ports[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var port Port
+	if err := yaml.UnmarshalStrict(b, &port); err != nil {
+		t.Fatal(err)
+	}
+	if port != api.Ports[0] {
+		t.Errorf("wrong marshalled port: %s", string(b))
+	}
+
+	if _, err := ReadStack([]byte(`services:
+  api:
+    image: okteto/api
+    ports:
+      - published: 80`)); err == nil {
+		t.Errorf("ReadStack() didn't fail for a port without target")
+	}
+}
+
+func Test_ReadStackSessionAffinity(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		expected *SessionAffinity
+		wantErr  bool
+	}{
+		{
+			name: "unset",
+			manifest: `services:
+  api:
+    image: okteto/api
+    ports:
+      - 8080`,
+		},
+		{
+			name: "short-syntax",
+			manifest: `services:
+  api:
+    image: okteto/api
+    session_affinity: ClientIP
+    ports:
+      - 8080`,
+			expected: &SessionAffinity{Type: apiv1.ServiceAffinityClientIP},
+		},
+		{
+			name: "timeout",
+			manifest: `services:
+  api:
+    image: okteto/api
+    session_affinity:
+      type: ClientIP
+      timeout: 3600
+    ports:
+      - 8080`,
+			expected: &SessionAffinity{Type: apiv1.ServiceAffinityClientIP, Timeout: pointer.Int32Ptr(3600)},
+		},
+		{
+			name: "timeout-too-long",
+			manifest: `services:
+  api:
+    image: okteto/api
+    session_affinity:
+      type: ClientIP
+      timeout: 86401
+    ports:
+      - 8080`,
+			wantErr: true,
+		},
+		{
+			name: "timeout-zero",
+			manifest: `services:
+  api:
+    image: okteto/api
+    session_affinity:
+      type: ClientIP
+      timeout: 0
+    ports:
+      - 8080`,
+			wantErr: true,
+		},
+		{
+			name: "timeout-without-client-ip",
+			manifest: `services:
+  api:
+    image: okteto/api
+    session_affinity:
+      timeout: 3600
+    ports:
+      - 8080`,
+			wantErr: true,
+		},
+		{
+			name: "unknown-type",
+			manifest: `services:
+  api:
+    image: okteto/api
+    session_affinity: Cookie
+    ports:
+      - 8080`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ReadStack([]byte(tt.manifest))
+			if err == nil {
+				err = s.validate()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadStack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result := s.Services["api"].SessionAffinity; !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("wrong session affinity: %+v", result)
+			}
+		})
+	}
+}
+
+func Test_ReadStackReload(t *testing.T) {
+	s, err := ReadStack([]byte(`services:
+  api:
+    image: okteto/api
+    reload: true
+  worker:
+    image: okteto/worker`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Services["api"].Reload || s.Services["worker"].Reload {
+		t.Errorf("wrong reload: %+v", s.Services)
+	}
+	if _, err := ReadStack([]byte(`services:
+  api:
+    image: okteto/api
+    reload: sometimes`)); err == nil {
+		t.Errorf("ReadStack() didn't fail for a reload that isn't a boolean")
+	}
+}
+
+func Test_ReadStackServiceEndpoints(t *testing.T) {
+	manifest := []byte(`services:
+  api:
+    image: okteto/api
+    public: true
+    ports:
+      - 8080
+      - 9090
+    endpoints:
+      - port: 8080
+      - port: 9090
+        path: /admin
+        name: admin
+  web:
+    image: okteto/web
+    ports:
+      - 80
+    endpoints:
+      - port: 80
+        path: /
+        name: admin
+endpoints:
+  docs:
+    - path: /docs
+      service: web
+      port: 80`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]Endpoint{
+		"api-8080": {{Path: "/", Service: "api", Port: 8080}},
+		"admin":    {{Path: "/admin", Service: "api", Port: 9090}, {Path: "/", Service: "web", Port: 80}},
+		"docs":     {{Path: "/docs", Service: "web", Port: 80}},
+	}
+	if !reflect.DeepEqual(s.Endpoints, expected) {
+		t.Errorf("wrong endpoints: %+v", s.Endpoints)
+	}
+	if err := s.validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{
+			name: "port-not-published",
+			manifest: `services:
+  api:
+    image: okteto/api
+    ports:
+      - 8080
+    endpoints:
+      - port: 9090`,
+		},
+		{
+			name: "udp-port",
+			manifest: `services:
+  dns:
+    image: coredns/coredns
+    ports:
+      - 53/udp
+    endpoints:
+      - port: 53`,
+		},
+		{
+			name: "defined-in-stack-endpoints",
+			manifest: `services:
+  api:
+    image: okteto/api
+    ports:
+      - 8080
+    endpoints:
+      - port: 8080
+        name: api
+endpoints:
+  api:
+    - path: /
+      service: api
+      port: 8080`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadStack([]byte(tt.manifest)); err == nil {
+				t.Errorf("ReadStack() didn't fail")
+			}
+		})
+	}
+}
+
+func Test_ReadStackNamedVolumes(t *testing.T) {
+	manifest := []byte(`services:
+  web:
+    image: nginx
+    volumes:
+      - uploads:/var/www/uploads
+      - /cache
+  worker:
+    image: worker
+    volumes:
+      - uploads:/uploads
+volumes:
+  uploads:
+    size: 5Gi
+  unused:`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := s.Services["web"]
+	if !reflect.DeepEqual(web.Volumes, []string{"/cache"}) {
+		t.Errorf("wrong volumes: %v", web.Volumes)
+	}
+	if !reflect.DeepEqual(web.NamedVolumes, []NamedVolumeMount{{Name: "uploads", MountPath: "/var/www/uploads"}}) {
+		t.Errorf("wrong named volumes: %v", web.NamedVolumes)
+	}
+	worker := s.Services["worker"]
+	if worker.Volumes != nil {
+		t.Errorf("wrong volumes: %v", worker.Volumes)
+	}
+	if !reflect.DeepEqual(s.GetVolumeNames(), []string{"uploads"}) {
+		t.Errorf("wrong volume names: %v", s.GetVolumeNames())
+	}
+	if err := s.validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestStack_validateNamedVolumes(t *testing.T) {
+	tests := []struct {
+		name    string
+		volumes map[string]VolumeSpec
+		mount   NamedVolumeMount
+		wantErr bool
+	}{
+		{name: "defined", volumes: map[string]VolumeSpec{"uploads": {}}, mount: NamedVolumeMount{Name: "uploads", MountPath: "/data"}},
+		{name: "undefined", mount: NamedVolumeMount{Name: "uploads", MountPath: "/data"}, wantErr: true},
+		{name: "relative-path", volumes: map[string]VolumeSpec{"uploads": {}}, mount: NamedVolumeMount{Name: "uploads", MountPath: "data"}, wantErr: true},
+		{name: "bad-name", volumes: map[string]VolumeSpec{"Uploads_1": {}}, mount: NamedVolumeMount{Name: "Uploads_1", MountPath: "/data"}, wantErr: true},
+		{name: "bad-access-mode", volumes: map[string]VolumeSpec{"uploads": {AccessMode: "ReadWriteSometimes"}}, mount: NamedVolumeMount{Name: "uploads", MountPath: "/data"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:    "name",
+				Volumes: tt.volumes,
+				Services: map[string]Service{
+					"api": {Image: "okteto/api", NamedVolumes: []NamedVolumeMount{tt.mount}},
+				},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateRequireImageTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   string
+		build   *BuildInfo
+		wantErr bool
+	}{
+		{name: "tag", image: "okteto/api:1.0"},
+		{name: "digest", image: "okteto/api@sha256:7c5f1b8bdc3a9d5a4c4d4fd1d0d5f7ec"},
+		{name: "registry-with-port-and-tag", image: "localhost:5000/api:1.0"},
+		{name: "build-without-image", build: &BuildInfo{Context: "."}},
+		{name: "untagged", image: "okteto/api", wantErr: true},
+		{name: "latest", image: "okteto/api:latest", wantErr: true},
+		{name: "registry-with-port-untagged", image: "localhost:5000/api", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"api": {Image: tt.image, Build: tt.build},
+				},
+			}
+			if err := s.validate(); err != nil {
+				t.Fatalf("lenient Stack.validate() failed: %s", err)
+			}
+			s.Okteto.RequireImageTags = true
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("strict Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ReadStackRestart(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest []byte
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "unquoted-no",
+			manifest: []byte("services:\n  api:\n    image: okteto/api\n    restart: no\n"),
+			expected: RestartNo,
+		},
+		{
+			name:     "unless-stopped",
+			manifest: []byte("services:\n  api:\n    image: okteto/api\n    restart: unless-stopped\n"),
+			expected: RestartUnlessStopped,
+		},
+		{
+			name:     "typo",
+			manifest: []byte("services:\n  api:\n    image: okteto/api\n    restart: alway\n"),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ReadStack(tt.manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Name = "name"
+			err = s.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), "'alway' is not supported") {
+					t.Errorf("unclear error: %s", err)
+				}
+				return
+			}
+			if s.Services["api"].Restart != tt.expected {
+				t.Errorf("wrong restart: '%s'", s.Services["api"].Restart)
+			}
+		})
+	}
+}
+
+func TestStack_validateServiceKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr bool
+	}{
+		{name: "empty", svc: Service{Image: "image"}},
+		{name: "job", svc: Service{Image: "image", Kind: JobServiceKind}},
+		{name: "job-with-ports", svc: Service{Image: "image", Kind: JobServiceKind, Ports: []Port{{Port: 8080, ContainerPort: 8080}}}, wantErr: true},
+		{name: "job-with-volumes", svc: Service{Image: "image", Kind: JobServiceKind, Volumes: []string{"/data"}}, wantErr: true},
+		{name: "job-with-autoscaling", svc: Service{Image: "image", Kind: JobServiceKind, Deploy: &DeployInfo{Autoscaling: &AutoscalingInfo{Min: 1, Max: 2}}}, wantErr: true},
+		{name: "unknown", svc: Service{Image: "image", Kind: "cronjob"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": tt.svc},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr bool
+	}{
+		{name: "empty", svc: Service{Image: "image"}},
+		{name: "every-minute", svc: Service{Image: "image", Schedule: "* * * * *"}},
+		{name: "ranges-and-steps", svc: Service{Image: "image", Schedule: "*/15 9-17 1,15 * MON-FRI"}},
+		{name: "month-names", svc: Service{Image: "image", Schedule: "0 0 1 jan,jul *"}},
+		{name: "predefined", svc: Service{Image: "image", Schedule: "@daily"}},
+		{name: "unknown-predefined", svc: Service{Image: "image", Schedule: "@sometimes"}, wantErr: true},
+		{name: "missing-fields", svc: Service{Image: "image", Schedule: "0 3 * *"}, wantErr: true},
+		{name: "out-of-range", svc: Service{Image: "image", Schedule: "60 3 * * *"}, wantErr: true},
+		{name: "invalid-step", svc: Service{Image: "image", Schedule: "*/0 * * * *"}, wantErr: true},
+		{name: "invalid-range", svc: Service{Image: "image", Schedule: "0 17-9 * * *"}, wantErr: true},
+		{name: "invalid-value", svc: Service{Image: "image", Schedule: "0 3 * * someday"}, wantErr: true},
+		{name: "with-ports", svc: Service{Image: "image", Schedule: "@hourly", Ports: []Port{{Port: 8080, ContainerPort: 8080}}}, wantErr: true},
+		{name: "with-volumes", svc: Service{Image: "image", Schedule: "@hourly", Volumes: []string{"/data"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": tt.svc},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_IsJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		svc      Service
+		expected bool
+	}{
+		{name: "default", svc: Service{}, expected: false},
+		{name: "kind", svc: Service{Kind: JobServiceKind}, expected: true},
+		{name: "restart-no", svc: Service{Restart: RestartNo}, expected: true},
+		{name: "restart-always", svc: Service{Restart: RestartAlways}, expected: false},
+		{name: "restart-no-with-ports", svc: Service{Restart: RestartNo, Ports: []Port{{Port: 8080, ContainerPort: 8080}}}, expected: false},
+		{name: "restart-no-with-expose", svc: Service{Restart: RestartNo, Expose: []Port{{Port: 8080, ContainerPort: 8080}}}, expected: false},
+		{name: "restart-no-with-volumes", svc: Service{Restart: RestartNo, Volumes: []string{"/data"}}, expected: false},
+		{name: "scheduled", svc: Service{Restart: RestartNo, Schedule: "@daily"}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.svc.IsJob(); result != tt.expected {
+				t.Errorf("Service.IsJob() = %t, expected %t", result, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_ReadStackStorageAccessMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		accessMode string
+		expected   apiv1.PersistentVolumeAccessMode
+		wantErr    bool
+	}{
+		{name: "read-write-once", accessMode: "ReadWriteOnce", expected: apiv1.ReadWriteOnce},
+		{name: "read-write-many", accessMode: "ReadWriteMany", expected: apiv1.ReadWriteMany},
+		{name: "read-only-many", accessMode: "ReadOnlyMany", expected: apiv1.ReadOnlyMany},
+		{name: "unknown", accessMode: "ReadWriteSometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := []byte(fmt.Sprintf(`services:
+  db:
+    image: postgres
+    replicas: 2
+    resources:
+      requests:
+        storage:
+          size: 1Gi
+          access_mode: %s
+    volumes:
+      - /var/lib/postgresql/data`, tt.accessMode))
+			s, err := ReadStack(manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Name = "name"
+			err = s.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if accessMode := s.Services["db"].Resources.Requests.Storage.AccessMode; accessMode != tt.expected {
+				t.Errorf("wrong access mode: %s", accessMode)
+			}
+		})
+	}
+}
+
+func TestStack_validateLoadBalancer(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr bool
+	}{
+		{name: "empty", svc: Service{Image: "image", Public: true}},
+		{name: "internal", svc: Service{Image: "image", Public: true, LoadBalancer: InternalLoadBalancer}},
+		{name: "external", svc: Service{Image: "image", Public: true, LoadBalancer: ExternalLoadBalancer}},
+		{name: "nlb", svc: Service{Image: "image", Public: true, NLB: true}},
+		{name: "unknown", svc: Service{Image: "image", Public: true, LoadBalancer: "private"}, wantErr: true},
+		{name: "not-public", svc: Service{Image: "image", LoadBalancer: InternalLoadBalancer}, wantErr: true},
+		{name: "nlb-not-public", svc: Service{Image: "image", NLB: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.svc.Ports = []Port{{Port: 80, ContainerPort: 8080}}
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": tt.svc},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateServiceType(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr bool
+	}{
+		{name: "inferred", svc: Service{Image: "image", Public: true, Ports: []Port{{Port: 80, ContainerPort: 80}}}},
+		{name: "cluster-ip", svc: Service{Image: "image", ServiceType: apiv1.ServiceTypeClusterIP, Ports: []Port{{Port: 80, ContainerPort: 80}}}},
+		{name: "node-port", svc: Service{Image: "image", ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 80, ContainerPort: 80, NodePort: 30080}}}},
+		{name: "node-port-without-node-port", svc: Service{Image: "image", ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 80, ContainerPort: 80}}}},
+		{name: "load-balancer-node-port", svc: Service{Image: "image", ServiceType: apiv1.ServiceTypeLoadBalancer, Ports: []Port{{Port: 80, ContainerPort: 80, NodePort: 30080}}}},
+		{name: "public-node-port", svc: Service{Image: "image", Public: true, ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 80, ContainerPort: 80}}}},
+		{name: "public-cluster-ip", svc: Service{Image: "image", Public: true, ServiceType: apiv1.ServiceTypeClusterIP, Ports: []Port{{Port: 80, ContainerPort: 80}}}, wantErr: true},
+		{name: "unknown", svc: Service{Image: "image", ServiceType: "ExternalName", Ports: []Port{{Port: 80, ContainerPort: 80}}}, wantErr: true},
+		{name: "without-ports", svc: Service{Image: "image", ServiceType: apiv1.ServiceTypeNodePort}, wantErr: true},
+		{name: "node-port-below-range", svc: Service{Image: "image", ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 80, ContainerPort: 80, NodePort: 8080}}}, wantErr: true},
+		{name: "node-port-above-range", svc: Service{Image: "image", ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 80, ContainerPort: 80, NodePort: 32768}}}, wantErr: true},
+		{name: "node-port-cluster-ip", svc: Service{Image: "image", Ports: []Port{{Port: 80, ContainerPort: 80, NodePort: 30080}}}, wantErr: true},
+		{name: "load-balancer-options-node-port", svc: Service{Image: "image", Public: true, NLB: true, ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 80, ContainerPort: 80}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": tt.svc},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	s := &Stack{
+		Name: "name",
+		Services: map[string]Service{
+			"api": {Image: "image", ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 80, ContainerPort: 80, NodePort: 30080}}},
+			"web": {Image: "image", ServiceType: apiv1.ServiceTypeNodePort, Ports: []Port{{Port: 8080, ContainerPort: 8080, NodePort: 30080}}},
+		},
+	}
+	if err := s.validate(); err == nil {
+		t.Errorf("Stack.validate() didn't fail for a node port used by two services")
+	}
+}
+
+func TestStack_validateDeployMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr bool
+	}{
+		{name: "replicated", svc: Service{Image: "image", Deploy: &DeployInfo{Mode: ReplicatedDeployMode}}},
+		{name: "global", svc: Service{Image: "image", Deploy: &DeployInfo{Mode: GlobalDeployMode}}},
+		{name: "global-with-replicas", svc: Service{Image: "image", Replicas: 3, Deploy: &DeployInfo{Mode: GlobalDeployMode}}},
+		{name: "global-restart-no", svc: Service{Image: "image", Restart: RestartNo, Deploy: &DeployInfo{Mode: GlobalDeployMode}}},
+		{name: "unknown", svc: Service{Image: "image", Deploy: &DeployInfo{Mode: "replicated-job"}}, wantErr: true},
+		{name: "global-volumes", svc: Service{Image: "image", Volumes: []string{"/data"}, Deploy: &DeployInfo{Mode: GlobalDeployMode}}, wantErr: true},
+		{name: "global-named-volumes", svc: Service{Image: "image", NamedVolumes: []NamedVolumeMount{{Name: "data", MountPath: "/data"}}, Deploy: &DeployInfo{Mode: GlobalDeployMode}}, wantErr: true},
+		{name: "global-job", svc: Service{Image: "image", Kind: JobServiceKind, Deploy: &DeployInfo{Mode: GlobalDeployMode}}, wantErr: true},
+		{name: "global-cronjob", svc: Service{Image: "image", Schedule: "@daily", Deploy: &DeployInfo{Mode: GlobalDeployMode}}, wantErr: true},
+		{name: "global-autoscaling", svc: Service{Image: "image", Deploy: &DeployInfo{Mode: GlobalDeployMode, Autoscaling: &AutoscalingInfo{Min: 1, Max: 3, CPUPercent: 80}}}, wantErr: true},
+		{name: "global-pdb", svc: Service{Image: "image", Deploy: &DeployInfo{Mode: GlobalDeployMode, PDB: &PDBInfo{MaxUnavailable: "1"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": tt.svc},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ReadStackGlobalMode(t *testing.T) {
+	s, err := ReadStack([]byte(`services:
+  agent:
+    image: datadog/agent
+    restart: "no"
+    deploy:
+      mode: global
+  api:
+    image: okteto/api
+    deploy:
+      mode: replicated`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := s.Services["agent"]
+	if !agent.IsGlobal() || agent.IsJob() {
+		t.Errorf("wrong 'agent' mode: global %t, job %t", agent.IsGlobal(), agent.IsJob())
+	}
+	api := s.Services["api"]
+	if api.IsGlobal() {
+		t.Errorf("wrong 'api' mode: global")
+	}
+}
+
+func TestStack_validatePublicWithoutPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr bool
+	}{
+		{name: "private-without-ports", svc: Service{Image: "image"}},
+		{name: "public-with-ports", svc: Service{Image: "image", Public: true, Ports: []Port{{Port: 80, ContainerPort: 80}}}},
+		{name: "public-without-ports", svc: Service{Image: "image", Public: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": tt.svc},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validatePrivilegedDevices(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr bool
+	}{
+		{name: "privileged", svc: Service{Image: "image", Privileged: true}},
+		{name: "privileged-cap-drop", svc: Service{Image: "image", Privileged: true, CapDrop: []apiv1.Capability{"NET_RAW"}}},
+		{name: "privileged-cap-drop-all", svc: Service{Image: "image", Privileged: true, CapDrop: []apiv1.Capability{"ALL"}}, wantErr: true},
+		{name: "device", svc: Service{Image: "image", Privileged: true, Devices: []string{"/dev/fuse"}}},
+		{name: "device-container-path", svc: Service{Image: "image", Privileged: true, Devices: []string{"/dev/net/tun:/dev/tun:rwm"}}},
+		{name: "device-not-in-dev", svc: Service{Image: "image", Privileged: true, Devices: []string{"/var/run/docker.sock"}}, wantErr: true},
+		{name: "device-relative-container-path", svc: Service{Image: "image", Privileged: true, Devices: []string{"/dev/fuse:fuse"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": tt.svc},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateGPU(t *testing.T) {
+	tests := []struct {
+		name    string
+		gpu     *GPUInfo
+		wantErr bool
+	}{
+		{name: "full", gpu: &GPUInfo{Count: 2}},
+		{name: "default-count", gpu: &GPUInfo{}},
+		{name: "shared", gpu: &GPUInfo{Profile: "shared"}},
+		{name: "mig", gpu: &GPUInfo{Count: 1, Profile: "1g.5gb"}},
+		{name: "mig-media-extensions", gpu: &GPUInfo{Profile: "1g.10gb+me"}},
+		{name: "negative-count", gpu: &GPUInfo{Count: -1}, wantErr: true},
+		{name: "unknown-profile", gpu: &GPUInfo{Profile: "a100"}, wantErr: true},
+		{name: "wrong-mig-slices", gpu: &GPUInfo{Profile: "8g.40gb"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:     "name",
+				Services: map[string]Service{"api": {Image: "image", GPU: tt.gpu}},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateStdinTTY(t *testing.T) {
+	tests := []struct {
+		name      string
+		stdinOpen bool
+		tty       bool
+		wantErr   bool
+	}{
+		{name: "none"},
+		{name: "both", stdinOpen: true, tty: true},
+		{name: "stdin-only", stdinOpen: true, wantErr: true},
+		{name: "tty-only", tty: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"api": {Image: "image", StdinOpen: tt.stdinOpen, TTY: tt.tty},
+				},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validatePDB(t *testing.T) {
+	tests := []struct {
+		name    string
+		pdb     *PDBInfo
+		wantErr bool
+	}{
+		{name: "min-available", pdb: &PDBInfo{MinAvailable: "2"}},
+		{name: "min-available-percent", pdb: &PDBInfo{MinAvailable: "50%"}},
+		{name: "max-unavailable", pdb: &PDBInfo{MaxUnavailable: "1"}},
+		{name: "max-unavailable-percent", pdb: &PDBInfo{MaxUnavailable: "25%"}},
+		{name: "empty", pdb: &PDBInfo{}, wantErr: true},
+		{name: "both", pdb: &PDBInfo{MinAvailable: "1", MaxUnavailable: "1"}, wantErr: true},
+		{name: "negative", pdb: &PDBInfo{MinAvailable: "-1"}, wantErr: true},
+		{name: "invalid-percent", pdb: &PDBInfo{MaxUnavailable: "150%"}, wantErr: true},
+		{name: "not-a-number", pdb: &PDBInfo{MinAvailable: "half"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"api": {Image: "image", Replicas: 3, Deploy: &DeployInfo{PDB: tt.pdb}},
+				},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateCapabilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		capAdd  []apiv1.Capability
+		capDrop []apiv1.Capability
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "add", capAdd: []apiv1.Capability{"NET_ADMIN"}},
+		{name: "drop", capDrop: []apiv1.Capability{"ALL"}},
+		{name: "no-overlap", capAdd: []apiv1.Capability{"NET_ADMIN", "SYS_TIME"}, capDrop: []apiv1.Capability{"MKNOD"}},
+		{name: "overlap", capAdd: []apiv1.Capability{"NET_ADMIN", "SYS_TIME"}, capDrop: []apiv1.Capability{"MKNOD", "SYS_TIME"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"api": {Image: "image", CapAdd: tt.capAdd, CapDrop: tt.capDrop},
+				},
+			}
+			err := s.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "'SYS_TIME'") {
+				t.Errorf("error doesn't mention the conflicting capability: %s", err)
+			}
+		})
+	}
+}
+
+func Test_ReadStackHealthCheck(t *testing.T) {
+	manifest := []byte(`services:
+  api:
+    image: okteto/api
+    healthcheck:
+      http_get:
+        path: /healthz
+        port: 8080
+      interval: 10s
+      timeout: 2s
+      retries: 3
+      start_period: 1m`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &HealthCheck{
+		HTTP:        &HTTPHealthCheck{Path: "/healthz", Port: 8080},
+		Interval:    10 * time.Second,
+		Timeout:     2 * time.Second,
+		Retries:     3,
+		StartPeriod: time.Minute,
+	}
+	if !reflect.DeepEqual(s.Services["api"].Healthcheck, expected) {
+		t.Errorf("wrong healthcheck: %+v", s.Services["api"].Healthcheck)
+	}
+}
+
+func Test_ReadStackHealthCheckTest(t *testing.T) {
+	tests := []struct {
+		name     string
+		test     string
+		expected HealthCheckTest
+		command  []string
+	}{
+		{
+			name:     "cmd",
+			test:     `["CMD", "curl", "-f", "http://localhost"]`,
+			expected: HealthCheckTest{"CMD", "curl", "-f", "http://localhost"},
+			command:  []string{"curl", "-f", "http://localhost"},
+		},
+		{
+			name:     "cmd-shell",
+			test:     `["CMD-SHELL", "curl -f http://localhost || exit 1"]`,
+			expected: HealthCheckTest{"CMD-SHELL", "curl -f http://localhost || exit 1"},
+			command:  []string{"sh", "-c", "curl -f http://localhost || exit 1"},
+		},
+		{
+			name:     "string",
+			test:     `curl -f http://localhost || exit 1`,
+			expected: HealthCheckTest{"CMD-SHELL", "curl -f http://localhost || exit 1"},
+			command:  []string{"sh", "-c", "curl -f http://localhost || exit 1"},
+		},
+		{
+			name:     "none",
+			test:     `["NONE"]`,
+			expected: HealthCheckTest{"NONE"},
+			command:  []string{"NONE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := []byte(fmt.Sprintf(`services:
+  api:
+    image: okteto/api
+    healthcheck:
+      test: %s`, tt.test))
+			s, err := ReadStack(manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test := s.Services["api"].Healthcheck.Test
+			if !reflect.DeepEqual(test, tt.expected) {
+				t.Errorf("wrong healthcheck test: %+v", test)
+			}
+			if !reflect.DeepEqual(test.GetCommand(), tt.command) {
+				t.Errorf("wrong healthcheck command: %+v", test.GetCommand())
+			}
+			if test.IsDisabled() != (tt.name == "none") {
+				t.Errorf("wrong disabled healthcheck: %t", test.IsDisabled())
+			}
+		})
+	}
+}
+
+func TestStack_GetServiceEnv(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := ioutil.WriteFile(envPath, []byte("A=from-file\nB=from-file\nD=from-file\nG=\"pa\\$word\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	otherEnvPath := filepath.Join(dir, ".env.other")
+	if err := ioutil.WriteFile(otherEnvPath, []byte("D=from-other-file\nE=from-other-file\nF=${OKTETO_TEST_VALUE}-${D}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("OKTETO_TEST_ENV_DIR", dir)
+	os.Setenv("OKTETO_TEST_VALUE", "expanded")
+	defer os.Unsetenv("OKTETO_TEST_ENV_DIR")
+	defer os.Unsetenv("OKTETO_TEST_VALUE")
+
+	manifest := []byte(`services:
+  api:
+    image: okteto/api
+    env_file:
+      - ${OKTETO_TEST_ENV_DIR}/.env
+      - ${OKTETO_TEST_ENV_DIR}/.env.other
+    environment:
+      - C=inline
+      - B=inline
+      - E=inline
+      - C=${OKTETO_TEST_VALUE}`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := s.GetServiceEnv("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []EnvVar{
+		{Name: "A", Value: "from-file"},
+		{Name: "B", Value: "inline"},
+		{Name: "C", Value: "expanded"},
+		{Name: "D", Value: "from-other-file"},
+		{Name: "E", Value: "inline"},
+		{Name: "F", Value: "expanded-from-other-file"},
+		{Name: "G", Value: "pa$word"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("wrong environment: %+v", result)
+	}
+
+	if _, err := s.GetServiceEnv("web"); err == nil {
+		t.Errorf("expected an error for an unknown service")
+	}
+}
+
+func Test_ReadStackInterpolation(t *testing.T) {
+	os.Setenv("OKTETO_TEST_TAG", "1.0")
+	os.Setenv("OKTETO_TEST_DATA", "/data")
+	defer os.Unsetenv("OKTETO_TEST_TAG")
+	defer os.Unsetenv("OKTETO_TEST_DATA")
+
+	manifest := []byte(`services:
+  api:
+    image: okteto/api:${OKTETO_TEST_TAG:?the tag is required}
+    environment:
+      - REGISTRY=${OKTETO_TEST_REGISTRY:-${OKTETO_TEST_MIRROR:-docker.io}}
+      - PRICE=$$5
+    volumes:
+      - ${OKTETO_TEST_DATA}:/var/lib/data
+      - data:${OKTETO_TEST_MOUNT:-/cache}
+  worker:
+    build: .
+    image: ${OKTETO_TEST_REGISTRY:-okteto.dev}/worker:${OKTETO_TEST_TAG}
+volumes:
+  data: {}`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := s.Services["api"]
+	if api.Image != "okteto/api:1.0" {
+		t.Errorf("wrong image: '%s'", api.Image)
+	}
+	expectedEnv := Environment{
+		{Name: "REGISTRY", Value: "docker.io"},
+		{Name: "PRICE", Value: "$5"},
+	}
+	if !reflect.DeepEqual(api.Environment, expectedEnv) {
+		t.Errorf("wrong environment: %+v", api.Environment)
+	}
+	if !reflect.DeepEqual(api.Volumes, []string{"/data:/var/lib/data"}) {
+		t.Errorf("wrong volumes: %+v", api.Volumes)
+	}
+	if !reflect.DeepEqual(api.NamedVolumes, []NamedVolumeMount{{Name: "data", MountPath: "/cache"}}) {
+		t.Errorf("wrong named volumes: %+v", api.NamedVolumes)
+	}
+	if worker := s.Services["worker"]; worker.Image != "${OKTETO_TEST_REGISTRY:-okteto.dev}/worker:${OKTETO_TEST_TAG}" {
+		t.Errorf("the image of a built service was expanded: '%s'", worker.Image)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{
+			name:     "image",
+			manifest: "services:\n  api:\n    image: okteto/api:${OKTETO_TEST_MISSING:?the tag is required}",
+			err:      "the tag is required",
+		},
+		{
+			name:     "built-image",
+			manifest: "services:\n  api:\n    build: .\n    image: okteto/api:${OKTETO_TEST_MISSING:?the tag is required}",
+			err:      "the tag is required",
+		},
+		{
+			name:     "environment",
+			manifest: "services:\n  api:\n    image: okteto/api\n    environment:\n      - TOKEN=${OKTETO_TEST_MISSING:?the token is required}",
+			err:      "the token is required",
+		},
+		{
+			name:     "volume",
+			manifest: "services:\n  api:\n    image: okteto/api\n    volumes:\n      - ${OKTETO_TEST_MISSING:?the data path is required}:/data",
+			err:      "the data path is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadStack([]byte(tt.manifest))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("wrong error: %s", err)
+			}
+		})
+	}
+}
+
+func TestStack_validateHealthCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		healthchecks bool
+		healthcheck  *HealthCheck
+		wantErr      bool
+	}{
+		{name: "test", healthcheck: &HealthCheck{Test: []string{"curl", "-f", "http://localhost"}}},
+		{name: "http", healthcheck: &HealthCheck{HTTP: &HTTPHealthCheck{Path: "/", Port: 8080}}},
+		{name: "empty", healthcheck: &HealthCheck{}, wantErr: true},
+		{name: "test-and-http", healthcheck: &HealthCheck{Test: []string{"true"}, HTTP: &HTTPHealthCheck{Path: "/", Port: 8080}}, wantErr: true},
+		{name: "http-without-port", healthcheck: &HealthCheck{HTTP: &HTTPHealthCheck{Path: "/"}}, wantErr: true},
+		{name: "http-relative-path", healthcheck: &HealthCheck{HTTP: &HTTPHealthCheck{Path: "healthz", Port: 8080}}, wantErr: true},
+		{name: "negative-interval", healthcheck: &HealthCheck{Test: []string{"true"}, Interval: -time.Second}, wantErr: true},
+		{name: "negative-retries", healthcheck: &HealthCheck{Test: []string{"true"}, Retries: -1}, wantErr: true},
+		{name: "with-healthchecks", healthchecks: true, healthcheck: &HealthCheck{Test: []string{"true"}}, wantErr: true},
+		{name: "cmd", healthcheck: &HealthCheck{Test: []string{"CMD", "true"}}},
+		{name: "cmd-without-command", healthcheck: &HealthCheck{Test: []string{"CMD"}}, wantErr: true},
+		{name: "cmd-shell-without-command", healthcheck: &HealthCheck{Test: []string{"CMD-SHELL", ""}}, wantErr: true},
+		{name: "none", healthcheck: &HealthCheck{Test: []string{"NONE"}}},
+		{name: "cmd-empty-executable", healthcheck: &HealthCheck{Test: []string{"CMD", "", "-f"}}, wantErr: true},
+		{name: "empty-executable", healthcheck: &HealthCheck{Test: []string{" "}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"api": {Image: "image", Healthchecks: tt.healthchecks, Healthcheck: tt.healthcheck},
+				},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   []string
+		platforms map[string]PlatformOverride
+		wantErr   bool
+	}{
+		{name: "none"},
+		{name: "command", command: []string{"python", "app.py"}},
+		{name: "empty-argument", command: []string{"echo", ""}},
+		{name: "empty-executable", command: []string{""}, wantErr: true},
+		{name: "blank-executable", command: []string{" ", "app.py"}, wantErr: true},
+		{name: "platform-empty-executable", platforms: map[string]PlatformOverride{"linux/arm64": {Command: Command{Values: []string{""}}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name: "name",
+				Services: map[string]Service{
+					"api": {Image: "image", Command: Command{Values: tt.command}, Platforms: tt.platforms},
+				},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ReadStackMemoryOptions(t *testing.T) {
+	manifest := []byte(`services:
+  db:
+    image: postgres
+    mem_swappiness: 0
+    oom_kill_disable: true`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := s.Services["db"]
+	if db.MemSwappiness == nil || *db.MemSwappiness != 0 || !db.OOMKillDisable {
+		t.Errorf("memory options not parsed: %+v", db)
+	}
+}
+
+func TestStack_validateDependsOn(t *testing.T) {
+	tests := []struct {
+		name      string
+		services  map[string]Service
+		wantErr   bool
+		wantCycle string
+	}{
+		{
+			name: "valid",
+			services: map[string]Service{
+				"api": {Image: "api", DependsOn: DependsOn{"db": {Condition: DependsOnServiceHealthy}}},
+				"db":  {Image: "db"},
+			},
+		},
+		{
+			name: "unknown-service",
+			services: map[string]Service{
+				"api": {Image: "api", DependsOn: DependsOn{"db": {Condition: DependsOnServiceStarted}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "self",
+			services: map[string]Service{
+				"api": {Image: "api", DependsOn: DependsOn{"api": {Condition: DependsOnServiceStarted}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown-condition",
+			services: map[string]Service{
+				"api": {Image: "api", DependsOn: DependsOn{"db": {Condition: "service_completed"}}},
+				"db":  {Image: "db"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "healthy-cronjob",
+			services: map[string]Service{
+				"api":    {Image: "api", DependsOn: DependsOn{"backup": {Condition: DependsOnServiceHealthy}}},
+				"backup": {Image: "backup", Schedule: "@daily"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "started-cronjob",
+			services: map[string]Service{
+				"api":    {Image: "api", DependsOn: DependsOn{"backup": {Condition: DependsOnServiceStarted}}},
+				"backup": {Image: "backup", Schedule: "@daily"},
+			},
+		},
+		{
+			name: "cycle",
+			services: map[string]Service{
+				"api":   {Image: "api", DependsOn: DependsOn{"db": {Condition: DependsOnServiceStarted}}},
+				"db":    {Image: "db", DependsOn: DependsOn{"queue": {Condition: DependsOnServiceStarted}}},
+				"queue": {Image: "queue", DependsOn: DependsOn{"api": {Condition: DependsOnServiceStarted}}},
+				"web":   {Image: "web", DependsOn: DependsOn{"api": {Condition: DependsOnServiceStarted}}},
+			},
+			wantErr:   true,
+			wantCycle: "'api' -> 'db' -> 'queue' -> 'api'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{Name: "name", Services: tt.services}
+			err := s.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantCycle != "" && !strings.Contains(err.Error(), tt.wantCycle) {
+				t.Errorf("wrong cycle: %s", err)
+			}
+		})
+	}
+}
+
+func TestStack_Filter(t *testing.T) {
+	s := &Stack{
+		Name: "name",
+		Endpoints: map[string][]Endpoint{
+			"api":   {{Path: "/api", Service: "api", Port: 8080}},
+			"front": {{Path: "/", Service: "web", Port: 80}, {Path: "/api", Service: "api", Port: 8080}},
+		},
+		Services: map[string]Service{
+			"api":    {Image: "api", Ports: []Port{{Port: 8080, ContainerPort: 8080}}},
+			"web":    {Image: "web", Ports: []Port{{Port: 80, ContainerPort: 80}}},
+			"worker": {Image: "worker"},
+		},
+	}
+
+	result, err := s.Filter([]string{"web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Services) != 1 || result.Services["web"].Image != "web" {
+		t.Errorf("wrong filtered services: %+v", result.Services)
+	}
+	if len(result.Endpoints) != 1 || len(result.Endpoints["front"]) != 2 {
+		t.Errorf("wrong filtered endpoints: %+v", result.Endpoints)
+	}
+	if len(s.Services) != 3 || len(s.Endpoints) != 2 {
+		t.Errorf("the original stack was modified: %+v", s)
+	}
+
+	result, err = s.Filter([]string{"worker"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Endpoints) != 0 {
+		t.Errorf("wrong filtered endpoints: %+v", result.Endpoints)
+	}
+
+	if _, err := s.Filter([]string{"db"}); err == nil {
+		t.Errorf("Stack.Filter() didn't fail for an undefined service")
+	}
+}
+
+func TestStack_ServicesUsingImage(t *testing.T) {
+	s := &Stack{
+		Services: map[string]Service{
+			"api":      {Image: "okteto/api:1.0"},
+			"worker":   {Image: "okteto/api:2.0"},
+			"frontend": {Image: "okteto/api"},
+			"db":       {Image: "postgres@sha256:7c5f1b8bdc3a9d5a4c4d4fd1d0d5f7ec"},
+			"cache":    {Image: "localhost:5000/redis:6"},
+			"vote":     {Image: "okteto.dev/voting-app-vote:okteto", Build: &BuildInfo{Context: "vote"}},
+			"result":   {Build: &BuildInfo{Context: "result"}},
+		},
+	}
+	tests := []struct {
+		name     string
+		image    string
+		expected []string
+	}{
+		{
+			name:     "repository",
+			image:    "okteto/api",
+			expected: []string{"api", "frontend", "worker"},
+		},
+		{
+			name:     "exact-tag",
+			image:    "okteto/api:1.0",
+			expected: []string{"api"},
+		},
+		{
+			name:     "repository-with-digest",
+			image:    "postgres",
+			expected: []string{"db"},
+		},
+		{
+			name:     "exact-digest",
+			image:    "postgres@sha256:7c5f1b8bdc3a9d5a4c4d4fd1d0d5f7ec",
+			expected: []string{"db"},
+		},
+		{
+			name:     "registry-with-port",
+			image:    "localhost:5000/redis",
+			expected: []string{"cache"},
+		},
+		{
+			name:     "built-image",
+			image:    "okteto.dev/voting-app-vote",
+			expected: []string{"vote"},
+		},
+		{
+			name:     "no-match",
+			image:    "okteto/api:3.0",
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := s.ServicesUsingImage(tt.image); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Stack.ServicesUsingImage() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStack_validateEndpointPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		wantErr  bool
+	}{
+		{name: "prefix", endpoint: Endpoint{Path: "/api", Service: "api", Port: 8080}},
+		{name: "exact", endpoint: Endpoint{Path: "/healthz", PathType: ExactPathType, Service: "api", Port: 8080}},
+		{name: "implementation-specific", endpoint: Endpoint{Path: "/api/*", PathType: ImplementationSpecificPathType, Service: "api", Port: 8080}},
+		{name: "relative-path", endpoint: Endpoint{Path: "api", Service: "api", Port: 8080}, wantErr: true},
+		{name: "unknown-path-type", endpoint: Endpoint{Path: "/api", PathType: "prefix", Service: "api", Port: 8080}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Stack{
+				Name:      "name",
+				Endpoints: map[string][]Endpoint{"endpoint": {tt.endpoint}},
+				Services: map[string]Service{
+					"api": {Image: "api", Ports: []Port{{Port: 8080, ContainerPort: 8080}}},
+				},
+			}
+			if err := s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStack_validateEndpointPortSuggestion(t *testing.T) {
+	s := &Stack{
+		Name: "name",
+		Endpoints: map[string][]Endpoint{
+			"endpoint1": {{Service: "db", Port: 8080}},
+		},
+		Services: map[string]Service{
+			"api": {Image: "api", Ports: []Port{{Port: 8080, ContainerPort: 8080}}},
+			"db":  {Image: "db"},
+		},
+	}
+	err := s.validate()
+	if err == nil {
+		t.Fatal("Stack.validate() not failed for an endpoint of an unexported port")
+	}
+	if !strings.Contains(err.Error(), "Port '8080' is exposed by 'api', did you mean that?") {
+		t.Errorf("wrong error message: %s", err)
+	}
+}
+
+func Test_ReadStackExposeProtocol(t *testing.T) {
+	manifest := []byte(`services:
+  dns:
+    image: coredns/coredns
+    expose:
+      - "53/udp"
+      - 53
+      - 9153`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.validate(); err != nil {
+		t.Fatal(err)
+	}
+	dns := s.Services["dns"]
+	expected := []Port{
+		{Port: 53, ContainerPort: 53, Protocol: apiv1.ProtocolUDP},
+		{Port: 53, ContainerPort: 53},
+		{Port: 9153, ContainerPort: 9153},
+	}
+	if !reflect.DeepEqual(dns.Expose, expected) {
+		t.Errorf("wrong expose: %+v", dns.Expose)
+	}
+	if dns.IsPublished() {
+		t.Errorf("service only exposing ports is published")
+	}
+}
+
+func Test_ReadStackEnvSources(t *testing.T) {
+	manifest := []byte(`name: name
+services:
+  api:
+    image: okteto/api
+    environment:
+      - MODE=production
+      - name: DB_PASSWORD
+        valueFrom:
+          secretKeyRef:
+            name: db
+            key: password
+      - name: FEATURES
+        valueFrom:
+          configMapKeyRef:
+            name: features
+            key: enabled
+            optional: true
+    env_from:
+      - secretRef:
+          name: api
+      - configMapRef:
+          name: api
+          optional: true`)
+	s, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.validate(); err != nil {
+		t.Fatal(err)
+	}
+	api := s.Services["api"]
+	expectedEnv := Environment{
+		{Name: "MODE", Value: "production"},
+		{Name: "DB_PASSWORD", ValueFrom: &EnvVarSource{SecretKeyRef: &EnvKeyRef{Name: "db", Key: "password"}}},
+		{Name: "FEATURES", ValueFrom: &EnvVarSource{ConfigMapKeyRef: &EnvKeyRef{Name: "features", Key: "enabled", Optional: true}}},
+	}
+	if !reflect.DeepEqual(api.Environment, expectedEnv) {
+		t.Errorf("wrong environment: %+v", api.Environment)
+	}
+	expectedEnvFrom := []EnvFromSource{
+		{SecretRef: &EnvSourceRef{Name: "api"}},
+		{ConfigMapRef: &EnvSourceRef{Name: "api", Optional: true}},
+	}
+	if !reflect.DeepEqual(api.EnvFrom, expectedEnvFrom) {
+		t.Errorf("wrong env_from: %+v", api.EnvFrom)
+	}
+
+	b, err := yaml.Marshal(api.Environment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unmarshalled Environment
+	if err := yaml.UnmarshalStrict(b, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshalled, expectedEnv) {
+		t.Errorf("wrong environment after marshalling: %s", string(b))
+	}
+}
+
+func TestStack_isReservedEnvVar(t *testing.T) {
+	s := &Stack{
+		Services: map[string]Service{
+			"api":    {Image: "api", Ports: []Port{{Port: 8080, ContainerPort: 8080}}},
+			"my-db":  {Image: "db", Expose: []Port{{Port: 5432, ContainerPort: 5432}}},
+			"worker": {Image: "worker"},
+		},
+	}
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "KUBERNETES_SERVICE_HOST", expected: true},
+		{name: "KUBERNETES_PORT", expected: true},
+		{name: "KUBERNETES_PORT_443_TCP_ADDR", expected: true},
+		{name: "API_SERVICE_PORT", expected: true},
+		{name: "API_PORT_8080_TCP", expected: true},
+		{name: "MY_DB_SERVICE_HOST", expected: true},
+		{name: "API_PORTAL", expected: false},
+		{name: "WORKER_SERVICE_HOST", expected: false},
+		{name: "PATH", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := s.isReservedEnvVar(tt.name); result != tt.expected {
+				t.Errorf("Stack.isReservedEnvVar(%s) = %t, expected %t", tt.name, result, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_validateStackName(t *testing.T) {
+	tests := []struct {
+		name      string
+		stackName string
+		wantErr   bool
+	}{
+		{name: "empty", stackName: "", wantErr: true},
+		{name: "starts-with-dash", stackName: "-bad-name", wantErr: true},
+		{name: "ends-with-dash", stackName: "bad-name-", wantErr: true},
+		{name: "symbols", stackName: "1$good-2", wantErr: true},
+		{name: "alphanumeric", stackName: "good-2", wantErr: false},
+		{name: "good", stackName: "good-name", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateStackName(tt.stackName); (err != nil) != tt.wantErr {
+				t.Errorf("Stack.validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_GetStackFromURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		stackName   string
+		wantErr     bool
+	}{
+		{
+			name:        "ok",
+			contentType: "text/plain; charset=utf-8",
+			body:        "name: remote\nservices:\n  app:\n    image: okteto/app",
+			status:      http.StatusOK,
+		},
+		{
+			name:        "name-from-argument",
+			contentType: "application/x-yaml",
+			body:        "services:\n  app:\n    image: okteto/app",
+			status:      http.StatusOK,
+			stackName:   "remote",
+		},
+		{
+			name:        "missing-name",
+			contentType: "application/x-yaml",
+			body:        "services:\n  app:\n    image: okteto/app",
+			status:      http.StatusOK,
+			wantErr:     true,
+		},
+		{
+			name:        "build-context",
+			contentType: "application/x-yaml",
+			body:        "name: remote\nservices:\n  app:\n    build: .",
+			status:      http.StatusOK,
+			wantErr:     true,
+		},
+		{
+			name:        "env-file",
+			contentType: "application/x-yaml",
+			body:        "name: remote\nservices:\n  app:\n    image: okteto/app\n    env_file:\n      - /etc/secrets.env",
+			status:      http.StatusOK,
+			wantErr:     true,
+		},
+		{
+			name:        "not-found",
+			contentType: "text/plain",
+			body:        "not found",
+			status:      http.StatusNotFound,
+			wantErr:     true,
+		},
+		{
+			name:        "bad-content-type",
+			contentType: "text/html",
+			body:        "<html></html>",
+			status:      http.StatusOK,
+			wantErr:     true,
+		},
+		{
+			name:        "too-big",
+			contentType: "text/plain",
+			body:        "name: remote\n" + strings.Repeat("#", maxStackManifestSize),
+			status:      http.StatusOK,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			s, err := GetStack(tt.stackName, ts.URL+"/okteto-stack.yml", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetStack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && s.Name != "remote" {
+				t.Errorf("wrong stack name '%s'", s.Name)
+			}
+		})
+	}
+}
+
+func TestStack_Equal(t *testing.T) {
+	manifest := []byte(`name: voting-app
+services:
+  vote:
+    image: okteto/vote:1
+    environment:
+      - OPTION_A=Cats
+      - OPTION_B=Dogs
+    ports:
+      - 80
+    resources:
+      memory: 1Gi
+`)
+	equivalent := []byte(`# same stack, different formatting
+name: voting-app
+services:
+  vote:
+    resources:
+      limits:
+        memory: 1024Mi
+    ports: [80]
+    environment:
+      - OPTION_B=Dogs
+      - OPTION_A=Cats
+    image: okteto/vote:1
+    replicas: 1
+`)
+	different := []byte(`name: voting-app
+services:
+  vote:
+    image: okteto/vote:2
+    ports:
+      - 8080
+`)
+
+	s1, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := ReadStack(equivalent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3, err := ReadStack(different)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := s2.Services["vote"]
+	svc.SetLastBuiltAnnotation()
+	s2.Services["vote"] = svc
+
+	if !s1.Equal(s2) {
+		t.Errorf("stacks should be equal, diff: %v", s1.Diff(s2))
+	}
+	if s1.Equal(s3) {
+		t.Errorf("stacks should not be equal")
+	}
+	diff := s1.Diff(s3)
+	expected := []string{"services.vote.environment", "services.vote.image", "services.vote.ports", "services.vote.resources"}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("wrong diff: %v", diff)
+	}
+}
+
+func TestStack_DiffLabelsAndAnnotations(t *testing.T) {
+	s1 := &Stack{Name: "voting-app", Labels: map[string]string{"team": "web"}}
+	s2 := &Stack{Name: "voting-app", Labels: map[string]string{"team": "web"}, Annotations: map[string]string{}}
+	if !s1.Equal(s2) {
+		t.Errorf("stacks should be equal, diff: %v", s1.Diff(s2))
+	}
+	s3 := &Stack{Name: "voting-app", Labels: map[string]string{"team": "api"}, Annotations: map[string]string{"owner": "me"}}
+	diff := s1.Diff(s3)
+	expected := []string{"annotations", "labels"}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("wrong diff: %v", diff)
+	}
+}
+
+func TestStack_DiffDeployQuantities(t *testing.T) {
+	newStack := func(cpus, averageValue string) *Stack {
+		return &Stack{
+			Name: "voting-app",
+			Services: map[string]Service{
+				"vote": {
+					Image: "okteto/vote:1",
+					Deploy: &DeployInfo{
+						Resources: &DeployResources{
+							Limits: DeployResourceList{CPUs: Quantity{Value: resource.MustParse(cpus)}},
+						},
+						Autoscaling: &AutoscalingInfo{
+							Min: 1,
+							Max: 3,
+							Metrics: []AutoscalingMetric{
+								{Type: "pods", Name: "requests", AverageValue: Quantity{Value: resource.MustParse(averageValue)}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	s1 := newStack("0.5", "1k")
+	if !s1.Equal(newStack("500m", "1000")) {
+		t.Errorf("stacks should be equal, diff: %v", s1.Diff(newStack("500m", "1000")))
+	}
+	expected := []string{"services.vote.deploy"}
+	if diff := s1.Diff(newStack("1", "1k")); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("wrong cpus diff: %v", diff)
+	}
+	if diff := s1.Diff(newStack("0.5", "2k")); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("wrong metrics diff: %v", diff)
+	}
+}
+
+func TestStack_DiffOktetoOptions(t *testing.T) {
+	s1 := &Stack{Name: "voting-app", Okteto: OktetoOptions{Domain: "example.com", TLSSecret: "wildcard-tls"}}
+	s2 := &Stack{Name: "voting-app", Okteto: OktetoOptions{Domain: "example.org", TLSIssuer: "letsencrypt"}}
+	if !s1.Equal(s1.DeepCopy()) {
+		t.Errorf("stacks should be equal, diff: %v", s1.Diff(s1.DeepCopy()))
+	}
+	diff := s1.Diff(s2)
+	expected := []string{"x-okteto.domain", "x-okteto.tlsIssuer", "x-okteto.tlsSecret"}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("wrong diff: %v", diff)
+	}
+}
+
+func TestStack_DiffVolumes(t *testing.T) {
+	manifest := []byte(`name: voting-app
+services:
+  db:
+    image: postgres:13
+    volumes:
+      - data:/var/lib/postgresql/data
+volumes:
+  data:
+    size: 1Gi
+    class: standard
+`)
+	tests := []struct {
+		name     string
+		manifest []byte
+		expected []string
+	}{
+		{
+			name: "equivalent",
+			manifest: []byte(`name: voting-app
+services:
+  db:
+    image: postgres:13
+    volumes:
+      - data:/var/lib/postgresql/data
+volumes:
+  data:
+    size: 1024Mi
+    class: standard
+    persist: true
+`),
+			expected: []string{},
+		},
+		{
+			name: "size",
+			manifest: []byte(`name: voting-app
+services:
+  db:
+    image: postgres:13
+    volumes:
+      - data:/var/lib/postgresql/data
+volumes:
+  data:
+    size: 2Gi
+    class: standard
+`),
+			expected: []string{"volumes.data"},
+		},
+		{
+			name: "class",
+			manifest: []byte(`name: voting-app
+services:
+  db:
+    image: postgres:13
+    volumes:
+      - data:/var/lib/postgresql/data
+volumes:
+  data:
+    size: 1Gi
+    class: ssd
+`),
+			expected: []string{"volumes.data"},
+		},
+		{
+			name: "persist",
+			manifest: []byte(`name: voting-app
+services:
+  db:
+    image: postgres:13
+    volumes:
+      - data:/var/lib/postgresql/data
+volumes:
+  data:
+    size: 1Gi
+    class: standard
+    persist: false
+`),
+			expected: []string{"volumes.data"},
+		},
+	}
+	s1, err := ReadStack(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s2, err := ReadStack(tt.manifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := s1.Diff(s2); !reflect.DeepEqual(diff, tt.expected) {
+				t.Errorf("wrong diff: %v", diff)
 			}
 		})
 	}